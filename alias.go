@@ -0,0 +1,65 @@
+package graphb
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// AliasMapping maps the response paths of a query through an alias, response keys joined by '.',
+// to the canonical paths of the fields producing them, field names joined by '.',
+// e.g. "me.recent.id" to "user.posts.id" for the query {me:user{recent:posts{id}}}.
+// Canonical paths identify fields whatever the aliases of a query, e.g. for field usage analytics.
+type AliasMapping map[string]string
+
+// AliasMapping returns the AliasMapping of this Query.
+// Inline fragments and fragment spreads are flattened into the enclosing field, as by LeafPaths.
+// Where fields of several inline fragments share a response key, the first field in selection order is mapped.
+func (q *Query) AliasMapping() (AliasMapping, error) {
+	if err := q.checkCycles(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	m := make(AliasMapping)
+	m.add(q.Fields, "", "", false)
+	return m, nil
+}
+
+// add maps the response paths of fs, under the response path prefix and the canonical path canonicalPrefix,
+// if aliased or if a field of the paths is aliased.
+func (m AliasMapping) add(fs []*Field, prefix, canonicalPrefix string, aliased bool) {
+	for _, f := range fs {
+		if f == nil {
+			continue
+		}
+		if f.isInlineFragment() {
+			m.add(f.Fields, prefix, canonicalPrefix, aliased)
+			continue
+		}
+		path := joinPath(prefix, f.responseKey())
+		canonical := joinPath(canonicalPrefix, f.Name)
+		throughAlias := aliased || (f.Alias != "" && f.Alias != f.Name)
+		if _, ok := m[path]; !ok && throughAlias {
+			m[path] = canonical
+		}
+		m.add(f.Fields, path, canonical, throughAlias)
+	}
+}
+
+// Canonical returns the canonical path of the response value at path, a response path as keyed by Flatten,
+// e.g. "user.posts.title" for "me.recent.0.title". List indices are left out.
+// A path which is not mapped, having no alias, is its own canonical path.
+func (m AliasMapping) Canonical(path string) string {
+	keys := strings.Split(path, ".")
+	responsePath := keys[:0]
+	for _, key := range keys {
+		// names do not start with a digit
+		if key != "" && (key[0] < '0' || key[0] > '9') {
+			responsePath = append(responsePath, key)
+		}
+	}
+	joined := strings.Join(responsePath, ".")
+	if canonical, ok := m[joined]; ok {
+		return canonical
+	}
+	return joined
+}
@@ -0,0 +1,49 @@
+package graphb
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery_AliasMapping(t *testing.T) {
+	q := MakeQuery(TypeQuery).SetFields(
+		MakeField("user").SetAlias("me").SetFields(
+			MakeField("posts").SetAlias("recent").SetFields(MakeField("id")),
+			MakeField("... on Admin").SetFields(MakeField("role").SetAlias("level")),
+			MakeField("name"),
+		),
+		MakeField("user").SetAlias("other").SetArguments(ArgumentInt("id", 2)).SetFields(MakeField("name")),
+		MakeField("viewer").SetFields(MakeField("login").SetAlias("handle"), MakeField("id").SetAlias("id")),
+	)
+	m, err := q.AliasMapping()
+	assert.Nil(t, err)
+	assert.Equal(t, AliasMapping{
+		"me":            "user",
+		"me.recent":     "user.posts",
+		"me.recent.id":  "user.posts.id",
+		"me.level":      "user.role",
+		"me.name":       "user.name",
+		"other":         "user",
+		"other.name":    "user.name",
+		"viewer.handle": "viewer.login",
+	}, m)
+
+	data := json.RawMessage(`{"me":{"recent":[{"id":1},{"id":2}],"name":"a"},"viewer":{"handle":"h","id":3}}`)
+	flat, err := Flatten(data)
+	assert.Nil(t, err)
+	canonical := make(map[string]int)
+	for key := range flat {
+		canonical[m.Canonical(key)]++
+	}
+	assert.Equal(t, map[string]int{"user.posts.id": 2, "user.name": 1, "viewer.login": 1, "viewer.id": 1}, canonical)
+	assert.Equal(t, "a.b", m.Canonical("a.b"))
+	assert.Equal(t, "", m.Canonical(""))
+
+	cyclic := MakeField("a")
+	cyclic.Fields = []*Field{cyclic}
+	_, err = MakeQuery(TypeQuery).SetFields(cyclic).AliasMapping()
+	assert.IsType(t, CyclicFieldErr{}, errors.Cause(err))
+}
@@ -0,0 +1,123 @@
+// Package appsync is the AWS AppSync real-time dialect of graphb.
+// It builds the WebSocket handshake URL and the subscription messages AppSync expects for a graphb subscription Query.
+package appsync
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/udacity/graphb"
+)
+
+// emptyPayload is the base64 encoded "{}" AppSync requires as handshake payload.
+const emptyPayload = "e30="
+
+// Auth produces the authorization object AppSync expects for the GraphQL API host,
+// both in the handshake URL and in the extensions of every subscription message.
+type Auth interface {
+	Headers(host string) map[string]string
+}
+
+// APIKey authorizes with an AppSync API key.
+type APIKey string
+
+// Headers implements Auth.
+func (k APIKey) Headers(host string) map[string]string {
+	return map[string]string{"host": host, "x-api-key": string(k)}
+}
+
+// Token authorizes with a Cognito user pool, OpenID Connect or Lambda authorization token.
+type Token string
+
+// Headers implements Auth.
+func (t Token) Headers(host string) map[string]string {
+	return map[string]string{"host": host, "Authorization": string(t)}
+}
+
+// RealtimeURL returns the WebSocket URL of the GraphQL endpoint, e.g.
+// https://x.appsync-api.us-east-1.amazonaws.com/graphql becomes
+// wss://x.appsync-realtime-api.us-east-1.amazonaws.com/graphql?header=...&payload=e30=
+// Custom domains are served under /graphql/realtime.
+func RealtimeURL(endpoint string, auth Auth) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	header, err := json.Marshal(auth.Headers(u.Host))
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	realtime := *u
+	realtime.Scheme = "wss"
+	if strings.Contains(u.Host, "appsync-api") {
+		realtime.Host = strings.Replace(u.Host, "appsync-api", "appsync-realtime-api", 1)
+	} else {
+		realtime.Path = strings.TrimSuffix(u.Path, "/") + "/realtime"
+	}
+	realtime.RawQuery = url.Values{
+		"header":  {base64.StdEncoding.EncodeToString(header)},
+		"payload": {emptyPayload},
+	}.Encode()
+	return realtime.String(), nil
+}
+
+// ConnectionInitMessage is the first message sent once the WebSocket is open.
+func ConnectionInitMessage() []byte {
+	return []byte(`{"type":"connection_init"}`)
+}
+
+// StartMessage registers the subscription q under id.
+// endpoint is the GraphQL endpoint whose host the authorization is issued for.
+func StartMessage(id string, endpoint string, q *graphb.Query, auth Auth) ([]byte, error) {
+	if q.Type.Canonical() != graphb.TypeSubscription {
+		return nil, errors.WithStack(NotSubscriptionErr{string(q.Type)})
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	data, err := q.JSON()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	msg := startMessage{ID: id, Type: "start"}
+	msg.Payload.Data = data
+	msg.Payload.Extensions.Authorization = auth.Headers(u.Host)
+	b, err := json.Marshal(msg)
+	return b, errors.WithStack(err)
+}
+
+// StopMessage unregisters the subscription of id.
+func StopMessage(id string) ([]byte, error) {
+	b, err := json.Marshal(struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+	}{id, "stop"})
+	return b, errors.WithStack(err)
+}
+
+type startMessage struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Payload struct {
+		Data       string `json:"data"`
+		Extensions struct {
+			Authorization map[string]string `json:"authorization"`
+		} `json:"extensions"`
+	} `json:"payload"`
+}
+
+// NotSubscriptionErr is returned when a query of another operation type is started as a subscription.
+type NotSubscriptionErr struct {
+	Type string
+}
+
+func (e NotSubscriptionErr) Error() string {
+	return fmt.Sprintf("'%s' operation can not be started as an AppSync subscription", e.Type)
+}
@@ -0,0 +1,60 @@
+package appsync
+
+import (
+	"encoding/base64"
+	"net/url"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/udacity/graphb"
+)
+
+const endpoint = "https://abc.appsync-api.us-east-1.amazonaws.com/graphql"
+
+func TestRealtimeURL(t *testing.T) {
+	t.Run("AppSync domain", func(t *testing.T) {
+		s, err := RealtimeURL(endpoint, APIKey("da2-key"))
+		assert.Nil(t, err)
+		u, err := url.Parse(s)
+		assert.Nil(t, err)
+		assert.Equal(t, "wss", u.Scheme)
+		assert.Equal(t, "abc.appsync-realtime-api.us-east-1.amazonaws.com", u.Host)
+		assert.Equal(t, "/graphql", u.Path)
+		assert.Equal(t, "e30=", u.Query().Get("payload"))
+
+		header, err := base64.StdEncoding.DecodeString(u.Query().Get("header"))
+		assert.Nil(t, err)
+		assert.Equal(t, `{"host":"abc.appsync-api.us-east-1.amazonaws.com","x-api-key":"da2-key"}`, string(header))
+	})
+
+	t.Run("custom domain", func(t *testing.T) {
+		s, err := RealtimeURL("https://api.example.com/graphql", Token("jwt"))
+		assert.Nil(t, err)
+		u, err := url.Parse(s)
+		assert.Nil(t, err)
+		assert.Equal(t, "api.example.com", u.Host)
+		assert.Equal(t, "/graphql/realtime", u.Path)
+
+		header, err := base64.StdEncoding.DecodeString(u.Query().Get("header"))
+		assert.Nil(t, err)
+		assert.Equal(t, `{"Authorization":"jwt","host":"api.example.com"}`, string(header))
+	})
+}
+
+func TestStartMessage(t *testing.T) {
+	q := graphb.MakeQuery(graphb.TypeSubscription).SetFields(graphb.MakeField("onCreateTodo").SetFields(graphb.MakeField("id")))
+	b, err := StartMessage("1", endpoint, q, APIKey("da2-key"))
+	assert.Nil(t, err)
+	assert.Equal(t, `{"id":"1","type":"start","payload":{"data":"{\"query\":\"subscription{onCreateTodo{id}}\"}","extensions":{"authorization":{"host":"abc.appsync-api.us-east-1.amazonaws.com","x-api-key":"da2-key"}}}}`, string(b))
+
+	_, err = StartMessage("1", endpoint, graphb.MakeQuery(graphb.TypeQuery), APIKey("da2-key"))
+	assert.Equal(t, NotSubscriptionErr{"query"}, errors.Cause(err))
+}
+
+func TestStopMessage(t *testing.T) {
+	b, err := StopMessage("1")
+	assert.Nil(t, err)
+	assert.Equal(t, `{"id":"1","type":"stop"}`, string(b))
+	assert.Equal(t, `{"type":"connection_init"}`, string(ConnectionInitMessage()))
+}
@@ -1,12 +1,17 @@
 package graphb
 
 import (
-	"fmt"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
 	"time"
+
+	"github.com/pkg/errors"
 )
 
 type argumentValue interface {
-	stringChan() <-chan string
+	tokenChan() <-chan Token
 }
 
 type Argument struct {
@@ -14,21 +19,36 @@ type Argument struct {
 	Value argumentValue
 }
 
-func (a *Argument) stringChan() <-chan string {
-	tokenChan := make(chan string)
+func (a *Argument) tokenChan() <-chan Token {
+	tokenChan := make(chan Token)
 	go func() {
-		tokenChan <- a.Name
-		tokenChan <- ":"
-		for str := range a.Value.stringChan() {
-			tokenChan <- str
+		tokenChan <- Token{TokenName, a.Name}
+		tokenChan <- Token{TokenPunctuator, tokenColumn}
+		for tok := range a.Value.tokenChan() {
+			tokenChan <- tok
 		}
 		close(tokenChan)
 	}()
 	return tokenChan
 }
 
+// Valuer is a custom value, e.g. a scalar struct, which converts itself to a value accepted by ArgumentAny.
+// ArgumentAny and the arguments of structs, see ArgumentsOf, accept Valuer(s) and TokenProvider(s).
+type Valuer interface {
+	GraphQLValue() (interface{}, error)
+}
+
 func ArgumentAny(name string, value interface{}) (Argument, error) {
 	switch v := value.(type) {
+	case Valuer:
+		converted, err := v.GraphQLValue()
+		if err != nil {
+			return Argument{}, errors.WithStack(err)
+		}
+		return ArgumentAny(name, converted)
+	case TokenProvider:
+		return ArgumentTokens(name, v)
+
 	case bool:
 		return ArgumentBool(name, v), nil
 	case []bool:
@@ -47,8 +67,48 @@ func ArgumentAny(name string, value interface{}) (Argument, error) {
 	case time.Time:
 		return ArgumentTime(name, v), nil
 
+	case json.RawMessage:
+		return ArgumentJSON(name, v)
+
+	// values decoded by encoding/json into an interface{}
+	case nil:
+		return Argument{name, argTokens{{TokenNull, "null"}}}, nil
+	case float64:
+		return ArgumentFloat(name, v)
+	case []interface{}:
+		list := make(argList, len(v))
+		for i, elem := range v {
+			arg, err := ArgumentAny(name, elem)
+			if err != nil {
+				return Argument{}, errors.WithStack(err)
+			}
+			list[i] = arg.Value
+		}
+		return Argument{name, list}, nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		fields := make([]Argument, len(keys))
+		for i, key := range keys {
+			if !validName.MatchString(key) {
+				return Argument{}, errors.WithStack(InvalidNameErr{argumentName, key})
+			}
+			arg, err := ArgumentAny(key, v[key])
+			if err != nil {
+				return Argument{}, errors.WithStack(err)
+			}
+			fields[i] = arg
+		}
+		return ArgumentCustomType(name, fields...), nil
+
 	default:
-		return Argument{}, ArgumentTypeNotSupportedErr{Value: value}
+		if rv := reflect.ValueOf(value); rv.Kind() == reflect.Map {
+			return argumentOfMap(name, rv, false, ZeroEmit)
+		}
+		return Argument{}, argumentTypeNotSupported(name, value)
 	}
 }
 
@@ -106,6 +166,119 @@ func ArgumentSlice(name string, values ...[]Argument) Argument {
 	return Argument{name, argArgSlice(values)}
 }
 
+// ArgumentJSON returns the argument whose value is the GraphQL literal of a JSON value, e.g. a filter stored as JSON.
+// Object keys become input object field names, which must be valid names. JSON strings can not become enum values.
+func ArgumentJSON(name string, raw json.RawMessage) (Argument, error) {
+	tokens, err := jsonTokens(raw)
+	if e, ok := errors.Cause(err).(InvalidJSONValueErr); ok {
+		e.Argument = name
+		return Argument{}, errors.WithStack(e)
+	}
+	if err != nil {
+		return Argument{}, errors.WithStack(err)
+	}
+	return Argument{name, argTokens(tokens)}, nil
+}
+
+// ArgumentTokens returns the argument whose value is serialized into the tokens of p, e.g. a custom scalar.
+// The tokens are read once and must make a single GraphQL value, which may reference variables.
+func ArgumentTokens(name string, p TokenProvider) (Argument, error) {
+	tokens := p.Tokens()
+	literals := make([]string, len(tokens))
+	for i, tok := range tokens {
+		literals[i] = tok.Literal
+	}
+	lexemes, err := lex(strings.Join(literals, tokenSpace))
+	if err == nil && len(lexemes) == 0 {
+		err = errors.New("no value")
+	}
+	var value argumentValue
+	if err == nil {
+		parser := &parser{lexemes: lexemes}
+		value, err = parser.parseValue(false)
+		if err == nil && !parser.atEnd() {
+			err = parser.unexpected("the end of the value")
+		}
+	}
+	if err != nil {
+		reason := err.Error()
+		if e, ok := errors.Cause(err).(SyntaxErr); ok {
+			reason = e.Message
+		}
+		return Argument{}, errors.WithStack(InvalidTokensErr{name, reason})
+	}
+	return Argument{name, value}, nil
+}
+
+// OmitIfDefault annotates arg with a default value, usually the one declared by the schema for an argument or an input object field.
+// An annotated argument is not emitted when its value equals the default, since the server falls back to the default anyway.
+// defaultValue accepts the same types as ArgumentAny.
+func OmitIfDefault(arg Argument, defaultValue interface{}) (Argument, error) {
+	def, err := ArgumentAny(arg.Name, defaultValue)
+	if err != nil {
+		return Argument{}, errors.WithStack(err)
+	}
+	return Argument{arg.Name, argDefaulted{arg.Value, def.Value}}, nil
+}
+
+// argDefaulted wraps a value annotated by OmitIfDefault.
+type argDefaulted struct {
+	value        argumentValue
+	defaultValue argumentValue
+}
+
+func (v argDefaulted) tokenChan() <-chan Token {
+	return v.value.tokenChan()
+}
+
+// isDefault compares the serialized values so that, for example, an empty slice equals a nil slice.
+func (v argDefaulted) isDefault() bool {
+	return StringFromChan(literals(v.value.tokenChan())) == StringFromChan(literals(v.defaultValue.tokenChan()))
+}
+
+// emittedArguments filters out the arguments annotated by OmitIfDefault whose values equal their defaults.
+func emittedArguments(args []Argument) []Argument {
+	emitted := make([]Argument, 0, len(args))
+	for _, arg := range args {
+		if v, ok := arg.Value.(argDefaulted); ok && v.isDefault() {
+			continue
+		}
+		emitted = append(emitted, arg)
+	}
+	return emitted
+}
+
+// eachValue calls fn with value, then with the values nested in value if it is an input object or a list, depth first.
+// It stops at the first error returned by fn.
+func eachValue(value argumentValue, fn func(argumentValue) error) error {
+	if err := fn(value); err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case argDefaulted:
+		return eachValue(v.value, fn)
+	case argumentCustom:
+		for _, arg := range v {
+			if err := eachValue(arg.Value, fn); err != nil {
+				return err
+			}
+		}
+	case argArgSlice:
+		for _, args := range v {
+			if err := eachValue(argumentCustom(args), fn); err != nil {
+				return err
+			}
+		}
+	case argList:
+		for _, value := range v {
+			if err := eachValue(value, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 /////////////////////////////
 // Primitive Wrapper Types //
 /////////////////////////////
@@ -113,10 +286,10 @@ func ArgumentSlice(name string, values ...[]Argument) Argument {
 // argBool represents a boolean value.
 type argBool bool
 
-func (v argBool) stringChan() <-chan string {
-	tokenChan := make(chan string)
+func (v argBool) tokenChan() <-chan Token {
+	tokenChan := make(chan Token)
 	go func() {
-		tokenChan <- fmt.Sprintf("%t", v)
+		tokenChan <- Token{TokenBoolean, boolLiteral(bool(v))}
 		close(tokenChan)
 	}()
 	return tokenChan
@@ -125,10 +298,10 @@ func (v argBool) stringChan() <-chan string {
 // argInt represents an integer value.
 type argInt int
 
-func (v argInt) stringChan() <-chan string {
-	tokenChan := make(chan string)
+func (v argInt) tokenChan() <-chan Token {
+	tokenChan := make(chan Token)
 	go func() {
-		tokenChan <- fmt.Sprintf("%d", v)
+		tokenChan <- Token{TokenInt, intLiteral(int(v))}
 		close(tokenChan)
 	}()
 	return tokenChan
@@ -137,10 +310,10 @@ func (v argInt) stringChan() <-chan string {
 // argString represents a string value.
 type argString string
 
-func (v argString) stringChan() <-chan string {
-	tokenChan := make(chan string)
+func (v argString) tokenChan() <-chan Token {
+	tokenChan := make(chan Token)
 	go func() {
-		tokenChan <- fmt.Sprintf(`"%s"`, v)
+		tokenChan <- Token{TokenString, `"` + string(v) + `"`}
 		close(tokenChan)
 	}()
 	return tokenChan
@@ -149,10 +322,10 @@ func (v argString) stringChan() <-chan string {
 // argQuotedString represents a quoted string value.
 type argQuotedString string
 
-func (v argQuotedString) stringChan() <-chan string {
-	tokenChan := make(chan string)
+func (v argQuotedString) tokenChan() <-chan Token {
+	tokenChan := make(chan Token)
 	go func() {
-		tokenChan <- fmt.Sprintf(`"\\"%s\\""`, v)
+		tokenChan <- Token{TokenString, `"\\"` + string(v) + `\\""`}
 		close(tokenChan)
 	}()
 	return tokenChan
@@ -161,10 +334,10 @@ func (v argQuotedString) stringChan() <-chan string {
 // argBlockString represents a block string value.
 type argBlockString string
 
-func (v argBlockString) stringChan() <-chan string {
-	tokenChan := make(chan string)
+func (v argBlockString) tokenChan() <-chan Token {
+	tokenChan := make(chan Token)
 	go func() {
-		tokenChan <- fmt.Sprintf(`"""%s"""`, v)
+		tokenChan <- Token{TokenString, `"""` + string(v) + `"""`}
 		close(tokenChan)
 	}()
 	return tokenChan
@@ -173,10 +346,10 @@ func (v argBlockString) stringChan() <-chan string {
 // argEnum represents a enum value.
 type argEnum string
 
-func (v argEnum) stringChan() <-chan string {
-	tokenChan := make(chan string)
+func (v argEnum) tokenChan() <-chan Token {
+	tokenChan := make(chan Token)
 	go func() {
-		tokenChan <- fmt.Sprintf("%s", v)
+		tokenChan <- Token{TokenEnum, string(v)}
 		close(tokenChan)
 	}()
 	return tokenChan
@@ -185,10 +358,10 @@ func (v argEnum) stringChan() <-chan string {
 // argTime represents a time value
 type argTime time.Time
 
-func (v argTime) stringChan() <-chan string {
-	tokenChan := make(chan string)
+func (v argTime) tokenChan() <-chan Token {
+	tokenChan := make(chan Token)
 	go func() {
-		tokenChan <- fmt.Sprintf(`"%s"`, time.Time(v).Format(time.RFC3339))
+		tokenChan <- Token{TokenString, `"` + time.Time(v).Format(time.RFC3339) + `"`}
 		close(tokenChan)
 	}()
 	return tokenChan
@@ -201,17 +374,17 @@ func (v argTime) stringChan() <-chan string {
 // argBoolSlice implements valueSlice
 type argBoolSlice []bool
 
-func (s argBoolSlice) stringChan() <-chan string {
-	tokenChan := make(chan string)
+func (s argBoolSlice) tokenChan() <-chan Token {
+	tokenChan := make(chan Token)
 	go func() {
-		tokenChan <- "["
+		tokenChan <- Token{TokenPunctuator, tokenLSB}
 		for i, v := range s {
 			if i != 0 {
-				tokenChan <- ","
+				tokenChan <- Token{TokenPunctuator, tokenComma}
 			}
-			tokenChan <- fmt.Sprintf("%t", v)
+			tokenChan <- Token{TokenBoolean, boolLiteral(v)}
 		}
-		tokenChan <- "]"
+		tokenChan <- Token{TokenPunctuator, tokenRSB}
 		close(tokenChan)
 	}()
 	return tokenChan
@@ -220,17 +393,17 @@ func (s argBoolSlice) stringChan() <-chan string {
 // argIntSlice implements valueSlice
 type argIntSlice []int
 
-func (s argIntSlice) stringChan() <-chan string {
-	tokenChan := make(chan string)
+func (s argIntSlice) tokenChan() <-chan Token {
+	tokenChan := make(chan Token)
 	go func() {
-		tokenChan <- "["
+		tokenChan <- Token{TokenPunctuator, tokenLSB}
 		for i, v := range s {
 			if i != 0 {
-				tokenChan <- ","
+				tokenChan <- Token{TokenPunctuator, tokenComma}
 			}
-			tokenChan <- fmt.Sprintf("%d", v)
+			tokenChan <- Token{TokenInt, intLiteral(v)}
 		}
-		tokenChan <- "]"
+		tokenChan <- Token{TokenPunctuator, tokenRSB}
 		close(tokenChan)
 	}()
 	return tokenChan
@@ -239,17 +412,17 @@ func (s argIntSlice) stringChan() <-chan string {
 // argStringSlice implements valueSlice
 type argStringSlice []string
 
-func (s argStringSlice) stringChan() <-chan string {
-	tokenChan := make(chan string)
+func (s argStringSlice) tokenChan() <-chan Token {
+	tokenChan := make(chan Token)
 	go func() {
-		tokenChan <- "["
+		tokenChan <- Token{TokenPunctuator, tokenLSB}
 		for i, v := range s {
 			if i != 0 {
-				tokenChan <- ","
+				tokenChan <- Token{TokenPunctuator, tokenComma}
 			}
-			tokenChan <- fmt.Sprintf(`"%s"`, v)
+			tokenChan <- Token{TokenString, `"` + v + `"`}
 		}
-		tokenChan <- "]"
+		tokenChan <- Token{TokenPunctuator, tokenRSB}
 		close(tokenChan)
 	}()
 	return tokenChan
@@ -258,17 +431,17 @@ func (s argStringSlice) stringChan() <-chan string {
 // argEnumSlice implements valueSlice
 type argEnumSlice []string
 
-func (s argEnumSlice) stringChan() <-chan string {
-	tokenChan := make(chan string)
+func (s argEnumSlice) tokenChan() <-chan Token {
+	tokenChan := make(chan Token)
 	go func() {
-		tokenChan <- "["
+		tokenChan <- Token{TokenPunctuator, tokenLSB}
 		for i, v := range s {
 			if i != 0 {
-				tokenChan <- ","
+				tokenChan <- Token{TokenPunctuator, tokenComma}
 			}
-			tokenChan <- fmt.Sprintf("%s", v)
+			tokenChan <- Token{TokenEnum, v}
 		}
-		tokenChan <- "]"
+		tokenChan <- Token{TokenPunctuator, tokenRSB}
 		close(tokenChan)
 	}()
 	return tokenChan
@@ -276,19 +449,40 @@ func (s argEnumSlice) stringChan() <-chan string {
 
 type argumentCustom []Argument
 
-func (s argumentCustom) stringChan() <-chan string {
-	tokenChan := make(chan string)
+func (s argumentCustom) tokenChan() <-chan Token {
+	tokenChan := make(chan Token)
+	go func() {
+		tokenChan <- Token{TokenPunctuator, tokenLB}
+		for i, v := range emittedArguments(s) {
+			if i != 0 {
+				tokenChan <- Token{TokenPunctuator, tokenComma}
+			}
+			for tok := range v.tokenChan() {
+				tokenChan <- tok
+			}
+		}
+		tokenChan <- Token{TokenPunctuator, tokenRB}
+		close(tokenChan)
+	}()
+	return tokenChan
+}
+
+// argList represents a list of values of any types, e.g. parsed from a document.
+type argList []argumentValue
+
+func (s argList) tokenChan() <-chan Token {
+	tokenChan := make(chan Token)
 	go func() {
-		tokenChan <- "{"
+		tokenChan <- Token{TokenPunctuator, tokenLSB}
 		for i, v := range s {
 			if i != 0 {
-				tokenChan <- ","
+				tokenChan <- Token{TokenPunctuator, tokenComma}
 			}
-			for str := range v.stringChan() {
-				tokenChan <- str
+			for tok := range v.tokenChan() {
+				tokenChan <- tok
 			}
 		}
-		tokenChan <- "}"
+		tokenChan <- Token{TokenPunctuator, tokenRSB}
 		close(tokenChan)
 	}()
 	return tokenChan
@@ -296,19 +490,19 @@ func (s argumentCustom) stringChan() <-chan string {
 
 type argArgSlice [][]Argument
 
-func (s argArgSlice) stringChan() <-chan string {
-	tokenChan := make(chan string)
+func (s argArgSlice) tokenChan() <-chan Token {
+	tokenChan := make(chan Token)
 	go func() {
-		tokenChan <- "["
+		tokenChan <- Token{TokenPunctuator, tokenLSB}
 		for i, v := range s {
 			if i != 0 {
-				tokenChan <- ","
+				tokenChan <- Token{TokenPunctuator, tokenComma}
 			}
-			for str := range argumentCustom(v).stringChan() {
-				tokenChan <- str
+			for tok := range argumentCustom(v).tokenChan() {
+				tokenChan <- tok
 			}
 		}
-		tokenChan <- "]"
+		tokenChan <- Token{TokenPunctuator, tokenRSB}
 		close(tokenChan)
 	}()
 	return tokenChan
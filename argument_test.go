@@ -2,9 +2,11 @@ package graphb
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -33,11 +35,40 @@ func TestArgumentAny(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, Argument{"arg", argIntSlice([]int{1, 2})}, arg)
 
-	// Type Not Supported
 	arg, err = ArgumentAny("arg", 1.1)
-	assert.IsType(t, ArgumentTypeNotSupportedErr{}, err)
-	assert.Equal(t, "Argument 1.1 of Type float64 is not supported", err.Error())
+	assert.Nil(t, err)
+	assert.Equal(t, Argument{"arg", argTokens{{TokenFloat, "1.1"}}}, arg)
+
+	arg, err = ArgumentAny("arg", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, Argument{"arg", argTokens{{TokenNull, "null"}}}, arg)
+
+	arg, err = ArgumentAny("arg", []interface{}{"a", 1, nil})
+	assert.Nil(t, err)
+	assert.Equal(t, Argument{"arg", argList{argString("a"), argInt(1), argTokens{{TokenNull, "null"}}}}, arg)
+
+	arg, err = ArgumentAny("arg", map[string]interface{}{"b": true, "a": []interface{}{1.5}})
+	assert.Nil(t, err)
+	assert.Equal(t, ArgumentCustomType("arg", Argument{"a", argList{argTokens{{TokenFloat, "1.5"}}}}, ArgumentBool("b", true)), arg)
+
+	_, err = ArgumentAny("arg", map[string]interface{}{"a-b": 1})
+	assert.Equal(t, InvalidNameErr{argumentName, "a-b"}, errors.Cause(err))
+
+	_, err = ArgumentAny("arg", []interface{}{int64(1)})
+	assert.Equal(t, argumentTypeNotSupported("arg", int64(1)), errors.Cause(err))
+
+	// Type Not Supported
+	arg, err = ArgumentAny("arg", int64(1))
+	assert.Equal(t, ArgumentTypeNotSupportedErr{"arg", int64(1), reflect.TypeOf(int64(1)), reflect.Int64, "convert it to int"}, err)
+	assert.Equal(t, "Value 1 of argument 'arg' of Type int64 (kind int64) is not supported, convert it to int", err.Error())
 	assert.Equal(t, Argument{}, arg)
+
+	_, err = ArgumentAny("arg", struct{}{})
+	assert.Equal(t, reflect.Struct, err.(ArgumentTypeNotSupportedErr).Kind)
+	assert.Contains(t, err.Error(), "ArgumentsOf")
+
+	_, err = ArgumentAny("arg", make(chan int))
+	assert.Contains(t, err.Error(), "(kind chan) is not supported, implement Valuer or TokenProvider")
 }
 
 func TestArgumentBool(t *testing.T) {
@@ -91,8 +122,8 @@ func TestArgumentEnum(t *testing.T) {
 func Test_argBool(t *testing.T) {
 	b := argBool(true)
 	i := 0
-	for str := range b.stringChan() {
-		assert.Equal(t, "true", str)
+	for tok := range b.tokenChan() {
+		assert.Equal(t, Token{TokenBoolean, "true"}, tok)
 		i++
 	}
 	assert.Equal(t, 1, i)
@@ -101,8 +132,8 @@ func Test_argBool(t *testing.T) {
 func Test_argEnum(t *testing.T) {
 	b := argEnum("ENUM_VALUE")
 	i := 0
-	for str := range b.stringChan() {
-		assert.Equal(t, "ENUM_VALUE", str)
+	for tok := range b.tokenChan() {
+		assert.Equal(t, Token{TokenEnum, "ENUM_VALUE"}, tok)
 		i++
 	}
 	assert.Equal(t, 1, i)
@@ -111,8 +142,8 @@ func Test_argEnum(t *testing.T) {
 func Test_argBlockString(t *testing.T) {
 	b := argBlockString(`blockstring`)
 	i := 0
-	for str := range b.stringChan() {
-		assert.Equal(t, "\"\"\"blockstring\"\"\"", str)
+	for tok := range b.tokenChan() {
+		assert.Equal(t, Token{TokenString, "\"\"\"blockstring\"\"\""}, tok)
 		i++
 	}
 	assert.Equal(t, 1, i)
@@ -120,7 +151,7 @@ func Test_argBlockString(t *testing.T) {
 
 func Test_argEnumSlice(t *testing.T) {
 	es := argEnumSlice([]string{"ENUM_VALUE", "ENUM_SLICE"})
-	c := es.stringChan()
+	c := literals(es.tokenChan())
 	i := 0
 	tokens := []string{"[", "ENUM_VALUE", ",", "ENUM_SLICE", "]"}
 
@@ -133,7 +164,7 @@ func Test_argEnumSlice(t *testing.T) {
 
 func Test_argBoolSlice(t *testing.T) {
 	bs := argBoolSlice([]bool{true, false})
-	c := bs.stringChan()
+	c := literals(bs.tokenChan())
 	i := 0
 	tokens := []string{"[", "true", ",", "false", "]"}
 	for str, ok := <-c; ok; str, ok = <-c {
@@ -147,7 +178,7 @@ func Test_argIntSlice(t *testing.T) {
 	is := argIntSlice([]int{1, -1, 0})
 	tokens := []string{"[", "1", ",", "-1", ",", "0", "]"}
 	i := 0
-	for str := range is.stringChan() {
+	for str := range literals(is.tokenChan()) {
 		assert.Equal(t, tokens[i], str)
 		i++
 	}
@@ -167,10 +198,114 @@ func Test_argMapSlice(t *testing.T) {
 	})
 	tokens := []string{"[", "{", "a", ":", `"b"`, ",", "b", ":", "true", "}", ",", "{", "a", ":", "1", ",", "b", ":", `"true"`, "}", "]"}
 	i := 0
-	for str := range is.stringChan() {
+	for str := range literals(is.tokenChan()) {
 		fmt.Println(str)
 		assert.Equal(t, tokens[i], str)
 		i++
 	}
 	assert.Equal(t, len(tokens), i)
 }
+
+func TestOmitIfDefault(t *testing.T) {
+	a, err := OmitIfDefault(ArgumentInt("first", 10), 10)
+	assert.Nil(t, err)
+	assert.Equal(t, Argument{"first", argDefaulted{argInt(10), argInt(10)}}, a)
+
+	_, err = OmitIfDefault(ArgumentInt("first", 10), int64(10))
+	assert.IsType(t, ArgumentTypeNotSupportedErr{}, errors.Cause(err))
+
+	first, _ := OmitIfDefault(ArgumentInt("first", 10), 10)
+	tags, _ := OmitIfDefault(ArgumentStringSlice("tags"), []string{})
+	status, _ := OmitIfDefault(ArgumentString("status", "DRAFT"), "PUBLISHED")
+	f := MakeField("posts").SetArguments(
+		first,
+		ArgumentCustomType("filter", tags, status),
+	)
+	assert.Equal(t, `posts(filter:{status:"DRAFT"})`, StringFromChan(f.stringChan()))
+
+	f = MakeField("posts").SetArguments(first, ArgumentCustomType("filter", tags))
+	assert.Equal(t, `posts(filter:{})`, StringFromChan(f.stringChan()))
+
+	f = MakeField("posts").SetArguments(first)
+	assert.Equal(t, `posts`, StringFromChan(f.stringChan()))
+}
+
+// money is a custom scalar serialized as a string.
+type money struct {
+	cents    int
+	currency string
+}
+
+func (m money) GraphQLValue() (interface{}, error) {
+	if m.currency == "" {
+		return nil, errors.New("no currency")
+	}
+	return fmt.Sprintf("%d.%02d %s", m.cents/100, m.cents%100, m.currency), nil
+}
+
+// level is an enum whose values convert themselves.
+type level int
+
+func (l *level) GraphQLValue() (interface{}, error) {
+	return [...]string{"LOW", "HIGH"}[*l], nil
+}
+
+func TestArgumentAny_custom(t *testing.T) {
+	arg, err := ArgumentAny("price", money{1050, "USD"})
+	assert.Nil(t, err)
+	assert.Equal(t, ArgumentString("price", "10.50 USD"), arg)
+
+	_, err = ArgumentAny("price", money{})
+	assert.EqualError(t, errors.Cause(err), "no currency")
+
+	arg, err = ArgumentAny("at", point{1, 2})
+	assert.Nil(t, err)
+	assert.Equal(t, `at:{x:1,y:$y}`, StringFromChan(literals(arg.tokenChan())))
+
+	high := level(1)
+	args, err := ArgumentsOf(struct {
+		Price  money
+		Level  *level   `graphql:"level,enum"`
+		Levels []*level `graphql:"levels,enum"`
+		Unset  *level
+	}{money{5, "EUR"}, &high, []*level{&high}, nil})
+	assert.Nil(t, err)
+	assert.Equal(t, `f(price:"0.05 EUR",level:HIGH,levels:[HIGH],unset:null)`, StringFromChan(MakeField("f").SetArguments(args...).stringChan()))
+}
+
+// region is an enum keying maps by its name.
+type region int
+
+func (r region) String() string {
+	return [...]string{"EU", "US"}[r]
+}
+
+// tier is an enum whose values are its names.
+type tier string
+
+func TestArgumentAny_map(t *testing.T) {
+	arg, err := ArgumentAny("quotas", map[region]int{1: 20, 0: 10})
+	assert.Nil(t, err)
+	assert.Equal(t, `quotas:{EU:10,US:20}`, StringFromChan(literals(arg.tokenChan())))
+
+	arg, err = ArgumentAny("limits", map[tier][]string{"PRO": {"a"}, "FREE": nil})
+	assert.Nil(t, err)
+	assert.Equal(t, `limits:{FREE:[],PRO:["a"]}`, StringFromChan(literals(arg.tokenChan())))
+
+	arg, err = ArgumentAny("labels", map[string]string{})
+	assert.Nil(t, err)
+	assert.Equal(t, `labels:{}`, StringFromChan(literals(arg.tokenChan())))
+
+	_, err = ArgumentAny("scores", map[int]int{1: 2})
+	assert.Equal(t, MapKeyNotSupportedErr{"scores", 1}, errors.Cause(err))
+	assert.Equal(t, "key 1 of type int in the map of argument 'scores' can not name an input object field, use string keys or keys with a String method", errors.Cause(err).Error())
+
+	_, err = ArgumentAny("labels", map[string]string{"a b": "c"})
+	assert.Equal(t, InvalidNameErr{argumentName, "a b"}, errors.Cause(err))
+
+	args, err := ArgumentsOf(struct {
+		Quotas map[region]tier `graphql:"quotas,enum"`
+	}{map[region]tier{0: "PRO"}})
+	assert.Nil(t, err)
+	assert.Equal(t, `f(quotas:{EU:PRO})`, StringFromChan(MakeField("f").SetArguments(args...).stringChan()))
+}
@@ -0,0 +1,194 @@
+// Package benchmarks measures the serializer of graphb on wide, deep and argument heavy queries,
+// and asserts allocation budgets so that performance regressions are caught by go test.
+package benchmarks
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/udacity/graphb"
+)
+
+// wideQuery selects n leaf fields under a single field.
+func wideQuery(n int) *graphb.Query {
+	fields := make([]*graphb.Field, n)
+	for i := range fields {
+		fields[i] = graphb.MakeField(fmt.Sprintf("field%d", i))
+	}
+	return graphb.MakeQuery(graphb.TypeQuery).SetFields(graphb.MakeField("root").SetFields(fields...))
+}
+
+// deepQuery nests n fields.
+func deepQuery(n int) *graphb.Query {
+	f := graphb.MakeField("leaf")
+	for i := 0; i < n; i++ {
+		f = graphb.MakeField(fmt.Sprintf("level%d", i)).SetFields(f)
+	}
+	return graphb.MakeQuery(graphb.TypeQuery).SetFields(f)
+}
+
+// argumentQuery has n fields, each with scalar, list and input object arguments.
+func argumentQuery(n int) *graphb.Query {
+	fields := make([]*graphb.Field, n)
+	for i := range fields {
+		fields[i] = graphb.MakeField("search").
+			SetAlias(fmt.Sprintf("s%d", i)).
+			SetArguments(
+				graphb.ArgumentString("text", "graphql"),
+				graphb.ArgumentInt("first", i),
+				graphb.ArgumentIntSlice("ids", 1, 2, 3),
+				graphb.ArgumentCustomType("filter",
+					graphb.ArgumentBool("archived", false),
+					graphb.ArgumentEnum("order", "DESC"),
+					graphb.ArgumentStringSlice("tags", "a", "b"),
+				),
+			).
+			SetFields(graphb.MakeField("id"))
+	}
+	return graphb.MakeQuery(graphb.TypeQuery).SetFields(fields...)
+}
+
+func serialize(b testing.TB, q *graphb.Query) string {
+	s, err := q.JSON()
+	if err != nil {
+		b.Fatal(err)
+	}
+	return s
+}
+
+func BenchmarkWideQuery(b *testing.B) {
+	for _, n := range []int{10, 100, 1000} {
+		q := wideQuery(n)
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				serialize(b, q)
+			}
+		})
+	}
+}
+
+func BenchmarkDeepQuery(b *testing.B) {
+	for _, n := range []int{10, 50} {
+		q := deepQuery(n)
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				serialize(b, q)
+			}
+		})
+	}
+}
+
+func BenchmarkArgumentQuery(b *testing.B) {
+	for _, n := range []int{10, 100} {
+		q := argumentQuery(n)
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				serialize(b, q)
+			}
+		})
+	}
+}
+
+// The budgets are allocations per serialization, with some headroom over the measured numbers.
+// Lower them when the serializer improves, never raise them without a reason.
+func TestAllocationBudgets(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocations are not measured with the race detector")
+	}
+	for _, tc := range []struct {
+		name   string
+		query  *graphb.Query
+		budget float64
+	}{
+		{"wide 100", wideQuery(100), 260},
+		{"deep 50", deepQuery(50), 140},
+		{"arguments 10", argumentQuery(10), 440},
+	} {
+		allocs := testing.AllocsPerRun(20, func() { serialize(t, tc.query) })
+		t.Logf("%s: %v allocs", tc.name, allocs)
+		if allocs > tc.budget {
+			t.Errorf("%s: %v allocations exceed the budget of %v", tc.name, allocs, tc.budget)
+		}
+	}
+}
+
+func BenchmarkWideQueryParallel(b *testing.B) {
+	q := graphb.MakeQuery(graphb.TypeQuery)
+	for i := 0; i < 100; i++ {
+		q.AddFields(argumentQuery(10).Fields...)
+	}
+	b.Run("sequential", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			strCh, err := q.StringChan()
+			if err != nil {
+				b.Fatal(err)
+			}
+			graphb.StringFromChan(strCh)
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := q.StringParallel(0); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkCompiledField(b *testing.B) {
+	userFields := func() *graphb.Field {
+		return graphb.MakeField("user").SetFields(argumentQuery(10).Fields...)
+	}
+	compiled, err := userFields().Compile()
+	if err != nil {
+		b.Fatal(err)
+	}
+	for name, f := range map[string]*graphb.Field{"plain": userFields(), "compiled": compiled} {
+		q := graphb.MakeQuery(graphb.TypeQuery).SetFields(f)
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				serialize(b, q)
+			}
+		})
+	}
+}
+
+func BenchmarkShapeCache(b *testing.B) {
+	q := argumentQuery(10)
+	c := graphb.NewShapeCache(16)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.JSON(q); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPaginationString(b *testing.B) {
+	q := argumentQuery(100)
+	page := q.Fields[0]
+	b.Run("StringChan", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			page.ReplaceArgument(graphb.ArgumentInt("first", i))
+			strCh, err := q.StringChan()
+			if err != nil {
+				b.Fatal(err)
+			}
+			graphb.StringFromChan(strCh)
+		}
+	})
+	b.Run("String", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			page.ReplaceArgument(graphb.ArgumentInt("first", i))
+			if _, err := q.String(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
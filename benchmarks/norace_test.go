@@ -0,0 +1,6 @@
+//go:build !race
+
+package benchmarks
+
+// raceEnabled reports whether the tests run with the race detector, whose instrumentation allocates.
+const raceEnabled = false
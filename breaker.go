@@ -0,0 +1,198 @@
+package graphb
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// BreakerState is the state of a circuit breaker.
+type BreakerState int
+
+const (
+	BreakerClosed   BreakerState = iota // Calls go through.
+	BreakerOpen                         // Calls fail fast with CircuitOpenErr.
+	BreakerHalfOpen                     // A single trial call goes through to decide whether to close again.
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// CircuitBreaker decides whether calls to a GraphQL endpoint go through, from the outcomes of the previous ones.
+type CircuitBreaker interface {
+	// Allow returns nil if a call may go through, and then Record has to be called with its outcome.
+	Allow() error
+	// Record records the latency and the error of a call.
+	Record(latency time.Duration, err error)
+	// Release releases a call without recording its outcome, e.g. because it was canceled by its context.
+	Release()
+}
+
+// WithCircuitBreaker returns an ExecuteFunc which executes queries through breaker,
+// so that an outage of the endpoint fails calls fast instead of cascading.
+// Calls canceled by their context are released rather than recorded, as they say nothing about the endpoint.
+func WithCircuitBreaker(execute ExecuteFunc, breaker CircuitBreaker) ExecuteFunc {
+	return func(ctx context.Context, q *Query) (json.RawMessage, error) {
+		if err := breaker.Allow(); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		start := time.Now()
+		data, err := execute(ctx, q)
+		if ctx.Err() != nil && errors.Cause(err) == ctx.Err() {
+			breaker.Release()
+		} else {
+			breaker.Record(time.Since(start), err)
+		}
+		return data, err
+	}
+}
+
+// RateBreaker is a CircuitBreaker which opens when too many of the recent calls failed or were too slow.
+// Its zero value never opens, set at least MaxErrorRate.
+type RateBreaker struct {
+	Window       int           // The number of recent calls considered, 20 if zero.
+	MinCalls     int           // The number of calls in the window required before opening.
+	MaxErrorRate float64       // The rate of failed calls in the window, from 0 to 1, which opens the breaker.
+	MaxLatency   time.Duration // Calls slower than MaxLatency count as failed, unless zero.
+	OpenFor      time.Duration // How long the breaker stays open before a trial call.
+
+	// OnStateChange, if not nil, is called on every state change, e.g. to export the state as a metric.
+	// It is called without holding the lock of the breaker, so it may call State.
+	OnStateChange func(from, to BreakerState)
+
+	mu       sync.Mutex
+	state    BreakerState
+	outcomes []bool // Whether each call of the window failed, as a ring buffer.
+	next     int
+	failures int
+	openedAt time.Time
+	trial    bool          // Whether the trial call of the half-open state is in flight.
+	changes  []stateChange // The state changes to report once the lock is released.
+	now      func() time.Time
+}
+
+type stateChange struct {
+	from, to BreakerState
+}
+
+// State returns the current state of the breaker.
+func (b *RateBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Allow implements CircuitBreaker.
+func (b *RateBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.unlock()
+	switch b.state {
+	case BreakerOpen:
+		if b.clock().Sub(b.openedAt) < b.OpenFor {
+			return errors.WithStack(CircuitOpenErr{})
+		}
+		b.setState(BreakerHalfOpen)
+		b.trial = true
+		return nil
+	case BreakerHalfOpen:
+		if b.trial {
+			return errors.WithStack(CircuitOpenErr{})
+		}
+		b.trial = true
+	}
+	return nil
+}
+
+// Release implements CircuitBreaker. Releasing the trial call of the half-open state lets another call try.
+func (b *RateBreaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == BreakerHalfOpen {
+		b.trial = false
+	}
+}
+
+// Record implements CircuitBreaker.
+func (b *RateBreaker) Record(latency time.Duration, err error) {
+	b.mu.Lock()
+	defer b.unlock()
+	failed := err != nil || (b.MaxLatency > 0 && latency > b.MaxLatency)
+
+	if b.state == BreakerHalfOpen {
+		b.trial = false
+		if failed {
+			b.open()
+		} else {
+			b.outcomes, b.next, b.failures = nil, 0, 0
+			b.setState(BreakerClosed)
+		}
+		return
+	}
+	if b.state == BreakerOpen {
+		return // a call allowed before the breaker opened
+	}
+
+	window := b.Window
+	if window <= 0 {
+		window = 20
+	}
+	if len(b.outcomes) < window {
+		b.outcomes = append(b.outcomes, failed)
+	} else {
+		if b.outcomes[b.next] {
+			b.failures--
+		}
+		b.outcomes[b.next] = failed
+		b.next = (b.next + 1) % window
+	}
+	if failed {
+		b.failures++
+	}
+	if b.MaxErrorRate > 0 && len(b.outcomes) >= b.MinCalls && float64(b.failures) >= b.MaxErrorRate*float64(len(b.outcomes)) {
+		b.open()
+	}
+}
+
+func (b *RateBreaker) open() {
+	b.openedAt = b.clock()
+	b.setState(BreakerOpen)
+}
+
+func (b *RateBreaker) setState(state BreakerState) {
+	if b.state != state {
+		b.changes = append(b.changes, stateChange{b.state, state})
+	}
+	b.state = state
+}
+
+// unlock releases the lock, then reports the state changes made while holding it.
+func (b *RateBreaker) unlock() {
+	changes := b.changes
+	b.changes = nil
+	b.mu.Unlock()
+	if b.OnStateChange == nil {
+		return
+	}
+	for _, c := range changes {
+		b.OnStateChange(c.from, c.to)
+	}
+}
+
+func (b *RateBreaker) clock() time.Time {
+	if b.now != nil {
+		return b.now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,101 @@
+package graphb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateBreaker(t *testing.T) {
+	now := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	var changes []string
+	var b *RateBreaker
+	b = &RateBreaker{
+		Window:       4,
+		MinCalls:     4,
+		MaxErrorRate: 0.5,
+		MaxLatency:   time.Second,
+		OpenFor:      time.Minute,
+		OnStateChange: func(from, to BreakerState) {
+			changes = append(changes, from.String()+"->"+to.String())
+			assert.Equal(t, to, b.State(), "called without holding the lock")
+		},
+		now: func() time.Time { return now },
+	}
+	fail := fmt.Errorf("down")
+
+	record := func(latency time.Duration, err error) {
+		assert.Nil(t, b.Allow())
+		b.Record(latency, err)
+	}
+	record(0, nil)
+	record(0, fail)
+	record(0, nil)
+	assert.Equal(t, BreakerClosed, b.State())
+	record(2*time.Second, nil) // too slow, 2 failures out of 4
+	assert.Equal(t, BreakerOpen, b.State())
+	assert.Equal(t, CircuitOpenErr{}, errors.Cause(b.Allow()))
+
+	now = now.Add(time.Minute)
+	assert.Nil(t, b.Allow())
+	assert.Equal(t, BreakerHalfOpen, b.State())
+	assert.Equal(t, CircuitOpenErr{}, errors.Cause(b.Allow()), "only one trial call")
+	b.Release()
+	assert.Equal(t, BreakerHalfOpen, b.State())
+	assert.Nil(t, b.Allow(), "a released trial call lets another call try")
+	b.Record(0, fail)
+	assert.Equal(t, BreakerOpen, b.State())
+
+	now = now.Add(time.Minute)
+	assert.Nil(t, b.Allow())
+	b.Record(0, nil)
+	assert.Equal(t, BreakerClosed, b.State())
+	record(0, fail)
+	assert.Equal(t, BreakerClosed, b.State(), "the window starts over")
+
+	assert.Equal(t, []string{"closed->open", "open->half-open", "half-open->open", "open->half-open", "half-open->closed"}, changes)
+}
+
+func TestWithCircuitBreaker(t *testing.T) {
+	calls := 0
+	execute := func(ctx context.Context, q *Query) (json.RawMessage, error) {
+		calls++
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("down")
+	}
+	b := &RateBreaker{MinCalls: 2, MaxErrorRate: 0.5, OpenFor: time.Minute}
+	wrapped := WithCircuitBreaker(execute, b)
+	q := MakeQuery(TypeQuery).SetFields(MakeField("me"))
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i := 0; i < 3; i++ {
+		_, err := wrapped(canceled, q)
+		assert.Equal(t, context.Canceled, errors.Cause(err))
+	}
+	assert.Equal(t, BreakerClosed, b.State())
+
+	wrapped(context.Background(), q)
+	assert.Equal(t, BreakerClosed, b.State(), "canceled calls are not recorded")
+	wrapped(context.Background(), q)
+	assert.Equal(t, BreakerOpen, b.State())
+	_, err := wrapped(context.Background(), q)
+	assert.Equal(t, CircuitOpenErr{}, errors.Cause(err))
+	assert.Equal(t, 5, calls)
+
+	// a canceled trial call leaves the breaker half-open
+	b.openedAt = time.Now().Add(-time.Minute)
+	_, err = wrapped(canceled, q)
+	assert.Equal(t, context.Canceled, errors.Cause(err))
+	assert.Equal(t, BreakerHalfOpen, b.State())
+	wrapped(context.Background(), q)
+	assert.Equal(t, BreakerOpen, b.State())
+	assert.Equal(t, 7, calls)
+}
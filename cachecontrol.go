@@ -0,0 +1,106 @@
+package graphb
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// CacheScope tells whether a cached response may be shared between users.
+type CacheScope string
+
+const (
+	CacheScopePublic  CacheScope = "PUBLIC"
+	CacheScopePrivate CacheScope = "PRIVATE"
+)
+
+// CacheControl returns a @cacheControl directive hinting how long the field may be cached, in whole seconds.
+// The scope is omitted when empty.
+func CacheControl(maxAge time.Duration, scope CacheScope) Directive {
+	d := Directive{Name: "cacheControl", Arguments: []Argument{ArgumentInt("maxAge", int(maxAge/time.Second))}}
+	if scope != "" {
+		d.Arguments = append(d.Arguments, ArgumentEnum("scope", string(scope)))
+	}
+	return d
+}
+
+// CacheHint is the cache hint of a response value, as reported by the cacheControl response extension.
+type CacheHint struct {
+	Path   []interface{} // The path of the value, like the path of a GraphQL error.
+	MaxAge time.Duration
+	Scope  CacheScope
+}
+
+// CachePolicy is how long and by whom a whole response may be cached.
+type CachePolicy struct {
+	MaxAge time.Duration
+	Scope  CacheScope
+}
+
+// CacheHints extracts the hints of the cacheControl extension from a whole response body, reporting false if there is none:
+//
+//	"extensions":{"cacheControl":{"version":1,"hints":[{"path":["user"],"maxAge":60,"scope":"PRIVATE"}]}}
+func CacheHints(response json.RawMessage) ([]CacheHint, bool) {
+	var body struct {
+		Extensions struct {
+			CacheControl *struct {
+				Hints []struct {
+					Path   []interface{} `json:"path"`
+					MaxAge int           `json:"maxAge"`
+					Scope  CacheScope    `json:"scope"`
+				} `json:"hints"`
+			} `json:"cacheControl"`
+		} `json:"extensions"`
+	}
+	if err := json.Unmarshal(response, &body); err != nil || body.Extensions.CacheControl == nil {
+		return nil, false
+	}
+	hints := make([]CacheHint, len(body.Extensions.CacheControl.Hints))
+	for i, h := range body.Extensions.CacheControl.Hints {
+		scope := h.Scope
+		if scope == "" {
+			scope = CacheScopePublic
+		}
+		hints[i] = CacheHint{Path: h.Path, MaxAge: time.Duration(h.MaxAge) * time.Second, Scope: scope}
+	}
+	return hints, true
+}
+
+// ResponseCachePolicy returns the policy of a whole response from its cache hints, reporting false if it has none:
+// the shortest max age of the hints, and the private scope if any hint is private.
+// Like servers computing the Cache-Control header, a root field of the data without a hint has a max age of 0,
+// so that it makes the whole response uncacheable. A client cache can use the policy as the time to live of the response.
+func ResponseCachePolicy(response json.RawMessage) (CachePolicy, bool) {
+	hints, ok := CacheHints(response)
+	if !ok || len(hints) == 0 {
+		return CachePolicy{}, false
+	}
+	policy := CachePolicy{MaxAge: hints[0].MaxAge, Scope: CacheScopePublic}
+	var body struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	json.Unmarshal(response, &body) // cannot fail, CacheHints decoded the response
+	for key := range body.Data {
+		if !hasRootHint(hints, key) {
+			policy.MaxAge = 0
+		}
+	}
+	for _, h := range hints {
+		if h.MaxAge < policy.MaxAge {
+			policy.MaxAge = h.MaxAge
+		}
+		if h.Scope == CacheScopePrivate {
+			policy.Scope = CacheScopePrivate
+		}
+	}
+	return policy, true
+}
+
+// hasRootHint reports whether hints has a hint for the root field of the given response key.
+func hasRootHint(hints []CacheHint, key string) bool {
+	for _, h := range hints {
+		if len(h.Path) == 1 && h.Path[0] == key {
+			return true
+		}
+	}
+	return false
+}
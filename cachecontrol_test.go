@@ -0,0 +1,45 @@
+package graphb
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheControl(t *testing.T) {
+	f := MakeField("user").AddDirectives(CacheControl(90*time.Second, CacheScopePrivate)).SetFields(
+		MakeField("avatar").AddDirectives(CacheControl(time.Hour, "")),
+	)
+	assert.Equal(t, "user@cacheControl(maxAge:90,scope:PRIVATE){avatar@cacheControl(maxAge:3600)}", StringFromChan(f.stringChan()))
+}
+
+func TestResponseCachePolicy(t *testing.T) {
+	response := json.RawMessage(`{"data":{"user":{"avatar":"a.png"}},"extensions":{"cacheControl":{"version":1,"hints":[
+		{"path":["user"],"maxAge":90,"scope":"PRIVATE"},
+		{"path":["user","avatar"],"maxAge":60}
+	]}}}`)
+	hints, ok := CacheHints(response)
+	assert.True(t, ok)
+	assert.Equal(t, []CacheHint{
+		{[]interface{}{"user"}, 90 * time.Second, CacheScopePrivate},
+		{[]interface{}{"user", "avatar"}, time.Minute, CacheScopePublic},
+	}, hints)
+
+	policy, ok := ResponseCachePolicy(response)
+	assert.True(t, ok)
+	assert.Equal(t, CachePolicy{time.Minute, CacheScopePrivate}, policy)
+
+	// a root field without a hint is not cacheable
+	policy, ok = ResponseCachePolicy(json.RawMessage(`{"data":{"user":{"avatar":"a.png"},"now":"12:00"},"extensions":{"cacheControl":{"version":1,"hints":[
+		{"path":["user"],"maxAge":90}
+	]}}}`))
+	assert.True(t, ok)
+	assert.Equal(t, CachePolicy{0, CacheScopePublic}, policy)
+
+	_, ok = ResponseCachePolicy(json.RawMessage(`{"data":{}}`))
+	assert.False(t, ok)
+	_, ok = ResponseCachePolicy(json.RawMessage(`{"extensions":{"cacheControl":{"version":1,"hints":[]}}}`))
+	assert.False(t, ok)
+}
@@ -0,0 +1,87 @@
+package graphb
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// piiClassification is the classification, or the prefix of classifications, which marks a field as PII.
+const piiClassification = "pii"
+
+// Classify labels this Field with data classifications, e.g. "pii:email".
+// Classifications prefixed by "pii" are reported by Query.PIIReport.
+func (f *Field) Classify(classifications ...string) *Field {
+	f.Classifications = append(f.Classifications, classifications...)
+	return f
+}
+
+// OfClassifications returns a FieldOption which labels the targeting field with data classifications.
+func OfClassifications(classifications ...string) FieldOption {
+	return func(f *Field) error {
+		f.Classifications = append(f.Classifications, classifications...)
+		return nil
+	}
+}
+
+// ClassifyArgument labels the argument of the given name of this Field with data classifications, e.g. "pii:ssn",
+// which designate its value for encryption, see EncryptionRule.
+func (f *Field) ClassifyArgument(name string, classifications ...string) *Field {
+	if f.ArgumentClassifications == nil {
+		f.ArgumentClassifications = make(map[string][]string)
+	}
+	f.ArgumentClassifications[name] = append(f.ArgumentClassifications[name], classifications...)
+	return f
+}
+
+// OfArgumentClassifications returns a FieldOption which labels the argument of the given name of the targeting field with data classifications.
+func OfArgumentClassifications(name string, classifications ...string) FieldOption {
+	return func(f *Field) error {
+		f.ClassifyArgument(name, classifications...)
+		return nil
+	}
+}
+
+// ClassifiedField is an entry of Query.PIIReport.
+type ClassifiedField struct {
+	Path            string // Field names from the operation root, joined by '.'.
+	Classifications []string
+}
+
+// PIIReport lists every requested field classified as PII, in the order the fields are serialized.
+// Only the PII classifications of a field are listed.
+func (q *Query) PIIReport() ([]ClassifiedField, error) {
+	if err := q.checkCycles(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var report []ClassifiedField
+	for _, f := range q.Fields {
+		report = append(report, f.piiReport("")...)
+	}
+	return report, nil
+}
+
+func (f *Field) piiReport(prefix string) []ClassifiedField {
+	if f == nil {
+		return nil
+	}
+	path := f.Name
+	if prefix != "" {
+		path = prefix + "." + f.Name
+	}
+
+	var report []ClassifiedField
+	var pii []string
+	for _, c := range f.Classifications {
+		if c == piiClassification || strings.HasPrefix(c, piiClassification+":") {
+			pii = append(pii, c)
+		}
+	}
+	if len(pii) > 0 {
+		report = append(report, ClassifiedField{path, pii})
+	}
+	for _, subF := range f.Fields {
+		report = append(report, subF.piiReport(path)...)
+	}
+	return report
+}
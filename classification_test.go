@@ -0,0 +1,41 @@
+package graphb
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestField_Classify(t *testing.T) {
+	f := MakeField("email").Classify("pii:email").Classify("internal")
+	assert.Equal(t, []string{"pii:email", "internal"}, f.Classifications)
+
+	f = NewField("email", OfClassifications("pii:email"))
+	assert.Equal(t, []string{"pii:email"}, f.Classifications)
+}
+
+func TestQuery_PIIReport(t *testing.T) {
+	q := MakeQuery(TypeQuery).SetFields(
+		MakeField("user").SetFields(
+			MakeField("id"),
+			MakeField("email").Classify("pii:email", "internal"),
+			MakeField("address").Classify("pii").SetFields(
+				MakeField("zip").Classify("pii:postal"),
+			),
+		),
+		MakeField("settings").Classify("piicture"),
+	)
+	report, err := q.PIIReport()
+	assert.Nil(t, err)
+	assert.Equal(t, []ClassifiedField{
+		{"user.email", []string{"pii:email"}},
+		{"user.address", []string{"pii"}},
+		{"user.address.zip", []string{"pii:postal"}},
+	}, report)
+
+	f := MakeField("a")
+	f.SetFields(f)
+	_, err = MakeQuery(TypeQuery).SetFields(f).PIIReport()
+	assert.IsType(t, CyclicFieldErr{}, errors.Cause(err))
+}
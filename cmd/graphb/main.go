@@ -0,0 +1,211 @@
+// Command graphb formats and checks executable GraphQL documents with the graphb serializer and validation,
+// e.g. in pre-commit hooks and build scripts, and sends them to GraphQL servers.
+//
+// Usage:
+//
+//	graphb fmt [-w] [-l] [-style pretty|spaced|compact] [file ...]
+//	graphb check [file ...]
+//	graphb send -endpoint url [-file file] [-operation name] [-var name=value ...] [-H 'key: value' ...]
+//
+// fmt prints the documents formatted, or rewrites the files with -w, or lists the files whose formatting differs with -l.
+// check prints the problems of the documents and exits with status 1 if any of them is an error.
+// Both read the standard input when no file is given.
+//
+// send sends an operation of the document in a POST request and prints the response, indented if it is JSON.
+// It exits with status 1 if the response has an error status or GraphQL errors.
+// The value of a variable of type String or ID is sent as is, the value of any other variable is decoded as JSON,
+// or sent as a string if it is not JSON, e.g. an enum value.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/udacity/graphb"
+)
+
+const usage = `usage:
+	graphb fmt [-w] [-l] [-style pretty|spaced|compact] [file ...]
+	graphb check [file ...]
+	graphb send -endpoint url [-file file] [-operation name] [-var name=value ...] [-H 'key: value' ...]
+`
+
+// stdinName is the name of the standard input in messages.
+const stdinName = "<stdin>"
+
+var styles = map[string]graphb.SerializerStyle{
+	"compact": graphb.StyleCompact,
+	"spaced":  graphb.StyleSpaced,
+	"pretty":  graphb.StylePretty,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs the command of args and returns the exit status.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	switch args[0] {
+	case "fmt":
+		return runFmt(args[1:], stdin, stdout, stderr)
+	case "check":
+		return runCheck(args[1:], stdin, stdout, stderr)
+	case "send":
+		return runSend(args[1:], stdin, stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	}
+	fmt.Fprintf(stderr, "graphb: unknown command %q\n%s", args[0], usage)
+	return 2
+}
+
+func runFmt(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	write := flags.Bool("w", false, "write the result to the files instead of the standard output")
+	list := flags.Bool("l", false, "list the files whose formatting differs")
+	styleName := flags.String("style", "pretty", "the white space of the result: pretty, spaced or compact")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	style, ok := styles[*styleName]
+	if !ok {
+		fmt.Fprintf(stderr, "graphb fmt: unknown style %q\n", *styleName)
+		return 2
+	}
+	if *write && flags.NArg() == 0 {
+		fmt.Fprintln(stderr, "graphb fmt: -w requires files")
+		return 2
+	}
+
+	status := 0
+	err := eachDocument(flags.Args(), stdin, func(name string, src []byte, doc *graphb.Document) error {
+		s, err := doc.Format(style)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		s += "\n"
+		if *list {
+			if s != string(src) {
+				fmt.Fprintln(stdout, name)
+			}
+			return nil
+		}
+		if *write {
+			if s == string(src) {
+				return nil
+			}
+			return errors.WithStack(os.WriteFile(name, []byte(s), 0644))
+		}
+		_, err = io.WriteString(stdout, s)
+		return errors.WithStack(err)
+	}, func(err error) {
+		fmt.Fprintf(stderr, "%v\n", err)
+		status = 1
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+	return status
+}
+
+func runCheck(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	status := 0
+	err := eachDocument(flags.Args(), stdin, func(name string, _ []byte, doc *graphb.Document) error {
+		err := doc.Validate()
+		if err == nil {
+			return nil
+		}
+		validationErr, ok := errors.Cause(err).(graphb.ValidationErr)
+		if !ok {
+			return errors.WithStack(err)
+		}
+		for _, p := range validationErr.Problems {
+			fmt.Fprintf(stdout, "%s: %s: %v\n", name, p.Severity, p.Err)
+			if p.Severity == graphb.SeverityError {
+				status = 1
+			}
+		}
+		return nil
+	}, func(err error) {
+		fmt.Fprintf(stdout, "%v\n", err)
+		status = 1
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+	return status
+}
+
+// eachDocument parses the documents of the files of names, or of stdin if there is none, and calls do with each of them.
+// The errors parsing a document or returned by do are passed to fail, which lets the other documents be processed.
+// The returned error is the one reading stdin.
+func eachDocument(names []string, stdin io.Reader, do func(name string, src []byte, doc *graphb.Document) error, fail func(error)) error {
+	if len(names) == 0 {
+		src, err := io.ReadAll(stdin)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		doc, err := graphb.ParseDocument(string(src))
+		if err != nil {
+			fail(errors.Wrapf(err, "%s", stdinName))
+			return nil
+		}
+		if err := do(stdinName, src, doc); err != nil {
+			fail(errors.Wrapf(err, "%s", stdinName))
+		}
+		return nil
+	}
+	for _, name := range names {
+		src, err := os.ReadFile(name)
+		if err != nil {
+			fail(errors.WithStack(err))
+			continue
+		}
+		// LoadDocument records the file name in the locations of the fields
+		doc, err := graphb.LoadDocument(fileFS{name, src}, name)
+		if err != nil {
+			fail(err)
+			continue
+		}
+		if err := do(name, src, doc); err != nil {
+			fail(errors.Wrapf(err, "%s", name))
+		}
+	}
+	return nil
+}
+
+// fileFS is the file system of a single file already read, which accepts the paths of the operating system.
+type fileFS struct {
+	name string
+	src  []byte
+}
+
+func (f fileFS) Open(name string) (fs.File, error) {
+	return os.Open(name)
+}
+
+// ReadFile implements fs.ReadFileFS, returning the file already read.
+func (f fileFS) ReadFile(name string) ([]byte, error) {
+	if name != f.name {
+		return os.ReadFile(name)
+	}
+	return f.src, nil
+}
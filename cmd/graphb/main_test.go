@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func runWith(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	status := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return status, stdout.String(), stderr.String()
+}
+
+func TestRun(t *testing.T) {
+	status, _, stderr := runWith("")
+	assert.Equal(t, 2, status)
+	assert.Contains(t, stderr, "usage:")
+
+	status, _, stderr = runWith("", "lint")
+	assert.Equal(t, 2, status)
+	assert.Contains(t, stderr, `unknown command "lint"`)
+}
+
+func TestFmt(t *testing.T) {
+	t.Run("stdin", func(t *testing.T) {
+		status, stdout, stderr := runWith(`{a(x:1){b,...F}} fragment F on T{c}`, "fmt", "-style", "spaced")
+		assert.Equal(t, 0, status)
+		assert.Equal(t, "query { a(x: 1) { b, ...F } } fragment F on T { c }\n", stdout)
+		assert.Empty(t, stderr)
+	})
+
+	t.Run("files", func(t *testing.T) {
+		dir := t.TempDir()
+		formatted := filepath.Join(dir, "formatted.graphql")
+		unformatted := filepath.Join(dir, "unformatted.graphql")
+		assert.Nil(t, os.WriteFile(formatted, []byte("query {\n  a\n}\n"), 0644))
+		assert.Nil(t, os.WriteFile(unformatted, []byte("# comment\n{ a b }"), 0644))
+
+		status, stdout, _ := runWith("", "fmt", "-l", formatted, unformatted)
+		assert.Equal(t, 0, status)
+		assert.Equal(t, unformatted+"\n", stdout)
+
+		status, stdout, _ = runWith("", "fmt", "-w", formatted, unformatted)
+		assert.Equal(t, 0, status)
+		assert.Empty(t, stdout)
+		b, err := os.ReadFile(unformatted)
+		assert.Nil(t, err)
+		assert.Equal(t, "query {\n  a\n  b\n}\n", string(b))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		status, _, stderr := runWith("{ a(", "fmt")
+		assert.Equal(t, 1, status)
+		assert.Contains(t, stderr, "<stdin>")
+
+		status, _, stderr = runWith("", "fmt", "-style", "wide")
+		assert.Equal(t, 2, status)
+		assert.Contains(t, stderr, `unknown style "wide"`)
+
+		status, _, stderr = runWith("{ a }", "fmt", "-w")
+		assert.Equal(t, 2, status)
+		assert.Contains(t, stderr, "-w requires files")
+
+		status, _, stderr = runWith("", "fmt", filepath.Join(t.TempDir(), "missing.graphql"))
+		assert.Equal(t, 1, status)
+		assert.Contains(t, stderr, "missing.graphql")
+	})
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.graphql")
+	invalid := filepath.Join(dir, "invalid.graphql")
+	assert.Nil(t, os.WriteFile(valid, []byte("query Q($id: ID!) { user(id: $id) { name } }"), 0644))
+	assert.Nil(t, os.WriteFile(invalid, []byte("query Q($id: ID!) {\n  user {\n    __name\n  }\n}"), 0644))
+
+	status, stdout, _ := runWith("", "check", valid)
+	assert.Equal(t, 0, status)
+	assert.Empty(t, stdout)
+
+	status, stdout, _ = runWith("", "check", valid, invalid)
+	assert.Equal(t, 1, status)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	assert.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], invalid+": error: "), lines[0])
+	assert.Contains(t, lines[0], invalid+":3")
+	assert.Contains(t, lines[0], "__name")
+	assert.Contains(t, lines[1], "$id")
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/udacity/graphb"
+)
+
+// repeated is a flag which may be given several times.
+type repeated []string
+
+func (r *repeated) String() string {
+	return strings.Join(*r, ",")
+}
+
+func (r *repeated) Set(value string) error {
+	*r = append(*r, value)
+	return nil
+}
+
+func runSend(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("send", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	endpoint := flags.String("endpoint", "", "the URL of the GraphQL server")
+	file := flags.String("file", "", "the document to send, the standard input if empty")
+	operation := flags.String("operation", "", "the name of the operation to send, if the document has several")
+	timeout := flags.Duration("timeout", 30*time.Second, "the time limit of the request")
+	var vars, headers repeated
+	flags.Var(&vars, "var", "a variable value as name=value, may be repeated")
+	flags.Var(&headers, "H", "a request header as 'key: value', may be repeated")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *endpoint == "" {
+		fmt.Fprintln(stderr, "graphb send: -endpoint is required")
+		return 2
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	req, err := newSendRequest(ctx, *endpoint, *file, *operation, vars, headers, stdin)
+	if err != nil {
+		fmt.Fprintf(stderr, "graphb send: %v\n", err)
+		return 1
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Fprintf(stderr, "graphb send: %v\n", err)
+		return 1
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		fmt.Fprintf(stderr, "graphb send: %v\n", err)
+		return 1
+	}
+
+	status := 0
+	if resp.StatusCode >= http.StatusBadRequest {
+		fmt.Fprintf(stderr, "graphb send: %s\n", resp.Status)
+		status = 1
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, body, "", "  "); err != nil {
+		stdout.Write(body)
+		return status
+	}
+	fmt.Fprintln(stdout, indented.String())
+	var result struct {
+		Errors []json.RawMessage `json:"errors"`
+	}
+	if json.Unmarshal(body, &result) == nil && len(result.Errors) > 0 {
+		status = 1
+	}
+	return status
+}
+
+// newSendRequest returns the request sending the operation of the document of file, or of stdin if file is empty,
+// with the values of vars given as name=value and the headers given as "key: value".
+func newSendRequest(ctx context.Context, endpoint, file, operation string, vars, headers []string, stdin io.Reader) (*http.Request, error) {
+	var doc *graphb.Document
+	var failed error
+	err := eachDocument(nonEmpty(file), stdin, func(_ string, _ []byte, d *graphb.Document) error {
+		doc = d
+		return nil
+	}, func(err error) {
+		failed = err
+	})
+	if err == nil {
+		err = failed
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	q, ok := doc.Operation(operation)
+	if !ok {
+		if operation == "" {
+			return nil, errors.New("the document has no single operation, select one with -operation")
+		}
+		return nil, errors.Errorf("no operation %q in the document", operation)
+	}
+
+	for _, v := range vars {
+		name, value, ok := strings.Cut(v, "=")
+		if !ok {
+			return nil, errors.Errorf("invalid -var %q, expected name=value", v)
+		}
+		if err := setVariable(q, name, value); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	var options []graphb.RequestOption
+	for _, h := range headers {
+		key, value, ok := strings.Cut(h, ":")
+		if !ok {
+			return nil, errors.Errorf("invalid -H %q, expected 'key: value'", h)
+		}
+		options = append(options, graphb.WithRequestHeader(strings.TrimSpace(key), strings.TrimSpace(value)))
+	}
+	req, err := q.NewRequest(ctx, http.MethodPost, endpoint, options...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return req, nil
+}
+
+// nonEmpty returns the names of the files to read, none for stdin.
+func nonEmpty(file string) []string {
+	if file == "" {
+		return nil
+	}
+	return []string{file}
+}
+
+// setVariable sets the value of the variable name of q.
+// The value of a String or ID variable is the text itself, the one of any other variable is decoded as JSON if possible.
+func setVariable(q *graphb.Query, name, text string) error {
+	for i := range q.Variables {
+		v := &q.Variables[i]
+		if v.Name != name {
+			continue
+		}
+		switch strings.TrimSuffix(v.Type, "!") {
+		case "String", "ID":
+			v.Value = text
+			return nil
+		}
+		var value interface{}
+		if err := json.Unmarshal([]byte(text), &value); err != nil {
+			v.Value = text
+			return nil
+		}
+		v.Value = value
+		return nil
+	}
+	return errors.Errorf("no variable $%s in operation %q", name, q.Name)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSend(t *testing.T) {
+	var request struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		b, _ := io.ReadAll(r.Body)
+		assert.Nil(t, json.Unmarshal(b, &request))
+		if request.Variables["id"] == "0" {
+			w.Write([]byte(`{"data":null,"errors":[{"message":"not found"}]}`))
+			return
+		}
+		w.Write([]byte(`{"data":{"user":{"name":"Ada"}}}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "op.graphql")
+	assert.Nil(t, os.WriteFile(file, []byte(`
+query User($id: ID!, $first: Int, $order: Order) { user(id: $id) { name, posts(first: $first, order: $order) { title } } }
+query Me { me { name } }`), 0644))
+
+	status, stdout, stderr := runWith("", "send", "-endpoint", server.URL, "-file", file, "-operation", "User",
+		"-var", "id=5", "-var", "first=10", "-var", "order=NEWEST", "-H", "Authorization: Bearer token")
+	assert.Equal(t, 0, status, stderr)
+	assert.Equal(t, "{\n  \"data\": {\n    \"user\": {\n      \"name\": \"Ada\"\n    }\n  }\n}\n", stdout)
+	assert.Equal(t, "query User($id:ID!,$first:Int,$order:Order){user(id:$id){name,posts(first:$first,order:$order){title}}}", request.Query)
+	assert.Equal(t, map[string]interface{}{"id": "5", "first": float64(10), "order": "NEWEST"}, request.Variables)
+	assert.Equal(t, "Bearer token", header.Get("Authorization"))
+
+	t.Run("stdin", func(t *testing.T) {
+		status, stdout, _ := runWith(`{ me { name } }`, "send", "-endpoint", server.URL)
+		assert.Equal(t, 0, status)
+		assert.Contains(t, stdout, "Ada")
+		assert.Equal(t, "query{me{name}}", request.Query)
+	})
+
+	t.Run("GraphQL errors", func(t *testing.T) {
+		status, stdout, _ := runWith("", "send", "-endpoint", server.URL, "-file", file, "-operation", "User", "-var", "id=0")
+		assert.Equal(t, 1, status)
+		assert.Contains(t, stdout, "not found")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		status, _, stderr := runWith("{ a }", "send")
+		assert.Equal(t, 2, status)
+		assert.Contains(t, stderr, "-endpoint is required")
+
+		status, _, stderr = runWith("", "send", "-endpoint", server.URL, "-file", file)
+		assert.Equal(t, 1, status)
+		assert.Contains(t, stderr, "select one with -operation")
+
+		status, _, stderr = runWith("", "send", "-endpoint", server.URL, "-file", file, "-operation", "Me", "-var", "id=1")
+		assert.Equal(t, 1, status)
+		assert.Contains(t, stderr, `no variable $id in operation "Me"`)
+
+		status, _, stderr = runWith("", "send", "-endpoint", server.URL, "-file", file, "-operation", "User", "-var", "id")
+		assert.Equal(t, 1, status)
+		assert.Contains(t, stderr, `invalid -var "id"`)
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}))
+		defer failing.Close()
+		status, stdout, stderr := runWith("{ a }", "send", "-endpoint", failing.URL)
+		assert.Equal(t, 1, status)
+		assert.Equal(t, "unavailable\n", stdout)
+		assert.Contains(t, stderr, "503")
+	})
+}
@@ -0,0 +1,307 @@
+package graphb
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// GenerateBuilderCode returns the Go source of functions building the operations of an executable GraphQL document with graphb,
+// one function per operation such as `func GetUserQuery() (*graphb.Query, error)`, to migrate hand-written query strings.
+// Fragment spreads become inline fragments on the type condition of the fragment.
+// Values whose constructor returns an error, such as floats and null, are built first and their errors returned.
+func GenerateBuilderCode(doc string) (string, error) {
+	d, err := ParseDocument(doc)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	var src strings.Builder
+	for _, q := range d.Operations {
+		g := &builderCode{}
+		code, err := g.operation(q)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		src.WriteString(code)
+	}
+	formatted, err := format.Source([]byte(src.String()))
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return strings.TrimSuffix(string(formatted), "\n"), nil
+}
+
+// builderCode generates the code of an operation.
+type builderCode struct {
+	prelude strings.Builder // The statements building the arguments whose constructor returns an error.
+	vars    int
+}
+
+func (g *builderCode) operation(q *Query) (string, error) {
+	var body strings.Builder
+	operation := exportedName(string(q.Type.Canonical()))
+	fmt.Fprintf(&body, "graphb.MakeQuery(graphb.Type%s)", operation)
+	if q.Name != "" {
+		fmt.Fprintf(&body, ".\nSetName(%q)", q.Name)
+	}
+	if len(q.Variables) > 0 {
+		body.WriteString(".\nAddVariables(\n")
+		for _, v := range q.Variables {
+			fmt.Fprintf(&body, "graphb.Variable{Name: %q, Type: %q}", v.Name, v.Type)
+			if v.defaultValue != nil {
+				arg, err := g.argument(v.Name, v.defaultValue)
+				if err != nil {
+					return "", errors.WithStack(err)
+				}
+				fmt.Fprintf(&body, ".WithDefault(%s)", arg)
+			}
+			body.WriteString(",\n")
+		}
+		body.WriteString(")")
+	}
+	if len(q.Directives) > 0 {
+		directives, err := g.directives(q.Directives)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		fmt.Fprintf(&body, ".\nAddDirectives(%s)", directives)
+	}
+	fields, err := g.fields(q.Fields)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	fmt.Fprintf(&body, ".\nSetFields(%s)", fields)
+
+	return fmt.Sprintf("func %s%s() (*graphb.Query, error) {\n%sreturn %s, nil\n}\n\n",
+		exportedName(q.Name), operation, g.prelude.String(), body.String()), nil
+}
+
+func (g *builderCode) fields(fields []*Field) (string, error) {
+	var code strings.Builder
+	code.WriteString("\n")
+	for _, f := range fields {
+		fmt.Fprintf(&code, "graphb.MakeField(%q)", f.Name)
+		if f.Alias != "" {
+			fmt.Fprintf(&code, ".SetAlias(%q)", f.Alias)
+		}
+		if len(f.Arguments) > 0 {
+			args, err := g.arguments(f.Arguments)
+			if err != nil {
+				return "", errors.WithStack(err)
+			}
+			fmt.Fprintf(&code, ".SetArguments(%s)", args)
+		}
+		if len(f.Directives) > 0 {
+			directives, err := g.directives(f.Directives)
+			if err != nil {
+				return "", errors.WithStack(err)
+			}
+			fmt.Fprintf(&code, ".AddDirectives(%s)", directives)
+		}
+		if len(f.Fields) > 0 {
+			subFields, err := g.fields(f.Fields)
+			if err != nil {
+				return "", errors.WithStack(err)
+			}
+			fmt.Fprintf(&code, ".SetFields(%s)", subFields)
+		}
+		code.WriteString(",\n")
+	}
+	return code.String(), nil
+}
+
+func (g *builderCode) directives(directives []Directive) (string, error) {
+	codes := make([]string, len(directives))
+	for i, d := range directives {
+		codes[i] = fmt.Sprintf("graphb.MakeDirective(%q)", d.Name)
+		if len(d.Arguments) > 0 {
+			args, err := g.arguments(d.Arguments)
+			if err != nil {
+				return "", errors.WithStack(err)
+			}
+			codes[i] = fmt.Sprintf("graphb.MakeDirective(%q, %s)", d.Name, args)
+		}
+	}
+	return strings.Join(codes, ", "), nil
+}
+
+func (g *builderCode) arguments(args []Argument) (string, error) {
+	codes := make([]string, len(args))
+	for i, arg := range args {
+		code, err := g.argument(arg.Name, arg.Value)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		codes[i] = code
+	}
+	return strings.Join(codes, ", "), nil
+}
+
+// argument returns the code of an argument built by the Argument function of its value.
+func (g *builderCode) argument(name string, value argumentValue) (string, error) {
+	switch v := value.(type) {
+	case argInt:
+		return fmt.Sprintf("graphb.ArgumentInt(%q, %d)", name, v), nil
+	case argBool:
+		return fmt.Sprintf("graphb.ArgumentBool(%q, %t)", name, v), nil
+	case argEnum:
+		return fmt.Sprintf("graphb.ArgumentEnum(%q, %q)", name, v), nil
+	case argVariable:
+		return fmt.Sprintf("graphb.ArgumentVariable(%q, %q)", name, v), nil
+	case argumentCustom:
+		args, err := g.arguments(v)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		if len(v) == 0 {
+			return fmt.Sprintf("graphb.ArgumentCustomType(%q)", name), nil
+		}
+		return fmt.Sprintf("graphb.ArgumentCustomType(%q, %s)", name, args), nil
+	case argList:
+		if code, ok, err := g.slice(name, v); ok || err != nil {
+			return code, errors.WithStack(err)
+		}
+	case argTokens:
+		if len(v) == 1 && v[0].Kind == TokenString {
+			if s := v[0].Literal; strings.HasPrefix(s, `"""`) {
+				return fmt.Sprintf("graphb.ArgumentBlockString(%q, %q)", name, s[3:len(s)-3]), nil
+			}
+			if s, ok := plainStringValue(v[0]); ok {
+				return fmt.Sprintf("graphb.ArgumentString(%q, %q)", name, s), nil
+			}
+		}
+		if len(v) == 1 && v[0].Kind == TokenFloat {
+			return g.fallible(fmt.Sprintf("graphb.ArgumentFloat(%q, %s)", name, v[0].Literal)), nil
+		}
+	}
+	raw, ok := jsonOfValue(value)
+	if !ok {
+		return "", errors.WithStack(UnsupportedBuilderValueErr{name})
+	}
+	return g.fallible(fmt.Sprintf("graphb.ArgumentJSON(%q, []byte(%q))", name, raw)), nil
+}
+
+// slice returns the code of a list argument built by a slice Argument function, if its elements have a common type.
+func (g *builderCode) slice(name string, list argList) (string, bool, error) {
+	if len(list) == 0 {
+		return "", false, nil
+	}
+	elements := make([]string, len(list))
+	var function string
+	for i, value := range list {
+		var f string
+		switch v := value.(type) {
+		case argInt:
+			f, elements[i] = "ArgumentIntSlice", strconv.Itoa(int(v))
+		case argBool:
+			f, elements[i] = "ArgumentBoolSlice", strconv.FormatBool(bool(v))
+		case argEnum:
+			f, elements[i] = "ArgumentEnumSlice", strconv.Quote(string(v))
+		case argTokens:
+			s, ok := "", false
+			if len(v) == 1 && !strings.HasPrefix(v[0].Literal, `"""`) {
+				s, ok = plainStringValue(v[0])
+			}
+			if !ok {
+				return "", false, nil
+			}
+			f, elements[i] = "ArgumentStringSlice", strconv.Quote(s)
+		case argumentCustom:
+			args, err := g.arguments(v)
+			if err != nil {
+				return "", false, errors.WithStack(err)
+			}
+			f, elements[i] = "ArgumentSlice", "[]graphb.Argument{"+args+"}"
+		default:
+			return "", false, nil
+		}
+		if function != "" && f != function {
+			return "", false, nil
+		}
+		function = f
+	}
+	return fmt.Sprintf("graphb.%s(%q, %s)", function, name, strings.Join(elements, ", ")), true, nil
+}
+
+// fallible adds the statements building an argument whose constructor returns an error to the prelude,
+// and returns the variable holding it.
+func (g *builderCode) fallible(call string) string {
+	g.vars++
+	name := fmt.Sprintf("arg%d", g.vars)
+	fmt.Fprintf(&g.prelude, "%s, err := %s\nif err != nil {\nreturn nil, err\n}\n", name, call)
+	return name
+}
+
+// stringValue returns the value of a string token which is not a block string.
+func stringValue(tok Token) (string, bool) {
+	var s string
+	if tok.Kind != TokenString || json.Unmarshal([]byte(tok.Literal), &s) != nil {
+		return "", false
+	}
+	return s, true
+}
+
+// plainStringValue returns the value of a string token which is not a block string, if it serializes unescaped,
+// as ArgumentString and ArgumentStringSlice serialize it. A value to escape is built from its JSON instead.
+func plainStringValue(tok Token) (string, bool) {
+	s, ok := stringValue(tok)
+	if !ok || jsonString(s) != `"`+s+`"` {
+		return "", false
+	}
+	return s, true
+}
+
+// jsonOfValue returns the JSON of a parsed value, if it has one. Enum values and variables do not.
+func jsonOfValue(value argumentValue) (string, bool) {
+	switch v := value.(type) {
+	case argInt:
+		return strconv.Itoa(int(v)), true
+	case argBool:
+		return strconv.FormatBool(bool(v)), true
+	case argTokens:
+		if len(v) != 1 {
+			return "", false
+		}
+		switch v[0].Kind {
+		case TokenInt, TokenFloat, TokenNull:
+			return v[0].Literal, true
+		case TokenString:
+			if s, ok := stringValue(v[0]); ok {
+				return jsonString(s), true
+			}
+		}
+	case argList:
+		elements := make([]string, len(v))
+		for i := range v {
+			element, ok := jsonOfValue(v[i])
+			if !ok {
+				return "", false
+			}
+			elements[i] = element
+		}
+		return "[" + strings.Join(elements, ",") + "]", true
+	case argumentCustom:
+		members := make([]string, len(v))
+		for i := range v {
+			member, ok := jsonOfValue(v[i].Value)
+			if !ok {
+				return "", false
+			}
+			members[i] = jsonString(v[i].Name) + ":" + member
+		}
+		return "{" + strings.Join(members, ",") + "}", true
+	}
+	return "", false
+}
+
+// exportedName returns name with an upper case first letter.
+func exportedName(name string) string {
+	if name == "" {
+		return ""
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
@@ -0,0 +1,111 @@
+package graphb
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateBuilderCode(t *testing.T) {
+	code, err := GenerateBuilderCode(`
+query getUser($id: ID!, $first: Int = 10) {
+  user(id: $id) {
+    handle: name
+    posts(first: $first, orderBy: {field: CREATED_AT, desc: true}, tags: ["a", "b"]) @include(if: true) {
+      title
+      rating(min: 1.5, scale: null)
+    }
+    ...Contact
+  }
+}
+mutation { like(ids: [1, 2], note: """a "quote" """, keys: [1.5, null]) { ok } }
+fragment Contact on User { email }`)
+	assert.Nil(t, err)
+	assert.Equal(t, `func GetUserQuery() (*graphb.Query, error) {
+	arg1, err := graphb.ArgumentFloat("min", 1.5)
+	if err != nil {
+		return nil, err
+	}
+	arg2, err := graphb.ArgumentJSON("scale", []byte("null"))
+	if err != nil {
+		return nil, err
+	}
+	return graphb.MakeQuery(graphb.TypeQuery).
+		SetName("getUser").
+		AddVariables(
+			graphb.Variable{Name: "id", Type: "ID!"},
+			graphb.Variable{Name: "first", Type: "Int"}.WithDefault(graphb.ArgumentInt("first", 10)),
+		).
+		SetFields(
+			graphb.MakeField("user").SetArguments(graphb.ArgumentVariable("id", "id")).SetFields(
+				graphb.MakeField("name").SetAlias("handle"),
+				graphb.MakeField("posts").SetArguments(graphb.ArgumentVariable("first", "first"), graphb.ArgumentCustomType("orderBy", graphb.ArgumentEnum("field", "CREATED_AT"), graphb.ArgumentBool("desc", true)), graphb.ArgumentStringSlice("tags", "a", "b")).AddDirectives(graphb.MakeDirective("include", graphb.ArgumentBool("if", true))).SetFields(
+					graphb.MakeField("title"),
+					graphb.MakeField("rating").SetArguments(arg1, arg2),
+				),
+				graphb.MakeField("... on User").SetFields(
+					graphb.MakeField("email"),
+				),
+			),
+		), nil
+}
+
+func Mutation() (*graphb.Query, error) {
+	arg1, err := graphb.ArgumentJSON("keys", []byte("[1.5,null]"))
+	if err != nil {
+		return nil, err
+	}
+	return graphb.MakeQuery(graphb.TypeMutation).
+		SetFields(
+			graphb.MakeField("like").SetArguments(graphb.ArgumentIntSlice("ids", 1, 2), graphb.ArgumentBlockString("note", "a \"quote\" "), arg1).SetFields(
+				graphb.MakeField("ok"),
+			),
+		), nil
+}
+`, code)
+
+	_, err = GenerateBuilderCode(`{ a(b: [[RED]]) }`)
+	assert.Equal(t, UnsupportedBuilderValueErr{"b"}, errors.Cause(err))
+
+	_, err = GenerateBuilderCode(`{ a(`)
+	assert.IsType(t, SyntaxErr{}, errors.Cause(err))
+}
+
+func TestVariable_WithDefault(t *testing.T) {
+	q := MakeQuery(TypeQuery).
+		AddVariables(Variable{Name: "first", Type: "Int"}.WithDefault(ArgumentInt("first", 10))).
+		SetFields(MakeField("posts").SetArguments(ArgumentVariable("first", "first")).SetFields(MakeField("id")))
+	s, err := q.StringChan()
+	assert.Nil(t, err)
+	assert.Equal(t, "query($first:Int=10){posts(first:$first){id}}", StringFromChan(s))
+}
+
+func TestGenerateBuilderCode_escapedStrings(t *testing.T) {
+	code, err := GenerateBuilderCode(`{ a(b: "say \"hi\"\nbye", c: ["x", "\\"]) }`)
+	assert.Nil(t, err)
+	assert.Equal(t, `func Query() (*graphb.Query, error) {
+	arg1, err := graphb.ArgumentJSON("b", []byte("\"say \\\"hi\\\"\\nbye\""))
+	if err != nil {
+		return nil, err
+	}
+	arg2, err := graphb.ArgumentJSON("c", []byte("[\"x\",\"\\\\\"]"))
+	if err != nil {
+		return nil, err
+	}
+	return graphb.MakeQuery(graphb.TypeQuery).
+		SetFields(
+			graphb.MakeField("a").SetArguments(arg1, arg2),
+		), nil
+}
+`, code)
+
+	// the arguments built by the generated code serialize as the source
+	b, err := ArgumentJSON("b", []byte("\"say \\\"hi\\\"\\nbye\""))
+	assert.Nil(t, err)
+	c, err := ArgumentJSON("c", []byte("[\"x\",\"\\\\\"]"))
+	assert.Nil(t, err)
+	s, err := MakeQuery(TypeQuery).SetFields(MakeField("a").SetArguments(b, c)).StringChan()
+	assert.Nil(t, err)
+	assert.Equal(t, `query{a(b:"say \"hi\"\nbye",c:["x","\\"])}`, StringFromChan(s))
+}
@@ -0,0 +1,42 @@
+package graphb
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Compile validates this Field and freezes its subtree into its serialized tokens,
+// so that a selection reused by many queries, e.g. a standard block of user fields, is emitted by copying the tokens
+// instead of walking the tree again for every query.
+// Changes made to the subtree after Compile are not serialized, call Compile again to refreeze.
+// A Query with a Config does not use the frozen tokens, which were not serialized with its conventions:
+// it serializes the subtree as it is, including the changes made after Compile.
+func (f *Field) Compile() (*Field, error) {
+	if err := f.check(); err != nil {
+		return f, errors.WithStack(err)
+	}
+	f.compiled = nil
+	var tokens []Token
+	for tok := range f.tokenChan() {
+		tokens = append(tokens, tok)
+	}
+	f.compiled = tokens
+	f.touch()
+	return f, nil
+}
+
+// IsCompiled reports whether this Field is frozen by Compile.
+func (f *Field) IsCompiled() bool {
+	return f.compiled != nil
+}
+
+// compiledTokenChan emits the frozen tokens of this Field.
+func (f *Field) compiledTokenChan() <-chan Token {
+	tokenChan := make(chan Token)
+	go func() {
+		for _, tok := range f.compiled {
+			tokenChan <- tok
+		}
+		close(tokenChan)
+	}()
+	return tokenChan
+}
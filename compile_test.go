@@ -0,0 +1,71 @@
+package graphb
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestField_Compile(t *testing.T) {
+	userFields, err := MakeField("user").
+		SetArguments(ArgumentInt("id", 1)).
+		SetFields(MakeField("id"), MakeField("name")).
+		Compile()
+	assert.Nil(t, err)
+	assert.True(t, userFields.IsCompiled())
+
+	q := MakeQuery(TypeQuery).SetFields(userFields, MakeField("me").SetFields(userFields))
+	s, err := q.JSON()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"query":"query{user(id:1){id,name},me{user(id:1){id,name}}}"}`, s)
+
+	// frozen until compiled again
+	userFields.AddArguments(ArgumentBool("active", true))
+	s, err = q.JSON()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"query":"query{user(id:1){id,name},me{user(id:1){id,name}}}"}`, s)
+
+	_, err = userFields.Compile()
+	assert.Nil(t, err)
+	s, err = q.JSON()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"query":"query{user(id:1,active:true){id,name},me{user(id:1,active:true){id,name}}}"}`, s)
+
+	f, err := MakeField("1").Compile()
+	assert.IsType(t, InvalidNameErr{}, errors.Cause(err))
+	assert.False(t, f.IsCompiled())
+}
+
+func TestField_Compile_copies(t *testing.T) {
+	login, err := MakeField("login").
+		SetArguments(ArgumentString("password", "hunter2")).
+		SetFields(MakeField("token"), MakeField("secret").RequireScope("admin")).
+		Compile()
+	assert.Nil(t, err)
+	q := MakeQuery(TypeMutation).SetFields(login)
+
+	s, err := q.StringRedacted("password")
+	assert.Nil(t, err)
+	assert.Equal(t, `mutation{login(password:"<redacted>"){token,secret}}`, s)
+
+	strCh, err := q.StripUnauthorized(nil).StringChan()
+	assert.Nil(t, err)
+	assert.Equal(t, `mutation{login(password:"hunter2"){token}}`, StringFromChan(strCh))
+}
+
+func TestField_Compile_config(t *testing.T) {
+	userFields, err := MakeField("user").SetFields(MakeField("id")).Compile()
+	assert.Nil(t, err)
+	userFields.SetFields(MakeField("id"), MakeField("name"))
+
+	s, err := MakeQuery(TypeQuery).SetFields(userFields).JSON()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"query":"query{user{id}}"}`, s)
+
+	// not frozen with a Config, changes made after Compile are serialized
+	s, err = MakeQuery(TypeQuery, NewConfig(WithTypenameInjection())).SetFields(userFields).JSON()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"query":"query{user{id,name,__typename}}"}`, s)
+	assert.True(t, userFields.IsCompiled())
+}
@@ -0,0 +1,234 @@
+package graphb
+
+import (
+	"time"
+)
+
+// Config holds serialization conventions applied to every Query made with it, see MakeQuery and OfConfig,
+// so that teams can enforce them in one place.
+type Config struct {
+	TimeFormat          string          // The layout of time values, time.RFC3339 by default.
+	TimeLocation        *time.Location  // The location time values are converted to before formatting, unless nil.
+	StrictEscaping      bool            // Whether string values are escaped, see WithStrictEscaping.
+	TypenameInjection   bool            // Whether __typename is selected in every selection set, see WithTypenameInjection.
+	NormalizeEnumValues bool            // Whether enum values are serialized in upper snake case, see WithEnumNormalization.
+	Style               SerializerStyle // The white space queries are serialized with, StyleCompact by default.
+
+	AllowReservedNames bool // Whether validation accepts names reserved for introspection, see WithReservedNames.
+	CheckIntRange      bool // Whether validation rejects Int values out of 32 bits, see WithIntRangeCheck.
+	CheckSelections    bool // Whether validation rejects empty selection sets, see WithSelectionCheck.
+	SkipNilFields      bool // Whether nil fields are left out instead of rejected, see WithNilFieldsSkipped.
+	CheckSharedFields  bool // Whether validation rejects a Field at several positions, see WithSharedFieldCheck.
+}
+
+// ConfigOption sets an option of a Config.
+type ConfigOption func(c *Config)
+
+// NewConfig returns a Config with the given options.
+func NewConfig(options ...ConfigOption) *Config {
+	c := &Config{TimeFormat: time.RFC3339}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// WithDefaultTimeFormat makes time values formatted with layout, e.g. time.RFC3339Nano.
+func WithDefaultTimeFormat(layout string) ConfigOption {
+	return func(c *Config) {
+		c.TimeFormat = layout
+	}
+}
+
+// WithTimeLocation makes time values converted to loc before formatting, e.g. time.UTC,
+// so that the offsets sent do not depend on the time zone of the deployment.
+func WithTimeLocation(loc *time.Location) ConfigOption {
+	return func(c *Config) {
+		c.TimeLocation = loc
+	}
+}
+
+// WithStrictEscaping makes string values escaped like JSON strings, which GraphQL string values share.
+// Otherwise, string values are serialized as they are, which breaks on quotes, backslashes and control characters.
+func WithStrictEscaping() ConfigOption {
+	return func(c *Config) {
+		c.StrictEscaping = true
+	}
+}
+
+// WithTypenameInjection makes every selection set but the operation's select __typename, if it does not already,
+// as normalized caches of client libraries need it.
+func WithTypenameInjection() ConfigOption {
+	return func(c *Config) {
+		c.TypenameInjection = true
+	}
+}
+
+// WithSerializerStyle returns a ConfigOption which serializes queries with the white space of style,
+// e.g. StyleSpaced or StylePretty for logs and diffs that humans read.
+func WithSerializerStyle(style SerializerStyle) ConfigOption {
+	return func(c *Config) {
+		c.Style = style
+	}
+}
+
+// WithEnumNormalization makes enum values normalized to upper snake case with EnumValue, e.g. "inProgress" to "IN_PROGRESS",
+// before validation and serialization.
+func WithEnumNormalization() ConfigOption {
+	return func(c *Config) {
+		c.NormalizeEnumValues = true
+	}
+}
+
+// WithReservedNames turns off the validation of reserved names, e.g. for a gateway forwarding the fields of a schema
+// which defines its own "__" fields.
+func WithReservedNames() ConfigOption {
+	return func(c *Config) {
+		c.AllowReservedNames = true
+	}
+}
+
+// WithIntRangeCheck makes validation reject Int values out of the signed 32-bit range of the Int scalar,
+// see: http://facebook.github.io/graphql/October2016/#sec-Int
+// Some servers silently overflow such values. Use ArgumentLong for 64-bit values.
+func WithIntRangeCheck() ConfigOption {
+	return func(c *Config) {
+		c.CheckIntRange = true
+	}
+}
+
+// WithSelectionCheck makes validation reject selection sets which would be serialized empty, e.g. `user{}`,
+// i.e. a query or a fragment without fields.
+// Without a schema, a composite field without sub fields is serialized as a leaf and can not be told apart.
+func WithSelectionCheck() ConfigOption {
+	return func(c *Config) {
+		c.CheckSelections = true
+	}
+}
+
+// WithNilFieldsSkipped makes nil fields left out of serialization instead of rejected with a NilFieldErr,
+// so that fields built conditionally can be added without checking them, e.g. AddFields(optionalField()).
+func WithNilFieldsSkipped() ConfigOption {
+	return func(c *Config) {
+		c.SkipNilFields = true
+	}
+}
+
+// WithSharedFieldCheck makes validation reject a *Field at several positions of a query, e.g. added to two parents,
+// which is serialized at each of them but changed at all of them by any change, a frequent aliasing bug.
+// Fields spread from a fragment are not checked, they are shared on purpose.
+func WithSharedFieldCheck() ConfigOption {
+	return func(c *Config) {
+		c.CheckSharedFields = true
+	}
+}
+
+// skipsNilFields reports whether c, which may be nil, leaves nil fields out.
+func (c *Config) skipsNilFields() bool {
+	return c != nil && c.SkipNilFields
+}
+
+// OfConfig returns a QueryOption which sets the Config of a query.
+func OfConfig(c *Config) QueryOption {
+	return func(query *Query) error {
+		query.Config = c
+		return nil
+	}
+}
+
+// timeFormat returns the layout of time values, time.RFC3339 for a Config not made with NewConfig.
+func (c *Config) timeFormat() string {
+	if c.TimeFormat == "" {
+		return time.RFC3339
+	}
+	return c.TimeFormat
+}
+
+// configure returns a copy of fs following the conventions of c.
+func (c *Config) configure(fs []*Field, root bool) []*Field {
+	if fs == nil {
+		return nil
+	}
+	configured := make([]*Field, 0, len(fs)+1)
+	hasTypename := false
+	for _, f := range fs {
+		if f == nil {
+			if !c.SkipNilFields {
+				configured = append(configured, nil)
+			}
+			continue
+		}
+		hasTypename = hasTypename || (f.Name == "__typename" && f.Alias == "")
+		copied := *f
+		// the frozen tokens of Field.Compile do not follow c, the subtree is configured as it is instead
+		copied.compiled = nil
+		copied.Arguments = c.configureArguments(f.Arguments)
+		copied.Fields = c.configure(f.Fields, false)
+		configured = append(configured, &copied)
+	}
+	if c.TypenameInjection && !root && !hasTypename {
+		configured = append(configured, &Field{Name: "__typename"})
+	}
+	return configured
+}
+
+func (c *Config) configureArguments(args []Argument) []Argument {
+	if args == nil {
+		return nil
+	}
+	configured := make([]Argument, len(args))
+	for i, arg := range args {
+		configured[i] = Argument{arg.Name, c.configureValue(arg.Value)}
+	}
+	return configured
+}
+
+func (c *Config) configureValue(value argumentValue) argumentValue {
+	switch v := value.(type) {
+	case argTime:
+		t := time.Time(v)
+		if c.TimeLocation != nil {
+			t = t.In(c.TimeLocation)
+		}
+		return argTokens{{TokenString, `"` + t.Format(c.timeFormat()) + `"`}}
+	case argString:
+		if c.StrictEscaping {
+			return argTokens{{TokenString, jsonString(string(v))}}
+		}
+	case argStringSlice:
+		if c.StrictEscaping {
+			list := make(argList, len(v))
+			for i, s := range v {
+				list[i] = argTokens{{TokenString, jsonString(s)}}
+			}
+			return list
+		}
+	case argEnum:
+		return argEnum(c.enumValue(string(v)))
+	case argEnumSlice:
+		if c.NormalizeEnumValues {
+			normalized := make(argEnumSlice, len(v))
+			for i, s := range v {
+				normalized[i] = EnumValue(s)
+			}
+			return normalized
+		}
+	case argumentCustom:
+		return argumentCustom(c.configureArguments(v))
+	case argArgSlice:
+		configured := make(argArgSlice, len(v))
+		for i, args := range v {
+			configured[i] = c.configureArguments(args)
+		}
+		return configured
+	case argList:
+		configured := make(argList, len(v))
+		for i, value := range v {
+			configured[i] = c.configureValue(value)
+		}
+		return configured
+	case argDefaulted:
+		return argDefaulted{c.configureValue(v.value), c.configureValue(v.defaultValue)}
+	}
+	return value
+}
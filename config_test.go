@@ -0,0 +1,125 @@
+package graphb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig(t *testing.T) {
+	at := time.Date(2018, 1, 2, 3, 4, 5, 600, time.UTC)
+	build := func(config ...*Config) *Query {
+		return MakeQuery(TypeQuery, config...).SetFields(
+			MakeField("posts").
+				SetArguments(ArgumentTime("since", at), ArgumentString("q", `say "hi"`), ArgumentStringSlice("tags", `a\b`)).
+				SetFields(MakeField("id"), MakeField("author").SetFields(MakeField("name"), MakeField("__typename"))),
+		)
+	}
+
+	s, err := build().JSON()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"query":"query{posts(since:\"2018-01-02T03:04:05Z\",q:\"say \"hi\"\",tags:[\"a\\b\"]){id,author{name,__typename}}}"}`, s)
+
+	c := NewConfig(WithDefaultTimeFormat(time.RFC3339Nano), WithStrictEscaping(), WithTypenameInjection())
+	q := build(c)
+	strCh, err := q.StringChan()
+	assert.Nil(t, err)
+	assert.Equal(t, `query{posts(since:"2018-01-02T03:04:05.0000006Z",q:"say \"hi\"",tags:["a\\b"]){id,author{name,__typename},__typename}}`, StringFromChan(strCh))
+	// the fields are not modified
+	assert.Len(t, q.Fields[0].Fields, 2)
+
+	q = NewQuery(TypeQuery, OfConfig(NewConfig(WithDefaultTimeFormat("2006-01-02"))), OfField("posts", OfArguments(ArgumentTime("since", at)), OfFields("id")))
+	assert.Nil(t, q.E)
+	s, err = q.StringParallel(2)
+	assert.Nil(t, err)
+	assert.Equal(t, `query{posts(since:"2018-01-02"){id}}`, s)
+}
+
+func TestConfig_zeroValue(t *testing.T) {
+	at := time.Date(2018, 1, 2, 3, 4, 5, 600, time.UTC)
+	q := MakeQuery(TypeQuery, &Config{StrictEscaping: true}).SetFields(MakeField("posts").SetArguments(ArgumentTime("since", at)))
+	strCh, err := q.StringChan()
+	assert.Nil(t, err)
+	assert.Equal(t, `query{posts(since:"2018-01-02T03:04:05Z")}`, StringFromChan(strCh))
+}
+
+func TestConfig_shapeCache(t *testing.T) {
+	c := NewShapeCache(4)
+	nano := NewConfig(WithDefaultTimeFormat(time.RFC3339Nano))
+	query := func(at time.Time) *Query {
+		return MakeQuery(TypeQuery, nano).SetFields(MakeField("posts").SetArguments(ArgumentTime("since", at)))
+	}
+	s, err := c.String(query(time.Date(2018, 1, 2, 3, 4, 5, 1, time.UTC)))
+	assert.Nil(t, err)
+	assert.Equal(t, `query{posts(since:"2018-01-02T03:04:05.000000001Z")}`, s)
+	s, err = c.String(query(time.Date(2018, 1, 2, 3, 4, 5, 2, time.UTC)))
+	assert.Nil(t, err)
+	assert.Equal(t, `query{posts(since:"2018-01-02T03:04:05.000000002Z")}`, s)
+}
+
+func TestConfig_timeLocation(t *testing.T) {
+	at := time.Date(2018, 1, 2, 3, 4, 5, 0, time.FixedZone("PST", -8*60*60))
+	build := func(config ...*Config) *Query {
+		return MakeQuery(TypeQuery, config...).SetFields(MakeField("posts").SetArguments(ArgumentTime("since", at)).SetFields(MakeField("id")))
+	}
+	strCh, err := build().StringChan()
+	assert.Nil(t, err)
+	assert.Equal(t, `query{posts(since:"2018-01-02T03:04:05-08:00"){id}}`, StringFromChan(strCh))
+
+	strCh, err = build(NewConfig(WithTimeLocation(time.UTC))).StringChan()
+	assert.Nil(t, err)
+	assert.Equal(t, `query{posts(since:"2018-01-02T11:04:05Z"){id}}`, StringFromChan(strCh))
+
+	c := NewShapeCache(4)
+	s, err := c.String(build(NewConfig()))
+	assert.Nil(t, err)
+	assert.Equal(t, `query{posts(since:"2018-01-02T03:04:05-08:00"){id}}`, s)
+	s, err = c.String(build(NewConfig(WithTimeLocation(time.UTC))))
+	assert.Nil(t, err)
+	assert.Equal(t, `query{posts(since:"2018-01-02T11:04:05Z"){id}}`, s)
+}
+
+func TestConfig_selectionCheck(t *testing.T) {
+	c := NewConfig(WithSelectionCheck())
+	_, err := MakeQuery(TypeQuery, c).StringChan()
+	assert.Equal(t, EmptySelectionErr{}, errors.Cause(err))
+	_, err = MakeQuery(TypeQuery).StringChan()
+	assert.Nil(t, err, "not checked by default")
+
+	_, err = MakeQuery(TypeQuery, c).SetFields(MakeField("user").SetFields(MakeField("... on User"))).StringChan()
+	assert.Equal(t, EmptySelectionErr{"... on User"}, errors.Cause(err))
+
+	r := NewFragmentRegistry()
+	assert.Nil(t, r.RegisterFragment("Nothing", "User"))
+	spread, err := r.Spread("Nothing")
+	assert.Nil(t, err)
+	_, err = MakeQuery(TypeQuery, c).SetFields(MakeField("user").SetFields(spread)).StringChan()
+	assert.Equal(t, EmptySelectionErr{"... on User"}, errors.Cause(err))
+
+	strCh, err := MakeQuery(TypeQuery, c).SetFields(MakeField("user").SetFields(MakeField("... on User").SetFields(MakeField("id")))).StringChan()
+	assert.Nil(t, err)
+	assert.Equal(t, `query{user{... on User{id}}}`, StringFromChan(strCh))
+}
+
+func TestConfig_nilFieldsSkipped(t *testing.T) {
+	var optional *Field
+	build := func(c ...*Config) *Query {
+		return MakeQuery(TypeQuery, c...).SetFields(optional, MakeField("user").SetFields(MakeField("id"), optional))
+	}
+
+	_, err := build().StringChan()
+	assert.Equal(t, NilFieldErr{}, errors.Cause(err))
+	_, err = MakeQuery(TypeQuery).SetFields(MakeField("user").SetFields(nil)).StringChan()
+	assert.Equal(t, NilFieldErr{}, errors.Cause(err), "nested nil fields are rejected by default")
+
+	c := NewConfig(WithNilFieldsSkipped())
+	strCh, err := build(c).StringChan()
+	assert.Nil(t, err)
+	assert.Equal(t, `query{user{id}}`, StringFromChan(strCh))
+	assert.Nil(t, build(c).Validate())
+
+	_, err = MakeQuery(TypeQuery, NewConfig(WithNilFieldsSkipped(), WithSelectionCheck())).SetFields(optional).StringChan()
+	assert.Equal(t, EmptySelectionErr{}, errors.Cause(err))
+}
@@ -0,0 +1,131 @@
+package graphb
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// PatchOperation is a change between two results, in the style of JSON Patch: https://tools.ietf.org/html/rfc6902
+// Op is "add", "remove" or "replace", Path a JSON Pointer, and Value the new value, nil for "remove".
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// DiffResults returns the operations turning the result prev into next, in the order they apply,
+// e.g. for a UI to update only what changed. Object members are compared by key, in key order,
+// and array elements by index, extra elements being removed from the end first.
+func DiffResults(prev, next json.RawMessage) ([]PatchOperation, error) {
+	a, err := decodeResult(prev)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	b, err := decodeResult(next)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var ops []PatchOperation
+	if err := diffValues("", a, b, &ops); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return ops, nil
+}
+
+// decodeResult decodes a JSON value keeping numbers exact.
+func decodeResult(raw json.RawMessage) (interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(raw))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return v, nil
+}
+
+func diffValues(path string, a, b interface{}, ops *[]PatchOperation) error {
+	switch x := a.(type) {
+	case map[string]interface{}:
+		if y, ok := b.(map[string]interface{}); ok {
+			return diffObjects(path, x, y, ops)
+		}
+	case []interface{}:
+		if y, ok := b.([]interface{}); ok {
+			return diffArrays(path, x, y, ops)
+		}
+	}
+	if reflect.DeepEqual(a, b) {
+		return nil
+	}
+	return addPatch(ops, "replace", path, b)
+}
+
+func diffObjects(path string, a, b map[string]interface{}, ops *[]PatchOperation) error {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		member := path + "/" + escapePointer(key)
+		x, inA := a[key]
+		y, inB := b[key]
+		var err error
+		switch {
+		case !inB:
+			*ops = append(*ops, PatchOperation{Op: "remove", Path: member})
+		case !inA:
+			err = addPatch(ops, "add", member, y)
+		default:
+			err = diffValues(member, x, y, ops)
+		}
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+func diffArrays(path string, a, b []interface{}, ops *[]PatchOperation) error {
+	for i := len(a) - 1; i >= len(b); i-- {
+		*ops = append(*ops, PatchOperation{Op: "remove", Path: path + "/" + strconv.Itoa(i)})
+	}
+	for i := range b {
+		element := path + "/" + strconv.Itoa(i)
+		var err error
+		if i < len(a) {
+			err = diffValues(element, a[i], b[i], ops)
+		} else {
+			err = addPatch(ops, "add", element, b[i])
+		}
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+func addPatch(ops *[]PatchOperation, op, path string, value interface{}) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	*ops = append(*ops, PatchOperation{Op: op, Path: path, Value: raw})
+	return nil
+}
+
+// escapePointer escapes a key as a reference token of a JSON Pointer: https://tools.ietf.org/html/rfc6901
+func escapePointer(key string) string {
+	return strings.Replace(strings.Replace(key, "~", "~0", -1), "/", "~1", -1)
+}
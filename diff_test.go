@@ -0,0 +1,39 @@
+package graphb
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffResults(t *testing.T) {
+	ops, err := DiffResults(
+		json.RawMessage(`{"user":{"name":"a","tags":["x","y","z"],"a/b":1,"score":1.50,"gone":null},"count":1}`),
+		json.RawMessage(`{"count":2,"user":{"name":"a","tags":["x","w"],"a/b":2,"score":1.50,"new":{"k":[]}}}`),
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, []PatchOperation{
+		{Op: "replace", Path: "/count", Value: json.RawMessage(`2`)},
+		{Op: "replace", Path: "/user/a~1b", Value: json.RawMessage(`2`)},
+		{Op: "remove", Path: "/user/gone"},
+		{Op: "add", Path: "/user/new", Value: json.RawMessage(`{"k":[]}`)},
+		{Op: "remove", Path: "/user/tags/2"},
+		{Op: "replace", Path: "/user/tags/1", Value: json.RawMessage(`"w"`)},
+	}, ops)
+
+	ops, err = DiffResults(json.RawMessage(`[1]`), json.RawMessage(`[1,{"a":true}]`))
+	assert.Nil(t, err)
+	assert.Equal(t, []PatchOperation{{Op: "add", Path: "/1", Value: json.RawMessage(`{"a":true}`)}}, ops)
+
+	ops, err = DiffResults(json.RawMessage(`{"a":1}`), json.RawMessage(`null`))
+	assert.Nil(t, err)
+	assert.Equal(t, []PatchOperation{{Op: "replace", Path: "", Value: json.RawMessage(`null`)}}, ops)
+
+	ops, err = DiffResults(json.RawMessage(`{"a":1}`), json.RawMessage(` { "a" : 1 } `))
+	assert.Nil(t, err)
+	assert.Empty(t, ops)
+
+	_, err = DiffResults(json.RawMessage(`{`), json.RawMessage(`{}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,89 @@
+package graphb
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Directive represents a GraphQL directive attached to a field or an inline fragment, e.g. `@include(if:true)`.
+// Name is the directive name without the leading '@'.
+type Directive struct {
+	Name      string
+	Arguments []Argument
+
+	impliesVariables bool // Whether the operation defines the variables it references as Boolean!, see IncludeIfVar.
+}
+
+func (d *Directive) tokenChan() <-chan Token {
+	tokenChan := make(chan Token)
+	go func() {
+		tokenChan <- Token{TokenDirective, directiveTokens.intern(d.Name)}
+		if args := emittedArguments(d.Arguments); len(args) > 0 {
+			tokenChan <- Token{TokenPunctuator, tokenLP}
+			for i := range args {
+				if i != 0 {
+					tokenChan <- Token{TokenPunctuator, tokenComma}
+				}
+				for tok := range args[i].tokenChan() {
+					tokenChan <- tok
+				}
+			}
+			tokenChan <- Token{TokenPunctuator, tokenRP}
+		}
+		close(tokenChan)
+	}()
+	return tokenChan
+}
+
+func (d *Directive) check(c *Config) error {
+	if !validName.MatchString(d.Name) {
+		return errors.WithStack(InvalidNameErr{directiveName, d.Name})
+	}
+	for _, arg := range d.Arguments {
+		if !validName.MatchString(arg.Name) {
+			return errors.WithStack(InvalidNameErr{argumentName, arg.Name})
+		}
+	}
+	if err := checkDuplicateArguments(d.Arguments); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := checkIntRange(d.Arguments, c); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(checkEnumValues(d.Arguments, c))
+}
+
+// MakeDirective constructs a Directive of the given name and arguments.
+func MakeDirective(name string, arguments ...Argument) Directive {
+	return Directive{Name: name, Arguments: arguments}
+}
+
+// AddDirectives adds directives to this Field and returns the pointer to this Field.
+func (f *Field) AddDirectives(directives ...Directive) *Field {
+	f.Directives = append(f.Directives, directives...)
+	f.touch()
+	return f
+}
+
+// IncludeIfVar adds `@include(if:$variable)` to this Field and returns the pointer to this Field.
+// The query of this Field defines the variable as `$variable:Boolean!` unless it defines the variable itself, see Query.AddVariables.
+func (f *Field) IncludeIfVar(variable string) *Field {
+	return f.AddDirectives(Directive{Name: "include", Arguments: []Argument{ArgumentVariable("if", variable)}, impliesVariables: true})
+}
+
+// SkipIfVar adds `@skip(if:$variable)` to this Field and returns the pointer to this Field, see IncludeIfVar.
+func (f *Field) SkipIfVar(variable string) *Field {
+	return f.AddDirectives(Directive{Name: "skip", Arguments: []Argument{ArgumentVariable("if", variable)}, impliesVariables: true})
+}
+
+// OfDirectives returns a FieldOption which adds directives to the targeting field.
+func OfDirectives(directives ...Directive) FieldOption {
+	return func(f *Field) error {
+		for i := range directives {
+			if err := directives[i].check(nil); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		f.Directives = append(f.Directives, directives...)
+		return nil
+	}
+}
@@ -0,0 +1,73 @@
+package graphb
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestField_AddDirectives(t *testing.T) {
+	q := MakeQuery(TypeQuery).
+		AddVariables(Variable{Name: "expanded", Type: "Boolean!"}).
+		SetFields(
+			MakeField("user").
+				SetArguments(ArgumentInt("id", 1)).
+				AddDirectives(MakeDirective("cached")).
+				SetFields(
+					MakeField("id"),
+					MakeField("...").
+						AddDirectives(MakeDirective("include", ArgumentVariable("if", "expanded"))).
+						SetFields(MakeField("bio")),
+				),
+		)
+	s, err := q.JSON()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"query":"query($expanded:Boolean!){user(id:1)@cached{id,...@include(if:$expanded){bio}}}"}`, s)
+}
+
+func TestOfDirectives(t *testing.T) {
+	f := NewField("user", OfDirectives(MakeDirective("cached")))
+	assert.Nil(t, f.E)
+	assert.Equal(t, []Directive{{Name: "cached"}}, f.Directives)
+
+	f = NewField("user", OfDirectives(MakeDirective("cache-d")))
+	assert.IsType(t, InvalidNameErr{}, errors.Cause(f.E))
+	assert.Equal(t, "'cache-d' is an invalid directive name in GraphQL: unexpected '-' (U+002D) at byte offset 5. A valid name matches /[_A-Za-z][_0-9A-Za-z]*/, see: http://facebook.github.io/graphql/October2016/#sec-Names", errors.Cause(f.E).Error())
+}
+
+func TestDirective_check(t *testing.T) {
+	f := MakeField("user").AddDirectives(MakeDirective("skip", ArgumentBool("i f", true)))
+	assert.IsType(t, InvalidNameErr{}, errors.Cause(f.check()))
+}
+
+func TestField_IncludeIfVar(t *testing.T) {
+	q := MakeQuery(TypeQuery).SetFields(
+		MakeField("user").SetFields(
+			MakeField("name"),
+			MakeField("posts").IncludeIfVar("withPosts").SetFields(MakeField("title").SkipIfVar("short")),
+			MakeField("friends").IncludeIfVar("withPosts"),
+		),
+	)
+	s, err := q.JSON()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"query":"query($withPosts:Boolean!,$short:Boolean!){user{name,posts@include(if:$withPosts){title@skip(if:$short)},friends@include(if:$withPosts)}}"}`, s)
+	assert.Empty(t, q.Variables, "the query is not modified")
+
+	// a variable defined by the query keeps its definition and value
+	q.AddVariables(Variable{Name: "short", Type: "Boolean", Value: true})
+	s, err = q.JSON()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"query":"query($short:Boolean,$withPosts:Boolean!){user{name,posts@include(if:$withPosts){title@skip(if:$short)},friends@include(if:$withPosts)}}","variables":{"short":true}}`, s)
+
+	// a directive built by hand does not define its variable
+	_, err = MakeQuery(TypeQuery).SetFields(MakeField("user").AddDirectives(MakeDirective("include", ArgumentVariable("if", "withUser")))).JSON()
+	assert.Equal(t, UndefinedVariableErr{"withUser"}, errors.Cause(err))
+
+	c := NewShapeCache(4)
+	s, err = c.String(MakeQuery(TypeQuery).SetFields(MakeField("user").IncludeIfVar("withUser")))
+	assert.Nil(t, err)
+	assert.Equal(t, `query($withUser:Boolean!){user@include(if:$withUser)}`, s)
+	_, err = c.String(MakeQuery(TypeQuery).SetFields(MakeField("user").AddDirectives(MakeDirective("include", ArgumentVariable("if", "withUser")))))
+	assert.Equal(t, UndefinedVariableErr{"withUser"}, errors.Cause(err))
+}
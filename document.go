@@ -0,0 +1,201 @@
+package graphb
+
+import (
+	"io/fs"
+
+	"github.com/pkg/errors"
+)
+
+// Document is a parsed executable GraphQL document, e.g. a .graphql file of operations and fragments.
+// Its operations are Query objects usable with the rest of the API. They keep their fragment spreads and are serialized
+// with the fragment definitions they use, see OfFragmentDefinitions.
+type Document struct {
+	Operations []*Query
+	Fragments  []*Fragment
+}
+
+// ParseDocument parses an executable GraphQL document.
+// Directives on variable definitions and fragment definitions are not supported.
+func ParseDocument(doc string) (*Document, error) {
+	return parseDocument(doc, "")
+}
+
+// LoadDocument parses the executable GraphQL document at path in fsys, e.g. a .graphql file embedded with go:embed.
+// The fields parsed record their location in the file, see Field.Location.
+func LoadDocument(fsys fs.FS, path string) (*Document, error) {
+	b, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	doc, err := parseDocument(string(b), path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s", path)
+	}
+	return doc, nil
+}
+
+func parseDocument(doc string, file string) (*Document, error) {
+	lexemes, err := lex(doc)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	p := &parser{lexemes: lexemes, file: file}
+	return p.parseDocument()
+}
+
+// Operation returns the operation of the given name, or the only operation of the document if name is empty.
+func (d *Document) Operation(name string) (*Query, bool) {
+	if name == "" {
+		if len(d.Operations) == 1 {
+			return d.Operations[0], true
+		}
+		return nil, false
+	}
+	for _, q := range d.Operations {
+		if q.Name == name {
+			return q, true
+		}
+	}
+	return nil, false
+}
+
+// Fragment returns the fragment of the given name.
+func (d *Document) Fragment(name string) (*Fragment, bool) {
+	for _, frag := range d.Fragments {
+		if frag.Name == name {
+			return frag, true
+		}
+	}
+	return nil, false
+}
+
+// Format returns this document serialized in style: its operations, followed by the definitions of its fragments
+// in document order, each once. It checks the document like Validate does and returns the first error found.
+func (d *Document) Format(style SerializerStyle) (string, error) {
+	var err error
+	d.validate(firstProblem(&err))
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	tokenChan := make(chan Token)
+	go func() {
+		for _, q := range d.Operations {
+			c := *q
+			c.FragmentDefinitions = true
+			fields, _ := c.fieldsAndFragments()
+			q.emitOperation(tokenChan, fields)
+		}
+		for _, frag := range d.Fragments {
+			fields, _ := spreadFragments(frag.Fields)
+			def := &fragmentDefinition{fragment: frag, fields: fields}
+			for tok := range def.tokenChan() {
+				tokenChan <- tok
+			}
+		}
+		close(tokenChan)
+	}()
+	return StringFromChan(literals(styleTokens(tokenChan, style))), nil
+}
+
+// validate reports the problems of the operations of this document, then the ones of the fragments nothing spreads,
+// and returns whether validation goes on.
+func (d *Document) validate(report reporter) bool {
+	spread := make(map[*Fragment]bool)
+	var visit func(fs []*Field)
+	visit = func(fs []*Field) {
+		for _, f := range fs {
+			if f == nil {
+				continue
+			}
+			if f.fragment != nil {
+				if spread[f.fragment] {
+					continue
+				}
+				spread[f.fragment] = true
+			}
+			visit(f.Fields)
+		}
+	}
+	for _, q := range d.Operations {
+		visit(q.Fields)
+	}
+	for _, frag := range d.Fragments {
+		visit(frag.Fields)
+	}
+	for _, q := range d.Operations {
+		if !q.validate(report) {
+			return false
+		}
+	}
+	for _, frag := range d.Fragments {
+		if spread[frag] {
+			continue
+		}
+		for _, f := range frag.Fields {
+			if f == nil {
+				if !report.check(NilFieldErr{}) {
+					return false
+				}
+				continue
+			}
+			if !f.validate(nil, report) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// resolve turns the fragment spreads of the document into the fields built by FragmentRegistry.Spread,
+// then checks that no fragment spreads itself, which would never end.
+func (d *Document) resolve(spreads []*Field) error {
+	fragments := make(map[string]*Fragment, len(d.Fragments))
+	for _, frag := range d.Fragments {
+		if fragments[frag.Name] != nil {
+			return errors.WithStack(DuplicateFragmentErr{frag.Name})
+		}
+		fragments[frag.Name] = frag
+	}
+	for _, f := range spreads {
+		frag, ok := fragments[f.fragment.Name]
+		if !ok {
+			return errors.WithStack(UndefinedFragmentErr{f.fragment.Name})
+		}
+		f.Name = "... on " + frag.TypeCondition
+		f.Fields = frag.Fields
+		f.fragment = frag
+	}
+
+	done := make(map[*Fragment]bool)
+	visiting := make(map[*Fragment]bool)
+	var visit func(fs []*Field) error
+	visit = func(fs []*Field) error {
+		for _, f := range fs {
+			if frag := f.fragment; frag != nil {
+				if visiting[frag] {
+					return errors.WithStack(CyclicFragmentErr{frag.Name})
+				}
+				if done[frag] {
+					continue
+				}
+				visiting[frag] = true
+				if err := visit(frag.Fields); err != nil {
+					return errors.WithStack(err)
+				}
+				visiting[frag] = false
+				done[frag] = true
+				continue
+			}
+			if err := visit(f.Fields); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		return nil
+	}
+	for _, frag := range d.Fragments {
+		if err := visit([]*Field{{fragment: frag}}); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
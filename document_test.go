@@ -0,0 +1,151 @@
+package graphb
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const userDocument = `# users.graphql
+query User($id: ID!, $size: Int = 64) {
+  user(id: $id) {
+    ...UserCard
+    avatar(size: $size, tags: ["a", $id], where: {min: -1.5, max: 2})
+    ... on Admin @include(if: true) { level }
+  }
+}
+
+mutation Rename($id: ID!, $name: String!) {
+  rename(input: {id: $id, name: $name, secret: "s3cr3t"}) { ...UserCard }
+}
+
+fragment UserCard on User {
+  id
+  displayName: name
+  note(format: MARKDOWN, text: """a "multi" line""")
+}
+`
+
+func TestLoadDocument(t *testing.T) {
+	fsys := fstest.MapFS{"queries/users.graphql": {Data: []byte(userDocument)}}
+	doc, err := LoadDocument(fsys, "queries/users.graphql")
+	assert.Nil(t, err)
+	assert.Len(t, doc.Operations, 2)
+	assert.Len(t, doc.Fragments, 1)
+
+	q, ok := doc.Operation("User")
+	assert.True(t, ok)
+	s, err := q.JSON()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"query":"query User($id:ID!,$size:Int=64){user(id:$id){...UserCard,avatar(size:$size,tags:[\"a\",$id],where:{min:-1.5,max:2}),... on Admin@include(if:true){level}}}fragment UserCard on User{id,displayName:name,note(format:MARKDOWN,text:\"\"\"a \"multi\" line\"\"\")}"}`, s)
+
+	// parsed queries are usable with the rest of the API
+	q.FragmentDefinitions = false
+	q.Variables[0].Value = "1"
+	s, err = q.JSON()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"query":"query User($id:ID!,$size:Int=64){user(id:$id){... on User{id,displayName:name,note(format:MARKDOWN,text:\"\"\"a \"multi\" line\"\"\")},avatar(size:$size,tags:[\"a\",$id],where:{min:-1.5,max:2}),... on Admin@include(if:true){level}}}","variables":{"id":"1"}}`, s)
+
+	rename, ok := doc.Operation("Rename")
+	assert.True(t, ok)
+	s, err = rename.StringRedacted("secret")
+	assert.Nil(t, err)
+	assert.Equal(t, `mutation Rename($id:ID!,$name:String!){rename(input:{id:$id,name:$name,secret:"<redacted>"}){...UserCard}}fragment UserCard on User{id,displayName:name,note(format:MARKDOWN,text:"""a "multi" line""")}`, s)
+
+	_, ok = doc.Operation("")
+	assert.False(t, ok)
+	assert.Equal(t, &SourceLocation{"queries/users.graphql", 4}, q.Fields[0].Fields[0].Location)
+
+	_, err = LoadDocument(fsys, "missing.graphql")
+	assert.NotNil(t, err)
+}
+
+func TestParseDocument(t *testing.T) {
+	doc, err := ParseDocument(`{ a(x: null, y: 12345678901234567890) b: c }`)
+	assert.Nil(t, err)
+	q, ok := doc.Operation("")
+	assert.True(t, ok)
+	strCh, err := q.StringChan()
+	assert.Nil(t, err)
+	assert.Equal(t, `query{a(x:null,y:12345678901234567890),b:c}`, StringFromChan(strCh))
+	assert.Nil(t, q.Fields[0].Location)
+}
+
+func TestParseDocument_errors(t *testing.T) {
+	cases := []struct {
+		doc string
+		err error
+	}{
+		{"{ ...A }", UndefinedFragmentErr{"A"}},
+		{"{ ...A } fragment A on T { b } fragment A on T { c }", DuplicateFragmentErr{"A"}},
+		{"{ ...A } fragment A on T { ...B } fragment B on T { c { ...A } }", CyclicFragmentErr{"A"}},
+		{"query Q($a: Int @deprecated) { a }", SyntaxErr{1, 17, "directives on variables are not supported by graphb"}},
+		{"{ a(x: 1", SyntaxErr{1, 9, "unexpected end of document, expected a Name"}},
+	}
+	for _, c := range cases {
+		_, err := ParseDocument(c.doc)
+		assert.Equal(t, c.err, errors.Cause(err), c.doc)
+	}
+}
+
+func TestDocument_Format(t *testing.T) {
+	doc, err := ParseDocument(`
+query User($id: ID!) { user(id: $id) { ...UserCard } }
+mutation Rename { rename(id: 1) { ...UserCard } }
+fragment UserCard on User { id, ...Avatar }
+fragment Avatar on User { avatar(size: 64) }
+fragment Unused on User { name }`)
+	assert.Nil(t, err)
+
+	s, err := doc.Format(StyleCompact)
+	assert.Nil(t, err)
+	assert.Equal(t, `query User($id:ID!){user(id:$id){...UserCard}}mutation Rename{rename(id:1){...UserCard}}fragment UserCard on User{id,...Avatar}fragment Avatar on User{avatar(size:64)}fragment Unused on User{name}`, s)
+
+	s, err = doc.Format(StylePretty)
+	assert.Nil(t, err)
+	assert.Equal(t, `query User($id: ID!) {
+  user(id: $id) {
+    ...UserCard
+  }
+}
+
+mutation Rename {
+  rename(id: 1) {
+    ...UserCard
+  }
+}
+
+fragment UserCard on User {
+  id
+  ...Avatar
+}
+
+fragment Avatar on User {
+  avatar(size: 64)
+}
+
+fragment Unused on User {
+  name
+}`, s)
+}
+
+func TestDocument_Validate(t *testing.T) {
+	doc, err := ParseDocument(`{ a { ...A } } fragment A on T { __a } fragment B on T { __b }`)
+	assert.Nil(t, err)
+
+	err = doc.Validate()
+	assert.IsType(t, ValidationErr{}, errors.Cause(err))
+	errs := errors.Cause(err).(ValidationErr).Errs(SeverityError)
+	assert.Len(t, errs, 2)
+	assert.Equal(t, ReservedNameErr{fieldName, "__a"}, errors.Cause(errs[0]))
+	assert.Equal(t, ReservedNameErr{fieldName, "__b"}, errors.Cause(errs[1]))
+
+	_, err = doc.Format(StyleCompact)
+	assert.Equal(t, ReservedNameErr{fieldName, "__a"}, errors.Cause(err))
+
+	doc, err = ParseDocument(`{ a }`)
+	assert.Nil(t, err)
+	assert.Nil(t, doc.Validate())
+}
@@ -0,0 +1,258 @@
+package graphb
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ValueEncryptor replaces sensitive String values before they are sent, e.g. tokenizes social security numbers into vault references.
+type ValueEncryptor interface {
+	// Encrypt returns the value sent instead of value, the value of the argument or input object field of the given name.
+	Encrypt(argument, value string) (string, error)
+}
+
+// ValueEncryptorFunc is a function implementing ValueEncryptor.
+type ValueEncryptorFunc func(argument, value string) (string, error)
+
+// Encrypt implements ValueEncryptor.
+func (f ValueEncryptorFunc) Encrypt(argument, value string) (string, error) {
+	return f(argument, value)
+}
+
+// EncryptionRule designates the arguments whose values Encryptor replaces: the arguments named by Arguments,
+// at any depth including input object fields, and the arguments classified with Classification
+// or a classification it prefixes, e.g. "pii" for "pii:ssn", see Field.ClassifyArgument.
+// Every String of the value of a designated argument is replaced, in lists and input objects too,
+// as well as the value of a variable it references.
+type EncryptionRule struct {
+	Encryptor      ValueEncryptor
+	Arguments      []string
+	Classification string
+}
+
+// designates reports whether r designates the argument of the given name, classified with classifications.
+func (r *EncryptionRule) designates(name string, classifications []string) bool {
+	for _, argument := range r.Arguments {
+		if argument == name {
+			return true
+		}
+	}
+	if r.Classification == "" {
+		return false
+	}
+	for _, c := range classifications {
+		if c == r.Classification || strings.HasPrefix(c, r.Classification+":") {
+			return true
+		}
+	}
+	return false
+}
+
+// WithEncryption returns a RequestOption which sends the query with the values designated by rules encrypted, see Query.Encrypted.
+func WithEncryption(rules ...EncryptionRule) RequestOption {
+	return func(o *requestOptions) {
+		o.encryption = append(o.encryption, rules...)
+	}
+}
+
+// Encrypted returns a copy of this Query whose argument values designated by rules are encrypted,
+// and so are the values of the variables they reference. The first rule designating an argument applies.
+// A designated value which is not a String, e.g. an Int, returns an UnencryptableValueErr.
+// The Query itself is not modified.
+func (q *Query) Encrypted(rules ...EncryptionRule) (*Query, error) {
+	// check first, copying a cyclic tree never ends
+	if err := q.checkCycles(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	e := &encrypter{rules: rules, variables: make(map[string]*EncryptionRule)}
+	encrypted := *q
+	fields, err := e.encryptFields(q.Fields)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	encrypted.Fields = fields
+	encrypted.Variables = make([]Variable, len(q.Variables))
+	for i, v := range q.Variables {
+		if r, ok := e.variables[v.Name]; ok {
+			if v.Value, err = encryptVariable(r, v); err != nil {
+				return nil, errors.WithStack(err)
+			}
+		}
+		encrypted.Variables[i] = v
+	}
+	return &encrypted, nil
+}
+
+// encrypter copies fields with the values designated by rules encrypted.
+type encrypter struct {
+	rules     []EncryptionRule
+	variables map[string]*EncryptionRule // The rules of the variables referenced by designated arguments.
+}
+
+func (e *encrypter) encryptFields(fs []*Field) ([]*Field, error) {
+	if fs == nil {
+		return nil, nil
+	}
+	encrypted := make([]*Field, len(fs))
+	for i, f := range fs {
+		if f == nil {
+			continue
+		}
+		copied := *f
+		copied.compiled = nil
+		var err error
+		if copied.Arguments, err = e.encryptArguments(f.Arguments, f.ArgumentClassifications); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if copied.Fields, err = e.encryptFields(f.Fields); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		encrypted[i] = &copied
+	}
+	return encrypted, nil
+}
+
+// encryptArguments returns a copy of args whose designated values are encrypted, classified by classifications if not nil.
+func (e *encrypter) encryptArguments(args []Argument, classifications map[string][]string) ([]Argument, error) {
+	if args == nil {
+		return nil, nil
+	}
+	encrypted := make([]Argument, len(args))
+	for i, arg := range args {
+		value, err := e.encryptValue(e.rule(arg.Name, classifications[arg.Name]), arg.Name, arg.Value)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		encrypted[i] = Argument{arg.Name, value}
+	}
+	return encrypted, nil
+}
+
+// rule returns the first rule designating the argument of the given name, nil if none does.
+func (e *encrypter) rule(name string, classifications []string) *EncryptionRule {
+	for i := range e.rules {
+		if e.rules[i].designates(name, classifications) {
+			return &e.rules[i]
+		}
+	}
+	return nil
+}
+
+// encryptValue returns value with its Strings encrypted by r, the rule of the argument of the given name,
+// or with the values of its input object fields designated by the rules encrypted if r is nil.
+func (e *encrypter) encryptValue(r *EncryptionRule, name string, value argumentValue) (argumentValue, error) {
+	switch v := value.(type) {
+	case argumentCustom:
+		args, err := e.encryptObject(r, v)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return argumentCustom(args), nil
+	case argArgSlice:
+		encrypted := make(argArgSlice, len(v))
+		for i, args := range v {
+			var err error
+			if encrypted[i], err = e.encryptObject(r, args); err != nil {
+				return nil, errors.WithStack(err)
+			}
+		}
+		return encrypted, nil
+	case argList:
+		encrypted := make(argList, len(v))
+		for i, value := range v {
+			var err error
+			if encrypted[i], err = e.encryptValue(r, name, value); err != nil {
+				return nil, errors.WithStack(err)
+			}
+		}
+		return encrypted, nil
+	case argDefaulted:
+		if v.isDefault() {
+			// not sent
+			return v, nil
+		}
+		encrypted, err := e.encryptValue(r, name, v.value)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return argDefaulted{encrypted, v.defaultValue}, nil
+	}
+	if r == nil {
+		return value, nil
+	}
+	switch v := value.(type) {
+	case argString:
+		return encryptString(r, name, string(v))
+	case argQuotedString:
+		return encryptString(r, name, string(v))
+	case argBlockString:
+		return encryptString(r, name, string(v))
+	case argStringSlice:
+		encrypted := make(argList, len(v))
+		for i, s := range v {
+			var err error
+			if encrypted[i], err = encryptString(r, name, s); err != nil {
+				return nil, errors.WithStack(err)
+			}
+		}
+		return encrypted, nil
+	case argVariable:
+		if _, ok := e.variables[string(v)]; !ok {
+			e.variables[string(v)] = r
+		}
+		return v, nil
+	case argTokens:
+		if len(v) == 1 && v[0].Literal == "null" {
+			return v, nil
+		}
+	}
+	return nil, errors.WithStack(UnencryptableValueErr{name})
+}
+
+// encryptObject returns a copy of the fields of an input object with their Strings encrypted by r,
+// or with the values of the fields designated by the rules encrypted if r is nil.
+func (e *encrypter) encryptObject(r *EncryptionRule, args []Argument) ([]Argument, error) {
+	if r == nil {
+		return e.encryptArguments(args, nil)
+	}
+	encrypted := make([]Argument, len(args))
+	for i, arg := range args {
+		value, err := e.encryptValue(r, arg.Name, arg.Value)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		encrypted[i] = Argument{arg.Name, value}
+	}
+	return encrypted, nil
+}
+
+func encryptString(r *EncryptionRule, name, s string) (argumentValue, error) {
+	encrypted, err := r.Encryptor.Encrypt(name, s)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	// escaped, whatever the encryptor returns
+	return argTokens{{TokenString, jsonString(encrypted)}}, nil
+}
+
+// encryptVariable returns the value of v encrypted by r, the rule of an argument referencing v.
+func encryptVariable(r *EncryptionRule, v Variable) (interface{}, error) {
+	switch value := v.Value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		encrypted, err := r.Encryptor.Encrypt(v.Name, value)
+		return encrypted, errors.WithStack(err)
+	case []string:
+		encrypted := make([]string, len(value))
+		for i, s := range value {
+			var err error
+			if encrypted[i], err = r.Encryptor.Encrypt(v.Name, s); err != nil {
+				return nil, errors.WithStack(err)
+			}
+		}
+		return encrypted, nil
+	}
+	return nil, errors.WithStack(UnencryptableValueErr{tokenDollar + v.Name})
+}
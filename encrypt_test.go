@@ -0,0 +1,77 @@
+package graphb
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// vault tokenizes values into references naming the argument.
+var vault = ValueEncryptorFunc(func(argument, value string) (string, error) {
+	return "vault:" + argument + ":" + strings.ToUpper(value), nil
+})
+
+func TestQuery_Encrypted(t *testing.T) {
+	q := MakeQuery(TypeMutation).
+		AddVariables(Variable{Name: "card", Type: "String!", Value: "4111"}).
+		SetFields(
+			MakeField("apply").SetArguments(
+				ArgumentString("ssn", "123-45"),
+				ArgumentString("name", "ann"),
+				ArgumentCustomType("applicant", ArgumentString("ssn", "678-90"), ArgumentInt("age", 30)),
+				ArgumentVariable("card", "card"),
+			).SetFields(MakeField("id")),
+			MakeField("verify").SetArguments(
+				ArgumentStringSlice("documents", "passport", "visa"),
+				ArgumentCustomType("address", ArgumentString("street", "main"), ArgumentString("zip", "1")),
+			).ClassifyArgument("address", "pii:address"),
+		)
+	rules := []EncryptionRule{
+		{Encryptor: vault, Arguments: []string{"ssn", "card", "documents"}},
+		{Encryptor: vault, Classification: "pii"},
+	}
+	encrypted, err := q.Encrypted(rules...)
+	assert.Nil(t, err)
+	s, err := encrypted.JSON()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"query":"mutation($card:String!){`+
+		`apply(ssn:\"vault:ssn:123-45\",name:\"ann\",applicant:{ssn:\"vault:ssn:678-90\",age:30},card:$card){id},`+
+		`verify(documents:[\"vault:documents:PASSPORT\",\"vault:documents:VISA\"],address:{street:\"vault:street:MAIN\",zip:\"vault:zip:1\"})}",`+
+		`"variables":{"card":"vault:card:4111"}}`, s)
+
+	// the Query itself is not modified
+	s, err = q.JSON()
+	assert.Nil(t, err)
+	assert.Contains(t, s, `ssn:\"123-45\"`)
+	assert.Equal(t, "4111", q.Variables[0].Value)
+
+	req, err := q.NewRequest(context.Background(), http.MethodPost, "https://example.com/graphql", WithEncryption(rules...))
+	assert.Nil(t, err)
+	body, _ := io.ReadAll(req.Body)
+	assert.Contains(t, string(body), `"variables":{"card":"vault:card:4111"}`)
+}
+
+func TestQuery_Encrypted_errors(t *testing.T) {
+	rule := EncryptionRule{Encryptor: vault, Arguments: []string{"age", "limit"}}
+	q := MakeQuery(TypeQuery).SetFields(MakeField("f").SetArguments(ArgumentCustomType("person", ArgumentInt("age", 30))))
+	_, err := q.Encrypted(rule)
+	assert.Equal(t, UnencryptableValueErr{"age"}, errors.Cause(err))
+
+	q = MakeQuery(TypeQuery).
+		AddVariables(Variable{Name: "limit", Type: "Int", Value: 10}).
+		SetFields(MakeField("f").SetArguments(ArgumentVariable("limit", "limit")))
+	_, err = q.Encrypted(rule)
+	assert.Equal(t, UnencryptableValueErr{"$limit"}, errors.Cause(err))
+	_, err = q.NewRequest(context.Background(), http.MethodPost, "https://example.com/graphql", WithEncryption(rule))
+	assert.Equal(t, UnencryptableValueErr{"$limit"}, errors.Cause(err))
+
+	failure := errors.New("vault unavailable")
+	failing := EncryptionRule{Encryptor: ValueEncryptorFunc(func(string, string) (string, error) { return "", failure }), Arguments: []string{"ssn"}}
+	_, err = MakeQuery(TypeQuery).SetFields(MakeField("f").SetArguments(ArgumentString("ssn", "1"))).Encrypted(failing)
+	assert.Equal(t, failure, errors.Cause(err))
+}
@@ -0,0 +1,80 @@
+package graphb
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// EnumValue returns s in upper snake case, the usual casing of enum values, e.g. "in-progress", "inProgress"
+// and "InProgress" all become "IN_PROGRESS", and "HTTPServer" becomes "HTTP_SERVER".
+// Only ASCII letters are converted, so that the result does not depend on any locale.
+func EnumValue(s string) string {
+	isLower := func(c byte) bool { return c >= 'a' && c <= 'z' }
+	isUpper := func(c byte) bool { return c >= 'A' && c <= 'Z' }
+
+	var b strings.Builder
+	pendingSeparator := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !isNameContinue(c) || c == '_' {
+			pendingSeparator = b.Len() > 0
+			continue
+		}
+		if i > 0 && isUpper(c) && b.Len() > 0 {
+			prev := s[i-1]
+			nextLower := i+1 < len(s) && isLower(s[i+1])
+			if isLower(prev) || isDigit(prev) || (isUpper(prev) && nextLower) {
+				pendingSeparator = true
+			}
+		}
+		if pendingSeparator {
+			b.WriteByte('_')
+			pendingSeparator = false
+		}
+		if isLower(c) {
+			c -= 'a' - 'A'
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// enumValue returns s as serialized following the conventions of c, which may be nil.
+func (c *Config) enumValue(s string) string {
+	if c != nil && c.NormalizeEnumValues {
+		return EnumValue(s)
+	}
+	return s
+}
+
+// checkEnumValues checks that the enum values of arguments, looking into input objects and lists,
+// are names other than true, false and null, which would read as other values, once normalized following c.
+func checkEnumValues(args []Argument, c *Config) error {
+	for _, arg := range args {
+		err := eachValue(arg.Value, func(value argumentValue) error {
+			switch v := value.(type) {
+			case argEnum:
+				return checkEnumValue(arg.Name, c.enumValue(string(v)))
+			case argEnumSlice:
+				for _, s := range v {
+					if err := checkEnumValue(arg.Name, c.enumValue(s)); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+func checkEnumValue(name string, value string) error {
+	if !validName.MatchString(value) || reservedWords[value] {
+		return InvalidEnumValueErr{name, value}
+	}
+	return nil
+}
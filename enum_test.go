@@ -0,0 +1,60 @@
+package graphb
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnumValue(t *testing.T) {
+	cases := map[string]string{
+		"IN_PROGRESS": "IN_PROGRESS",
+		"inProgress":  "IN_PROGRESS",
+		"InProgress":  "IN_PROGRESS",
+		"in-progress": "IN_PROGRESS",
+		"in progress": "IN_PROGRESS",
+		"_in__prog_":  "IN_PROG",
+		"HTTPServer":  "HTTP_SERVER",
+		"level2Check": "LEVEL2_CHECK",
+		"ASC":         "ASC",
+		"iñtl":        "I_TL",
+		"":            "",
+	}
+	for in, out := range cases {
+		assert.Equal(t, out, EnumValue(in), in)
+	}
+}
+
+func TestWithEnumNormalization(t *testing.T) {
+	build := func(config ...*Config) *Query {
+		return MakeQuery(TypeQuery, config...).SetFields(
+			MakeField("posts").SetArguments(ArgumentEnum("status", "in-progress"), ArgumentEnumSlice("sort", "newest", "mostLiked")),
+		)
+	}
+	strCh, err := build(NewConfig(WithEnumNormalization())).StringChan()
+	assert.Nil(t, err)
+	assert.Equal(t, "query{posts(status:IN_PROGRESS,sort:[NEWEST,MOST_LIKED])}", StringFromChan(strCh))
+
+	_, err = build().StringChan()
+	assert.Equal(t, InvalidEnumValueErr{"status", "in-progress"}, errors.Cause(err))
+}
+
+func TestCheckEnumValues(t *testing.T) {
+	assert.Nil(t, MakeField("a").SetArguments(ArgumentEnum("e", "TRUE"), ArgumentEnum("n", "Null")).check())
+
+	cases := []struct {
+		field *Field
+		err   InvalidEnumValueErr
+	}{
+		{MakeField("a").SetArguments(ArgumentEnum("e", "true")), InvalidEnumValueErr{"e", "true"}},
+		{MakeField("a").SetArguments(ArgumentEnumSlice("e", "A", "null")), InvalidEnumValueErr{"e", "null"}},
+		{MakeField("a").SetArguments(ArgumentCustomType("o", ArgumentEnum("e", "in progress"))), InvalidEnumValueErr{"o", "in progress"}},
+		{MakeField("a").SetArguments(ArgumentEnum("e", "")), InvalidEnumValueErr{"e", ""}},
+		{MakeField("a").AddDirectives(MakeDirective("d", ArgumentEnum("e", "false"))), InvalidEnumValueErr{"e", "false"}},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.err, errors.Cause(c.field.check()))
+	}
+	assert.Nil(t, MakeField("a").SetArguments(ArgumentEnum("e", "true")).checkWith(NewConfig(WithEnumNormalization())))
+}
@@ -0,0 +1,178 @@
+package graphb
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Equal reports whether a and b are the same query: everything serialized in the query text in the same order,
+// their Config(s), and the values of their variables. Headers are not compared.
+// A query containing a cycle is equal to no query.
+func Equal(a, b *Query) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	keyA, err := a.shapeKey()
+	if err != nil {
+		return false
+	}
+	keyB, err := b.shapeKey()
+	if err != nil {
+		return false
+	}
+	return keyA == keyB && reflect.DeepEqual(a.variableValues(), b.variableValues())
+}
+
+// SemanticallyEqual reports whether a and b send the same operation to a server, whatever the order of their fields,
+// arguments, input object fields and variable definitions, and whatever their white space, see SerializerStyle.
+// Fields are compared as serialized with their Config(s), e.g. with injected __typename(s), and values by their text.
+// The values of variables are compared too. Headers are not compared.
+// A query containing a cycle is semantically equal to no query.
+func SemanticallyEqual(a, b *Query) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	keyA, ok := a.semanticKey()
+	if !ok {
+		return false
+	}
+	keyB, ok := b.semanticKey()
+	if !ok {
+		return false
+	}
+	return keyA == keyB && reflect.DeepEqual(a.variableValues(), b.variableValues())
+}
+
+// semanticKey returns a key which is the same for the queries sending the same operation, and whether this Query has one.
+func (q *Query) semanticKey() (string, bool) {
+	if q.checkCycles() != nil {
+		return "", false
+	}
+	var b strings.Builder
+	writeKey(&b, string(q.Type))
+	writeKey(&b, q.Name)
+	for _, d := range q.Directives {
+		writeSemanticDirectiveKey(&b, d)
+	}
+	variables := append([]Variable(nil), q.variables()...)
+	sort.SliceStable(variables, func(i, j int) bool {
+		return variables[i].Name < variables[j].Name
+	})
+	for _, v := range variables {
+		b.WriteByte('$')
+		writeKey(&b, v.Name)
+		writeKey(&b, v.Type)
+		if v.defaultValue != nil {
+			b.WriteByte('=')
+			writeSemanticValueKey(&b, v.defaultValue)
+		}
+	}
+	for _, op := range q.OperationOptions {
+		b.WriteByte('O')
+		writeTokensKey(&b, op.Prefix())
+		writeTokensKey(&b, op.Suffix())
+	}
+	fields := q.Fields
+	if q.Config != nil {
+		fields = q.Config.configure(fields, true)
+	}
+	b.WriteString(semanticFieldsKey(fields))
+	return b.String(), true
+}
+
+// semanticFieldsKey returns the key of a selection set, whose fields are in any order.
+func semanticFieldsKey(fs []*Field) string {
+	keys := make([]string, len(fs))
+	for i, f := range fs {
+		keys[i] = semanticFieldKey(f)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteByte('{')
+	for _, key := range keys {
+		writeKey(&b, key)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func semanticFieldKey(f *Field) string {
+	if f == nil {
+		return "-"
+	}
+	var b strings.Builder
+	writeKey(&b, f.Alias)
+	writeKey(&b, f.Name)
+	writeSemanticArgumentsKey(&b, f.Arguments)
+	for _, d := range f.Directives {
+		writeSemanticDirectiveKey(&b, d)
+	}
+	b.WriteString(semanticFieldsKey(f.Fields))
+	return b.String()
+}
+
+func writeSemanticDirectiveKey(b *strings.Builder, d Directive) {
+	b.WriteByte('@')
+	writeKey(b, d.Name)
+	writeSemanticArgumentsKey(b, d.Arguments)
+}
+
+// writeSemanticArgumentsKey writes the key of the arguments emitted among args, in any order.
+func writeSemanticArgumentsKey(b *strings.Builder, args []Argument) {
+	b.WriteByte('(')
+	for _, arg := range sortedArguments(emittedArguments(args)) {
+		writeKey(b, arg.Name)
+		writeSemanticValueKey(b, arg.Value)
+	}
+	b.WriteByte(')')
+}
+
+// writeSemanticValueKey writes the text of value, with the fields of its input objects sorted.
+func writeSemanticValueKey(b *strings.Builder, value argumentValue) {
+	var tokens []Token
+	for tok := range canonicalValue(value).tokenChan() {
+		tokens = append(tokens, tok)
+	}
+	writeTokensKey(b, tokens)
+}
+
+// canonicalValue returns value with the fields of its input objects sorted by name, at any depth.
+func canonicalValue(value argumentValue) argumentValue {
+	switch v := value.(type) {
+	case argumentCustom:
+		return argumentCustom(canonicalArguments(v))
+	case argArgSlice:
+		sorted := make(argArgSlice, len(v))
+		for i, args := range v {
+			sorted[i] = canonicalArguments(args)
+		}
+		return sorted
+	case argList:
+		sorted := make(argList, len(v))
+		for i, elem := range v {
+			sorted[i] = canonicalValue(elem)
+		}
+		return sorted
+	case argDefaulted:
+		return argDefaulted{canonicalValue(v.value), v.defaultValue}
+	}
+	return value
+}
+
+func canonicalArguments(args []Argument) []Argument {
+	sorted := sortedArguments(args)
+	for i := range sorted {
+		sorted[i].Value = canonicalValue(sorted[i].Value)
+	}
+	return sorted
+}
+
+// sortedArguments returns a copy of args sorted by name.
+func sortedArguments(args []Argument) []Argument {
+	sorted := append([]Argument(nil), args...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
@@ -0,0 +1,74 @@
+package graphb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func withConfig(q *Query, c *Config) *Query {
+	q.Config = c
+	return q
+}
+
+func TestEqual(t *testing.T) {
+	assert.True(t, Equal(nil, nil))
+	assert.False(t, Equal(userQuery("1", "name"), nil))
+	assert.True(t, Equal(userQuery("1", "name", "email"), userQuery("1", "name", "email")))
+	assert.False(t, Equal(userQuery("1", "name", "email"), userQuery("1", "email", "name")))
+	assert.False(t, Equal(userQuery("1", "name"), userQuery("2", "name")))
+	assert.False(t, Equal(userQuery("1", "name"), withConfig(userQuery("1", "name"), NewConfig(WithTypenameInjection()))))
+
+	// headers are not compared
+	assert.True(t, Equal(userQuery("1", "name").AddHeader("Authorization", "Bearer t"), userQuery("1", "name")))
+
+	cyclic := MakeField("a")
+	cyclic.Fields = []*Field{cyclic}
+	q := MakeQuery(TypeQuery).SetFields(cyclic)
+	assert.False(t, Equal(q, q))
+}
+
+func TestSemanticallyEqual(t *testing.T) {
+	assert.True(t, SemanticallyEqual(nil, nil))
+	assert.False(t, SemanticallyEqual(nil, userQuery("1", "name")))
+	assert.True(t, SemanticallyEqual(userQuery("1", "name", "email"), userQuery("1", "email", "name")))
+	assert.False(t, SemanticallyEqual(userQuery("1", "name"), userQuery("1", "email")))
+	assert.False(t, SemanticallyEqual(userQuery("1", "name"), userQuery("2", "name")))
+
+	a := MakeQuery(TypeQuery).SetFields(
+		MakeField("users").SetArguments(
+			ArgumentInt("first", 10),
+			ArgumentCustomType("where", ArgumentString("name", "a"), ArgumentCustomType("age", ArgumentInt("gt", 1), ArgumentInt("lt", 9))),
+		).SetFields(MakeField("id"), MakeField("posts").SetFields(MakeField("title"), MakeField("id"))),
+	)
+	b := MakeQuery(TypeQuery).SetFields(
+		MakeField("users").SetArguments(
+			ArgumentCustomType("where", ArgumentCustomType("age", ArgumentInt("lt", 9), ArgumentInt("gt", 1)), ArgumentString("name", "a")),
+			ArgumentInt("first", 10),
+		).SetFields(MakeField("posts").SetFields(MakeField("id"), MakeField("title")), MakeField("id")),
+	)
+	assert.False(t, Equal(a, b))
+	assert.True(t, SemanticallyEqual(a, b))
+
+	// the order of list elements matters
+	assert.False(t, SemanticallyEqual(
+		MakeQuery(TypeQuery).SetFields(MakeField("f").SetArguments(ArgumentIntSlice("ids", 1, 2))),
+		MakeQuery(TypeQuery).SetFields(MakeField("f").SetArguments(ArgumentIntSlice("ids", 2, 1))),
+	))
+	// aliases matter
+	assert.False(t, SemanticallyEqual(
+		MakeQuery(TypeQuery).SetFields(MakeField("f").SetAlias("a")),
+		MakeQuery(TypeQuery).SetFields(MakeField("f").SetAlias("b")),
+	))
+
+	// compared as serialized with their Config
+	injected := withConfig(userQuery("1", "name"), NewConfig(WithTypenameInjection()))
+	assert.False(t, SemanticallyEqual(injected, userQuery("1", "name")))
+	assert.True(t, SemanticallyEqual(injected, userQuery("1", "__typename", "name")))
+	assert.True(t, SemanticallyEqual(withConfig(userQuery("1", "name"), NewConfig(WithSerializerStyle(StylePretty))), userQuery("1", "name")))
+
+	cyclic := MakeField("a")
+	cyclic.Fields = []*Field{cyclic}
+	q := MakeQuery(TypeQuery).SetFields(cyclic)
+	assert.False(t, SemanticallyEqual(q, q))
+}
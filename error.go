@@ -1,16 +1,25 @@
 package graphb
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
 )
 
 type nameType string
 
 const (
-	operationName nameType = "operation name"
-	aliasName     nameType = "alias name"
-	fieldName     nameType = "field name"
-	argumentName  nameType = "argument name"
+	operationName     nameType = "operation name"
+	aliasName         nameType = "alias name"
+	fieldName         nameType = "field name"
+	argumentName      nameType = "argument name"
+	variableName      nameType = "variable name"
+	directiveName     nameType = "directive name"
+	fragmentName      nameType = "fragment name"
+	typeConditionName nameType = "type condition"
 )
 
 // InvalidNameErr is returned when an invalid name is used. In GraphQL, operation, alias, field and argument all have names.
@@ -21,12 +30,32 @@ type InvalidNameErr struct {
 }
 
 func (e InvalidNameErr) Error() string {
-	return fmt.Sprintf("'%s' is an invalid %s in GraphQL. A valid name matches /[_A-Za-z][_0-9A-Za-z]*/, see: http://facebook.github.io/graphql/October2016/#sec-Names", e.Name, e.Type)
+	return fmt.Sprintf("'%s' is an invalid %s in GraphQL: %s. A valid name matches /[_A-Za-z][_0-9A-Za-z]*/, see: http://facebook.github.io/graphql/October2016/#sec-Names", e.Name, e.Type, e.reason())
+}
+
+// InvalidRune returns the first rune of the name breaking the Name grammar and its byte offset in the name.
+// The rune is utf8.RuneError for an empty name or a byte which is not valid UTF-8.
+func (e InvalidNameErr) InvalidRune() (rune, int) {
+	return invalidNameRune(e.Name)
+}
+
+func (e InvalidNameErr) reason() string {
+	r, offset := e.InvalidRune()
+	switch {
+	case e.Name == "":
+		return "it is empty"
+	case offset < 0:
+		return "it is valid" // only when InvalidNameErr is built by hand
+	case r == utf8.RuneError && !strings.HasPrefix(e.Name[offset:], string(utf8.RuneError)):
+		return fmt.Sprintf("invalid UTF-8 byte 0x%02x at byte offset %d", e.Name[offset], offset)
+	default:
+		return fmt.Sprintf("unexpected %q (%U) at byte offset %d", r, r, offset)
+	}
 }
 
 // InvalidOperationTypeErr is returned when the operation is not one of query, mutation and subscription.
 type InvalidOperationTypeErr struct {
-	Type operationType
+	Type OperationType
 }
 
 func (e InvalidOperationTypeErr) Error() string {
@@ -34,6 +63,7 @@ func (e InvalidOperationTypeErr) Error() string {
 }
 
 // NilFieldErr is returned when any field is nil. Of course the author could choose to ignore nil fields. But, author chose a stricter construct.
+// Nil fields are left out instead with WithNilFieldsSkipped.
 type NilFieldErr struct{}
 
 func (e NilFieldErr) Error() string {
@@ -50,10 +80,407 @@ func (e CyclicFieldErr) Error() string {
 }
 
 // ArgumentTypeNotSupportedErr is returned when user tries to pass an unsupported type to ArgumentAny.
+// Type and Kind describe the value for programmatic handling, and Suggestion tells how to pass such a value.
 type ArgumentTypeNotSupportedErr struct {
-	Value interface{}
+	Argument   string // The name of the argument, empty if there is none.
+	Value      interface{}
+	Type       reflect.Type // The type of Value, nil if Value is nil.
+	Kind       reflect.Kind // The kind of Type, reflect.Invalid if Value is nil.
+	Suggestion string
+}
+
+// argumentTypeNotSupported returns the ArgumentTypeNotSupportedErr of value for argument, suggesting a fix for its kind.
+func argumentTypeNotSupported(argument string, value interface{}) ArgumentTypeNotSupportedErr {
+	e := ArgumentTypeNotSupportedErr{Argument: argument, Value: value, Type: reflect.TypeOf(value)}
+	if e.Type != nil {
+		e.Kind = e.Type.Kind()
+	}
+	switch e.Kind {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.Suggestion = "convert it to int"
+	case reflect.Float32:
+		e.Suggestion = "convert it to float64"
+	case reflect.Struct:
+		e.Suggestion = "convert its fields with ArgumentsOf, or implement Valuer to register a scalar serializer"
+	case reflect.Slice, reflect.Array:
+		e.Suggestion = "convert it with ArgumentsOf, or use []interface{}"
+	case reflect.Ptr:
+		e.Suggestion = "dereference it"
+	case reflect.Complex64, reflect.Complex128, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		e.Suggestion = "implement Valuer or TokenProvider to register a scalar serializer"
+	default:
+		e.Suggestion = "use ArgumentJSON for a value encodable as JSON, or implement Valuer to register a scalar serializer"
+	}
+	return e
 }
 
 func (e ArgumentTypeNotSupportedErr) Error() string {
-	return fmt.Sprintf("Argument %+v of Type %T is not supported", e.Value, e.Value)
+	var argument string
+	if e.Argument != "" {
+		argument = fmt.Sprintf(" of argument '%s'", e.Argument)
+	}
+	return fmt.Sprintf("Value %+v%s of Type %T (kind %s) is not supported, %s", e.Value, argument, e.Value, e.Kind, e.Suggestion)
+}
+
+// MapKeyNotSupportedErr is returned when a key of a map converted into an input object can not name a field,
+// e.g. an integer, since field names can not start with a digit.
+type MapKeyNotSupportedErr struct {
+	Argument string
+	Key      interface{}
+}
+
+func (e MapKeyNotSupportedErr) Error() string {
+	return fmt.Sprintf("key %v of type %T in the map of argument '%s' can not name an input object field, use string keys or keys with a String method", e.Key, e.Key, e.Argument)
+}
+
+// InvalidTypeReferenceErr is returned when a variable is defined with a malformed type reference.
+type InvalidTypeReferenceErr struct {
+	Variable string
+	Type     string
+}
+
+func (e InvalidTypeReferenceErr) Error() string {
+	return fmt.Sprintf("'%s' is an invalid type reference of variable '$%s' in GraphQL, see: http://facebook.github.io/graphql/October2016/#sec-Type-References", e.Type, e.Variable)
+}
+
+// DuplicateVariableErr is returned when an operation defines the same variable more than once.
+type DuplicateVariableErr struct {
+	Name string
+}
+
+func (e DuplicateVariableErr) Error() string {
+	return fmt.Sprintf("variable '$%s' is defined more than once", e.Name)
+}
+
+// DuplicateArgumentErr is returned when a field, a directive or an input object has the same argument more than once,
+// see MergeArguments.
+type DuplicateArgumentErr struct {
+	Name string
+}
+
+func (e DuplicateArgumentErr) Error() string {
+	return fmt.Sprintf("argument '%s' is given more than once", e.Name)
+}
+
+// UndefinedVariableErr is returned when an argument references a variable which is not defined by the operation.
+type UndefinedVariableErr struct {
+	Name string
+}
+
+func (e UndefinedVariableErr) Error() string {
+	return fmt.Sprintf("variable '$%s' is used but not defined by the operation, see: http://facebook.github.io/graphql/October2016/#sec-All-Variable-Uses-Defined", e.Name)
+}
+
+// UnusedVariableErr is returned when the operation defines a variable which no argument references.
+type UnusedVariableErr struct {
+	Name string
+}
+
+func (e UnusedVariableErr) Error() string {
+	return fmt.Sprintf("variable '$%s' is defined but never used, see: http://facebook.github.io/graphql/October2016/#sec-All-Variables-Used", e.Name)
+}
+
+// UnexpectedContentTypeErr is returned when a response body can not be decoded because of its content type.
+type UnexpectedContentTypeErr struct {
+	ContentType string
+}
+
+func (e UnexpectedContentTypeErr) Error() string {
+	return fmt.Sprintf("unexpected content type '%s'", e.ContentType)
+}
+
+// PathNotFoundErr is returned when a path of response keys leads nowhere, in a response or in a Query.
+type PathNotFoundErr struct {
+	Path string
+}
+
+func (e PathNotFoundErr) Error() string {
+	return fmt.Sprintf("path '%s' not found", e.Path)
+}
+
+// DuplicateFragmentErr is returned when a fragment of the same name is registered more than once.
+type DuplicateFragmentErr struct {
+	Name string
+}
+
+func (e DuplicateFragmentErr) Error() string {
+	return fmt.Sprintf("fragment '%s' is registered more than once", e.Name)
+}
+
+// UndefinedFragmentErr is returned when spreading a fragment which is not registered.
+type UndefinedFragmentErr struct {
+	Name string
+}
+
+func (e UndefinedFragmentErr) Error() string {
+	return fmt.Sprintf("fragment '%s' is not registered", e.Name)
+}
+
+// OpenAPISchemaNotSupportedErr is returned when an OpenAPI parameter schema has no GraphQL counterpart.
+type OpenAPISchemaNotSupportedErr struct {
+	Parameter string
+	Type      string
+}
+
+func (e OpenAPISchemaNotSupportedErr) Error() string {
+	return fmt.Sprintf("schema type '%s' of parameter '%s' is not supported", e.Type, e.Parameter)
+}
+
+// ReservedNameErr is returned when a name is reserved by GraphQL, either for introspection or as a value,
+// see WithReservedNames.
+type ReservedNameErr struct {
+	Type nameType
+	Name string
+}
+
+func (e ReservedNameErr) Error() string {
+	return fmt.Sprintf("'%s' is a reserved %s in GraphQL, see: http://facebook.github.io/graphql/October2016/#sec-Reserved-Names", e.Name, e.Type)
+}
+
+// InvalidJSONValueErr is returned when the JSON value of an argument is malformed or can not be converted to GraphQL.
+type InvalidJSONValueErr struct {
+	Argument string
+	Reason   string
+}
+
+func (e InvalidJSONValueErr) Error() string {
+	return fmt.Sprintf("invalid JSON value of argument '%s': %s", e.Argument, e.Reason)
+}
+
+// InvalidTokensErr is returned when the tokens of a TokenProvider do not make a single GraphQL value, see ArgumentTokens.
+type InvalidTokensErr struct {
+	Argument string
+	Reason   string
+}
+
+func (e InvalidTokensErr) Error() string {
+	return fmt.Sprintf("invalid tokens of argument '%s': %s", e.Argument, e.Reason)
+}
+
+// SyntaxErr is returned when a GraphQL document does not follow the grammar of the spec.
+// Line and Column are 1-based, Column counts bytes.
+type SyntaxErr struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e SyntaxErr) Error() string {
+	return fmt.Sprintf("syntax error at %d:%d: %s", e.Line, e.Column, e.Message)
+}
+
+// CyclicFragmentErr is returned when a fragment spreads itself, directly or through other fragments.
+type CyclicFragmentErr struct {
+	Name string
+}
+
+func (e CyclicFragmentErr) Error() string {
+	return fmt.Sprintf("fragment '%s' spreads itself", e.Name)
+}
+
+// IntOutOfRangeErr is returned when an Int value does not fit in 32 bits and WithIntRangeCheck is on.
+type IntOutOfRangeErr struct {
+	Argument string
+	Value    int
+}
+
+func (e IntOutOfRangeErr) Error() string {
+	return fmt.Sprintf("value %d of argument '%s' is out of the 32-bit range of Int, see ArgumentLong", e.Value, e.Argument)
+}
+
+// InvalidFloatErr is returned when a Float argument is NaN or infinite, which have no GraphQL literal.
+type InvalidFloatErr struct {
+	Argument string
+	Value    float64
+}
+
+func (e InvalidFloatErr) Error() string {
+	return fmt.Sprintf("value %v of argument '%s' is not a finite number, which GraphQL Float literals can not represent", e.Value, e.Argument)
+}
+
+// InvalidEnumValueErr is returned when an enum value is not a name, or is true, false or null, which read as other values,
+// see: http://facebook.github.io/graphql/October2016/#sec-Enum-Value
+type InvalidEnumValueErr struct {
+	Argument string
+	Value    string
+}
+
+func (e InvalidEnumValueErr) Error() string {
+	return fmt.Sprintf("'%s' is an invalid enum value of argument '%s'. A valid enum value is a name other than true, false and null", e.Value, e.Argument)
+}
+
+// CircuitOpenErr is returned when a circuit breaker rejects a call, see WithCircuitBreaker.
+type CircuitOpenErr struct{}
+
+func (e CircuitOpenErr) Error() string {
+	return "circuit breaker is open, the call was not sent"
+}
+
+// UnsupportedBuilderValueErr is returned by GenerateBuilderCode for a value no Argument function builds,
+// e.g. a list of lists of enum values.
+type UnsupportedBuilderValueErr struct {
+	Argument string
+}
+
+func (e UnsupportedBuilderValueErr) Error() string {
+	return fmt.Sprintf("the value of argument '%s' can not be built with the Argument functions", e.Argument)
+}
+
+// DuplicateOperationErr is returned when operations of the same name are persisted together, see QueryRegistry.
+type DuplicateOperationErr struct {
+	Name string
+}
+
+func (e DuplicateOperationErr) Error() string {
+	return fmt.Sprintf("operation '%s' is registered more than once", e.Name)
+}
+
+// OperationNotPersistedErr is returned when an operation is not in the persisted queries manifest of a client, see WithManifest.
+type OperationNotPersistedErr struct {
+	Name string
+	ID   string
+}
+
+func (e OperationNotPersistedErr) Error() string {
+	return fmt.Sprintf("operation '%s' of hash %s is not in the persisted queries manifest", e.Name, e.ID)
+}
+
+// UnexpectedStatusErr is returned when a server answers a request with an unexpected HTTP status.
+type UnexpectedStatusErr struct {
+	Status int
+}
+
+func (e UnexpectedStatusErr) Error() string {
+	return fmt.Sprintf("unexpected HTTP status %d", e.Status)
+}
+
+// UintOverflowErr is returned when an unsigned integer value does not fit in an int, see ArgumentsOf.
+type UintOverflowErr struct {
+	Argument string
+	Value    uint64
+}
+
+func (e UintOverflowErr) Error() string {
+	return fmt.Sprintf("value %d of argument '%s' overflows int", e.Value, e.Argument)
+}
+
+// CursorNotAdvancedErr is returned when a page has a next page but its end cursor is empty or the one of the previous page,
+// which would fetch the same page forever, see Paginate.
+type CursorNotAdvancedErr struct {
+	Path   string
+	Cursor string
+}
+
+func (e CursorNotAdvancedErr) Error() string {
+	return fmt.Sprintf("the end cursor '%s' of connection '%s' does not advance", e.Cursor, e.Path)
+}
+
+// SubscriptionOverflowErr ends a subscription whose consumer let its buffer of results fill up, see OverflowError.
+type SubscriptionOverflowErr struct {
+	Buffer int
+}
+
+func (e SubscriptionOverflowErr) Error() string {
+	return fmt.Sprintf("the consumer of the subscription is too slow, its buffer of %d results is full", e.Buffer)
+}
+
+// MultipartSubscriptionErr ends a subscription over multipart HTTP whose server reported errors of the transport,
+// e.g. the loss of the connection to a subgraph, see MultipartClient.
+type MultipartSubscriptionErr struct {
+	Errors json.RawMessage
+}
+
+func (e MultipartSubscriptionErr) Error() string {
+	return fmt.Sprintf("the server ended the subscription: %s", e.Errors)
+}
+
+// GraphQLErrorsErr is returned along with the data of a response carrying GraphQL errors, see ExecuteWith.
+type GraphQLErrorsErr struct {
+	Errors json.RawMessage
+}
+
+func (e GraphQLErrorsErr) Error() string {
+	return fmt.Sprintf("the response has errors: %s", e.Errors)
+}
+
+// EmptySelectionErr is returned when a selection set would be serialized without any field, see WithSelectionCheck.
+// Field is the name of the field or fragment selecting nothing, empty for an operation.
+type EmptySelectionErr struct {
+	Field string
+}
+
+func (e EmptySelectionErr) Error() string {
+	if e.Field == "" {
+		return "the operation selects no field"
+	}
+	return fmt.Sprintf("'%s' has an empty selection set", e.Field)
+}
+
+// ErrQueryTooLarge is returned by StringMax when a query is serialized larger than the limit.
+var ErrQueryTooLarge = errors.New("the query is larger than the limit")
+
+// UnencryptableValueErr is returned when an EncryptionRule designates a value which is not a String, e.g. an Int.
+// Argument is the name of the argument or input object field, or of the variable prefixed by '$'.
+type UnencryptableValueErr struct {
+	Argument string
+}
+
+func (e UnencryptableValueErr) Error() string {
+	return fmt.Sprintf("the value of '%s' is not a String and can not be encrypted", e.Argument)
+}
+
+// SharedFieldErr is returned when a *Field is at several positions of a query, see WithSharedFieldCheck.
+type SharedFieldErr struct {
+	Field string
+}
+
+func (e SharedFieldErr) Error() string {
+	return fmt.Sprintf("Field '%s' is at several positions of the query, a change to one of them changes all of them", e.Field)
+}
+
+// ValidationErr lists every problem found by Validate, in the order they are found.
+type ValidationErr struct {
+	Problems []Problem
+}
+
+func (e ValidationErr) Error() string {
+	messages := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		messages[i] = p.Severity.String() + ": " + p.Err.Error()
+	}
+	return fmt.Sprintf("%d validation problem(s): %s", len(e.Problems), strings.Join(messages, "; "))
+}
+
+// Errs returns the errors of the problems of the given severity.
+func (e ValidationErr) Errs(severity Severity) []error {
+	var errs []error
+	for _, p := range e.Problems {
+		if p.Severity == severity {
+			errs = append(errs, p.Err)
+		}
+	}
+	return errs
+}
+
+// NotRecordedErr is returned by ReplayTransport for a request which was not recorded, Path being the recording it looked for.
+type NotRecordedErr struct {
+	OperationName string
+	Path          string
+}
+
+func (e NotRecordedErr) Error() string {
+	if e.OperationName == "" {
+		return fmt.Sprintf("no recorded response at %s", e.Path)
+	}
+	return fmt.Sprintf("no recorded response to operation '%s' at %s", e.OperationName, e.Path)
+}
+
+// NoConnectionErr is returned by PageIterator.Next when the path to pageInfo given to Paginate has no connection field before pageInfo.
+type NoConnectionErr struct {
+	Path string
+}
+
+func (e NoConnectionErr) Error() string {
+	return fmt.Sprintf("path '%s' has no connection field before pageInfo", e.Path)
 }
@@ -0,0 +1,22 @@
+package graphb
+
+// BuildEntitiesQuery builds the query a federation gateway sends to a subgraph to resolve entities:
+//
+//	query($representations:[_Any!]!){_entities(representations:$representations){... on User{id,name}}}
+//
+// Each representation carries the "__typename" and the key fields of an entity.
+// The selection usually consists of inline fragments, one per entity type, e.g. MakeField("... on User").SetFields(...).
+func BuildEntitiesQuery(representations []map[string]interface{}, selection ...*Field) *Query {
+	return MakeQuery(TypeQuery).
+		AddVariables(Variable{Name: "representations", Type: "[_Any!]!", Value: representations}).
+		SetFields(
+			MakeField("_entities").
+				SetArguments(ArgumentVariable("representations", "representations")).
+				SetFields(selection...),
+		)
+}
+
+// BuildServiceQuery builds the query a federation gateway sends to a subgraph to fetch its schema: query{_service{sdl}}
+func BuildServiceQuery() *Query {
+	return MakeQuery(TypeQuery).SetFields(MakeField("_service").SetFields(MakeField("sdl")))
+}
@@ -0,0 +1,27 @@
+package graphb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildEntitiesQuery(t *testing.T) {
+	q := BuildEntitiesQuery(
+		[]map[string]interface{}{
+			{"__typename": "User", "id": "1"},
+			{"__typename": "Product", "upc": "2"},
+		},
+		MakeField("... on User").SetFields(MakeField("name")),
+		MakeField("... on Product").SetFields(MakeField("price")),
+	)
+	s, err := q.JSON()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"query":"query($representations:[_Any!]!){_entities(representations:$representations){... on User{name},... on Product{price}}}","variables":{"representations":[{"__typename":"User","id":"1"},{"__typename":"Product","upc":"2"}]}}`, s)
+}
+
+func TestBuildServiceQuery(t *testing.T) {
+	s, err := BuildServiceQuery().JSON()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"query":"query{_service{sdl}}"}`, s)
+}
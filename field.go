@@ -6,11 +6,27 @@ import (
 
 // Field is a recursive data struct which represents a GraphQL query field.
 type Field struct {
-	Name      string
-	Alias     string
-	Arguments []Argument
-	Fields    []*Field
-	E         error
+	Name       string
+	Alias      string
+	Arguments  []Argument
+	Directives []Directive
+	Fields     []*Field
+	E          error
+	Scopes     []string // Authorization scopes required to request this field, see Query.StripUnauthorized.
+
+	Classifications         []string            // Data classifications such as "pii:email", see Query.PIIReport.
+	ArgumentClassifications map[string][]string // Data classifications of the arguments by name, see Field.ClassifyArgument.
+
+	// Where the field was built and its arguments last set, recorded only for fields built with TrackLocation or OfLocation.
+	Location          *SourceLocation
+	ArgumentsLocation *SourceLocation
+
+	tracked  bool    // Whether the argument setters record where they are called from, see TrackLocation.
+	compiled []Token // The frozen tokens of this Field, see Compile.
+	fragment *Fragment // The registered fragment this Field spreads, see FragmentRegistry.Spread.
+
+	generation uint64   // Renewed by the setters of this Field, see Query.String.
+	segment    *segment // The cached serialization of this Field, see Query.String.
 }
 
 // Implement fieldContainer
@@ -20,6 +36,7 @@ func (f *Field) getFields() []*Field {
 
 func (f *Field) setFields(fs []*Field) {
 	f.Fields = fs
+	f.touch()
 }
 
 // StringChan returns read only string token channel or an error.
@@ -39,82 +56,174 @@ func (f *Field) StringChan() (<-chan string, error) {
 // The different being the public method checks the validity of the Field structure
 // while the private counterpart assumes the validity.
 func (f *Field) stringChan() <-chan string {
+	return literals(f.tokenChan())
+}
 
-	tokenChan := make(chan string)
+// tokenChan emits the tokens of this Field, assuming the validity of the Field structure.
+func (f *Field) tokenChan() <-chan Token {
+	if f.compiled != nil {
+		return f.compiledTokenChan()
+	}
+
+	tokenChan := make(chan Token)
 
 	go func() {
-		// emit alias and names
-		if f.Alias != "" {
-			tokenChan <- f.Alias
-			tokenChan <- tokenColumn
-		}
-		tokenChan <- f.Name
-
-		// emit argument tokens
-		if len(f.Arguments) > 0 {
-			tokenChan <- tokenLP
-			for i := range f.Arguments {
-				if i != 0 {
-					tokenChan <- tokenComma
-				}
-				for str := range f.Arguments[i].stringChan() {
-					tokenChan <- str
-				}
-			}
-			tokenChan <- tokenRP
-		}
+		f.emitHead(tokenChan, f.Arguments)
 
 		// emit field tokens
 		if len(f.Fields) > 0 {
-			tokenChan <- tokenLB
+			tokenChan <- Token{TokenPunctuator, tokenLB}
 			for i, field := range f.Fields {
 				if field != nil {
 					if i != 0 {
-						tokenChan <- tokenComma
+						tokenChan <- Token{TokenPunctuator, tokenComma}
 					}
-					for str := range field.stringChan() {
-						tokenChan <- str
+					for tok := range field.tokenChan() {
+						tokenChan <- tok
 					}
 				}
 			}
-			tokenChan <- tokenRB
+			tokenChan <- Token{TokenPunctuator, tokenRB}
 		}
 		close(tokenChan)
 	}()
 	return tokenChan
 }
 
+// emitHead emits the tokens of this Field preceding its selection set, with the given arguments.
+func (f *Field) emitHead(tokenChan chan<- Token, arguments []Argument) {
+	// emit alias and names
+	if f.Alias != "" {
+		tokenChan <- Token{TokenName, f.Alias}
+		tokenChan <- Token{TokenPunctuator, tokenColumn}
+	}
+	tokenChan <- Token{TokenName, f.Name}
+
+	// emit argument tokens
+	if args := emittedArguments(arguments); len(args) > 0 {
+		tokenChan <- Token{TokenPunctuator, tokenLP}
+		for i := range args {
+			if i != 0 {
+				tokenChan <- Token{TokenPunctuator, tokenComma}
+			}
+			for tok := range args[i].tokenChan() {
+				tokenChan <- tok
+			}
+		}
+		tokenChan <- Token{TokenPunctuator, tokenRP}
+	}
+
+	// emit directive tokens
+	for i := range f.Directives {
+		for tok := range f.Directives[i].tokenChan() {
+			tokenChan <- tok
+		}
+	}
+}
+
 func (f *Field) check() error {
+	return f.checkWith(nil)
+}
+
+// checkWith checks the validity of this Field following the validation options of c, which may be nil.
+func (f *Field) checkWith(c *Config) (err error) {
+	f.validate(c, firstProblem(&err))
+	return err
+}
+
+// validate reports the problems of this Field and its sub fields to report, and returns whether validation goes on.
+// The sub fields of a Field reaching itself are not walked.
+func (f *Field) validate(c *Config, report reporter) bool {
 	if err := f.checkCycle(); err != nil {
-		return errors.WithStack(err)
+		return report.check(err)
 	}
-	if err := f.checkOther(); err != nil {
-		return errors.WithStack(err)
+	return f.validateTree(c, report)
+}
+
+func (f *Field) validateTree(c *Config, report reporter) bool {
+	located := report
+	located.report = func(p Problem) bool {
+		p.Err = f.located(p.Err)
+		return report.report(p)
 	}
-	return nil
+	if !f.validateSelf(c, located) {
+		return false
+	}
+	for _, subF := range f.Fields {
+		if subF == nil {
+			if !c.skipsNilFields() && !located.check(NilFieldErr{}) {
+				return false
+			}
+			continue
+		}
+		if !subF.validateTree(c, report) {
+			return false
+		}
+	}
+	return true
 }
 
-// checkOther checks the validity of this Field and returns nil on valid Field.
-func (f *Field) checkOther() error {
+// validateSelf reports the problems of this Field without its sub fields.
+func (f *Field) validateSelf(c *Config, report reporter) bool {
 	// Check validity of names
-	if !validName.MatchString(f.Name) && !validInlineFragment.MatchString(f.Name){
-		return errors.WithStack(InvalidNameErr{fieldName, f.Name})
+	if !validName.MatchString(f.Name) && !validInlineFragment.MatchString(f.Name) && !report.check(InvalidNameErr{fieldName, f.Name}) {
+		return false
 	}
-	if err := f.checkAlias(); err != nil {
-		return errors.WithStack(err)
+	if !report.check(f.checkAlias()) {
+		return false
 	}
 	for _, arg := range f.Arguments {
-		if !validName.MatchString(arg.Name) {
-			return errors.WithStack(InvalidNameErr{argumentName, arg.Name})
+		if !validName.MatchString(arg.Name) && !report.check(InvalidNameErr{argumentName, arg.Name}) {
+			return false
 		}
 	}
-
-	// Check sub fields
-	for _, subF := range f.Fields {
-		if err := subF.checkOther(); err != nil {
-			return errors.WithStack(err)
+	if !report.check(checkDuplicateArguments(f.Arguments)) ||
+		!report.check(f.checkReservedNames(c)) ||
+		!report.check(checkIntRange(f.Arguments, c)) ||
+		!report.check(checkEnumValues(f.Arguments, c)) {
+		return false
+	}
+	for i := range f.Directives {
+		if !report.check(f.Directives[i].check(c)) {
+			return false
 		}
 	}
+	if !report.check(f.checkSelection(c)) {
+		return false
+	}
+	if !report.warnings {
+		return true
+	}
+	// the checks turned off by c
+	strict := c.strict()
+	if c != nil && c.AllowReservedNames && !report.warn(f.checkReservedNames(strict)) {
+		return false
+	}
+	if c == nil || !c.CheckIntRange {
+		if !report.warn(checkIntRange(f.Arguments, strict)) {
+			return false
+		}
+		for i := range f.Directives {
+			if !report.warn(checkIntRange(f.Directives[i].Arguments, strict)) {
+				return false
+			}
+		}
+	}
+	if c == nil || !c.CheckSelections {
+		return report.warn(f.checkSelection(strict))
+	}
+	return true
+}
+
+// checkSelection checks that this Field selects a field if it is a fragment, whose selection set is never omitted,
+// when c checks selections.
+func (f *Field) checkSelection(c *Config) error {
+	if c == nil || !c.CheckSelections {
+		return nil
+	}
+	if (f.fragment != nil || validInlineFragment.MatchString(f.Name)) && len(f.Fields) == 0 {
+		return errors.WithStack(EmptySelectionErr{f.Name})
+	}
 	return nil
 }
 
@@ -145,23 +254,97 @@ func MakeField(name string) *Field {
 // SetArguments sets the arguments of a Field and return the pointer to this Field.
 func (f *Field) SetArguments(arguments ...Argument) *Field {
 	f.Arguments = arguments
+	f.locateArguments()
+	f.touch()
 	return f
 }
 
 func (f *Field) AddArguments(argument ...Argument) *Field {
 	f.Arguments = append(f.Arguments, argument...)
+	f.locateArguments()
+	f.touch()
+	return f
+}
+
+// GetArgument returns the argument of the given name and whether this Field has it.
+func (f *Field) GetArgument(name string) (Argument, bool) {
+	for _, arg := range f.Arguments {
+		if arg.Name == name {
+			return arg, true
+		}
+	}
+	return Argument{}, false
+}
+
+// RemoveArgument removes the argument(s) of the given name and return the pointer to this Field.
+func (f *Field) RemoveArgument(name string) *Field {
+	var args []Argument
+	for _, arg := range f.Arguments {
+		if arg.Name != name {
+			args = append(args, arg)
+		}
+	}
+	f.Arguments = args
+	f.touch()
+	return f
+}
+
+// ReplaceArgument replaces the argument of the same name in place, or adds it if there is none,
+// and return the pointer to this Field.
+func (f *Field) ReplaceArgument(argument Argument) *Field {
+	for i := range f.Arguments {
+		if f.Arguments[i].Name == argument.Name {
+			f.Arguments[i] = argument
+			f.locateArguments()
+			f.touch()
+			return f
+		}
+	}
+	f.Arguments = append(f.Arguments, argument)
+	f.locateArguments()
+	f.touch()
 	return f
 }
 
 // SetFields sets the sub fields of a Field and return the pointer to this Field.
 func (f *Field) SetFields(fs ...*Field) *Field {
 	f.Fields = fs
+	f.touch()
+	return f
+}
+
+// RemoveField removes the sub field(s) of the given name and return the pointer to this Field.
+func (f *Field) RemoveField(name string) *Field {
+	var fields []*Field
+	for _, subF := range f.Fields {
+		if subF == nil || subF.Name != name {
+			fields = append(fields, subF)
+		}
+	}
+	f.Fields = fields
+	f.touch()
+	return f
+}
+
+// ReplaceField replaces the sub field of the given name by field in place, or adds field if there is none,
+// and return the pointer to this Field.
+func (f *Field) ReplaceField(name string, field *Field) *Field {
+	for i, subF := range f.Fields {
+		if subF != nil && subF.Name == name {
+			f.Fields[i] = field
+			f.touch()
+			return f
+		}
+	}
+	f.Fields = append(f.Fields, field)
+	f.touch()
 	return f
 }
 
 // SetAlias sets the alias of a Field and return the pointer to this Field.
 func (f *Field) SetAlias(alias string) *Field {
 	f.Alias = alias
+	f.touch()
 	return f
 }
 
@@ -174,6 +357,10 @@ func reach(f1, f2 *Field) error {
 		return errors.WithStack(NilFieldErr{})
 	}
 	for _, field := range f2.Fields {
+		if field == nil {
+			// reported by validateTree
+			continue
+		}
 		if f1 == field {
 			return errors.WithStack(CyclicFieldErr{*f1})
 		}
@@ -16,5 +16,52 @@ func TestField_AddArguments(t *testing.T) {
 
 func TestField_CheckInlineFragment(t *testing.T) {
 	f := MakeField("... on f")
-	assert.NoError(t, f.checkOther())
+	assert.NoError(t, f.check())
+
+	f = MakeField("...")
+	assert.NoError(t, f.check())
+
+	f = MakeField("abc on f")
+	assert.Error(t, f.check())
+}
+
+func TestField_GetArgument(t *testing.T) {
+	f := MakeField("f").SetArguments(ArgumentInt("a", 1), ArgumentString("b", "x"))
+	arg, ok := f.GetArgument("b")
+	assert.True(t, ok)
+	assert.Equal(t, ArgumentString("b", "x"), arg)
+
+	arg, ok = f.GetArgument("c")
+	assert.False(t, ok)
+	assert.Equal(t, Argument{}, arg)
+}
+
+func TestField_RemoveArgument(t *testing.T) {
+	f := MakeField("f").SetArguments(ArgumentInt("a", 1), ArgumentString("b", "x"), ArgumentInt("a", 2))
+	f.RemoveArgument("a")
+	assert.Equal(t, []Argument{ArgumentString("b", "x")}, f.Arguments)
+
+	f.RemoveArgument("b").RemoveArgument("b")
+	assert.Empty(t, f.Arguments)
+	assert.Equal(t, "f", StringFromChan(f.stringChan()))
+}
+
+func TestField_ReplaceArgument(t *testing.T) {
+	f := MakeField("f").SetArguments(ArgumentInt("first", 10), ArgumentString("after", "a"))
+	f.ReplaceArgument(ArgumentString("after", "b")).ReplaceArgument(ArgumentBool("new", true))
+	assert.Equal(t, `f(first:10,after:"b",new:true)`, StringFromChan(f.stringChan()))
+}
+
+func TestField_RemoveField(t *testing.T) {
+	f := MakeField("f").SetFields(MakeField("a"), MakeField("b"), MakeField("a"))
+	f.RemoveField("a")
+	assert.Equal(t, "f{b}", StringFromChan(f.stringChan()))
+	f.RemoveField("b")
+	assert.Equal(t, "f", StringFromChan(f.stringChan()))
+}
+
+func TestField_ReplaceField(t *testing.T) {
+	f := MakeField("f").SetFields(MakeField("a"), MakeField("b"))
+	f.ReplaceField("a", MakeField("c").SetFields(MakeField("d"))).ReplaceField("e", MakeField("e"))
+	assert.Equal(t, "f{c{d},b,e}", StringFromChan(f.stringChan()))
 }
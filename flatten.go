@@ -0,0 +1,51 @@
+package graphb
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// Flatten returns the leaf values of a response, e.g. its "data" member, keyed by their dotted paths of response keys
+// and list indices, e.g. "user.posts.0.title", for assertions and metrics.
+// Leaves are strings, booleans, nil, json.Number(s) keeping numbers exact, and empty objects and lists.
+// A value at the root is keyed by the empty path.
+func Flatten(data json.RawMessage) (map[string]interface{}, error) {
+	v, err := decodeResult(data)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	flat := make(map[string]interface{})
+	flatten("", v, flat)
+	return flat, nil
+}
+
+func flatten(path string, v interface{}, flat map[string]interface{}) {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		if len(x) == 0 {
+			break
+		}
+		for key, member := range x {
+			flatten(joinPath(path, key), member, flat)
+		}
+		return
+	case []interface{}:
+		if len(x) == 0 {
+			break
+		}
+		for i, elem := range x {
+			flatten(joinPath(path, strconv.Itoa(i)), elem, flat)
+		}
+		return
+	}
+	flat[path] = v
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
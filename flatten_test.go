@@ -0,0 +1,34 @@
+package graphb
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlatten(t *testing.T) {
+	flat, err := Flatten(json.RawMessage(`{
+		"user": {"name": "Ada", "admin": false, "manager": null, "tags": [], "settings": {},
+			"posts": [{"title": "a", "likes": 12345678901234567890}, {"title": "b", "comments": [{"id": "c1"}]}]}
+	}`))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"user.name":                  "Ada",
+		"user.admin":                 false,
+		"user.manager":               nil,
+		"user.tags":                  []interface{}{},
+		"user.settings":              map[string]interface{}{},
+		"user.posts.0.title":         "a",
+		"user.posts.0.likes":         json.Number("12345678901234567890"),
+		"user.posts.1.title":         "b",
+		"user.posts.1.comments.0.id": "c1",
+	}, flat)
+
+	flat, err = Flatten(json.RawMessage(`1`))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"": json.Number("1")}, flat)
+
+	_, err = Flatten(json.RawMessage(`{"a":`))
+	assert.NotNil(t, err)
+}
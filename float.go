@@ -0,0 +1,47 @@
+package graphb
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// FloatFormat is how ArgumentFloat formats a value.
+type FloatFormat int
+
+const (
+	// FloatFixed formats without an exponent, e.g. 0.0000001 and 1000000000000000000000.0, which every server accepts.
+	FloatFixed FloatFormat = iota
+	// FloatShortest formats with the shortest representation, using an exponent for large and small magnitudes, e.g. 1e-07.
+	FloatShortest
+)
+
+// ArgumentFloat returns a Float argument. Its value is formatted with the first format given, FloatFixed by default,
+// and always reads as a Float, e.g. 1 is formatted as 1.0.
+// Both formats round trip: parsing the literal as a float64 gives value back.
+// NaN and infinities have no GraphQL literal and return an InvalidFloatErr.
+func ArgumentFloat(name string, value float64, format ...FloatFormat) (Argument, error) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return Argument{}, errors.WithStack(InvalidFloatErr{name, value})
+	}
+	f := FloatFixed
+	if len(format) > 0 {
+		f = format[0]
+	}
+	return Argument{name, argTokens{{TokenFloat, floatLiteral(value, f)}}}, nil
+}
+
+func floatLiteral(v float64, format FloatFormat) string {
+	var s string
+	if format == FloatShortest {
+		s = strconv.FormatFloat(v, 'g', -1, 64)
+	} else {
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
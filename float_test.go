@@ -0,0 +1,50 @@
+package graphb
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArgumentFloat(t *testing.T) {
+	cases := []struct {
+		value    float64
+		fixed    string
+		shortest string
+	}{
+		{1.5, "1.5", "1.5"},
+		{1, "1.0", "1.0"},
+		{-0.1, "-0.1", "-0.1"},
+		{1e21, "1000000000000000000000.0", "1e+21"},
+		{1e-7, "0.0000001", "1e-07"},
+		{123456789.125, "123456789.125", "1.23456789125e+08"},
+	}
+	for _, c := range cases {
+		fixed, err := ArgumentFloat("x", c.value)
+		assert.Nil(t, err)
+		assert.Equal(t, "x:"+c.fixed, StringFromChan(literals(fixed.tokenChan())))
+		shortest, err := ArgumentFloat("x", c.value, FloatShortest)
+		assert.Nil(t, err)
+		assert.Equal(t, "x:"+c.shortest, StringFromChan(literals(shortest.tokenChan())))
+
+		for _, s := range []string{c.fixed, c.shortest} {
+			parsed, err := strconv.ParseFloat(s, 64)
+			assert.Nil(t, err)
+			assert.Equal(t, c.value, parsed)
+		}
+	}
+}
+
+func TestArgumentFloat_nonFinite(t *testing.T) {
+	for _, v := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		_, err := ArgumentFloat("x", v)
+		e, ok := errors.Cause(err).(InvalidFloatErr)
+		assert.True(t, ok)
+		assert.Equal(t, "x", e.Argument)
+	}
+	_, err := ArgumentFloat("ratio", math.Inf(-1))
+	assert.Equal(t, "value -Inf of argument 'ratio' is not a finite number, which GraphQL Float literals can not represent", errors.Cause(err).Error())
+}
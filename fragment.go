@@ -0,0 +1,154 @@
+package graphb
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Fragment is a named selection registered in a FragmentRegistry, e.g. `fragment UserCard on User{id,name}`.
+type Fragment struct {
+	Name          string
+	TypeCondition string // The type the fragment applies to.
+	Fields        []*Field
+}
+
+// FragmentRegistry is a library of named selections which are registered once and spread into any number of queries.
+// It is safe for concurrent use.
+type FragmentRegistry struct {
+	mu        sync.RWMutex
+	fragments map[string]*Fragment
+}
+
+// NewFragmentRegistry returns an empty FragmentRegistry.
+func NewFragmentRegistry() *FragmentRegistry {
+	return &FragmentRegistry{fragments: make(map[string]*Fragment)}
+}
+
+// RegisterFragment registers the selection fields of the given name on typeCondition.
+func (r *FragmentRegistry) RegisterFragment(name string, typeCondition string, fields ...*Field) error {
+	if !validName.MatchString(name) || name == "on" {
+		return errors.WithStack(InvalidNameErr{fragmentName, name})
+	}
+	if !validName.MatchString(typeCondition) {
+		return errors.WithStack(InvalidNameErr{typeConditionName, typeCondition})
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.fragments[name]; ok {
+		return errors.WithStack(DuplicateFragmentErr{name})
+	}
+	r.fragments[name] = &Fragment{Name: name, TypeCondition: typeCondition, Fields: fields}
+	return nil
+}
+
+// Fragment returns the fragment of the given name and whether it is registered.
+func (r *FragmentRegistry) Fragment(name string) (*Fragment, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	frag, ok := r.fragments[name]
+	return frag, ok
+}
+
+// Spread returns a Field referencing the fragment of the given name, to be added to the selection of a query or a field.
+// By default, it is serialized as an inline fragment, e.g. `... on User{id,name}`.
+// If the query is built with OfFragmentDefinitions, it is serialized as `...UserCard` and the query is followed by the fragment definition.
+func (r *FragmentRegistry) Spread(name string) (*Field, error) {
+	frag, ok := r.Fragment(name)
+	if !ok {
+		return nil, errors.WithStack(UndefinedFragmentErr{name})
+	}
+	return &Field{
+		Name:     "... on " + frag.TypeCondition,
+		Fields:   append([]*Field(nil), frag.Fields...),
+		fragment: frag,
+	}, nil
+}
+
+// OfSpread returns a FieldContainerOption which adds the spread of a registered fragment to the targeting query or field.
+func OfSpread(r *FragmentRegistry, name string) FieldContainerOption {
+	return func(fc fieldContainer) error {
+		f, err := r.Spread(name)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		fc.setFields(append(fc.getFields(), f))
+		return nil
+	}
+}
+
+// OfFragmentDefinitions returns a QueryOption which makes the query serialize the spreads of registered fragments
+// as named fragment spreads followed by the fragment definitions, instead of inline fragments.
+func OfFragmentDefinitions() QueryOption {
+	return func(query *Query) error {
+		query.FragmentDefinitions = true
+		return nil
+	}
+}
+
+// fragmentDefinition is a fragment definition emitted after the operation.
+type fragmentDefinition struct {
+	fragment *Fragment
+	fields   []*Field
+}
+
+func (d *fragmentDefinition) tokenChan() <-chan Token {
+	tokenChan := make(chan Token)
+	go func() {
+		tokenChan <- Token{TokenKeyword, KeywordFragment}
+		tokenChan <- Token{TokenSpace, tokenSpace}
+		tokenChan <- Token{TokenName, d.fragment.Name}
+		tokenChan <- Token{TokenSpace, tokenSpace}
+		tokenChan <- Token{TokenKeyword, KeywordOn}
+		tokenChan <- Token{TokenSpace, tokenSpace}
+		tokenChan <- Token{TokenName, d.fragment.TypeCondition}
+		tokenChan <- Token{TokenPunctuator, tokenLB}
+		for i, field := range d.fields {
+			if i != 0 {
+				tokenChan <- Token{TokenPunctuator, tokenComma}
+			}
+			for tok := range field.tokenChan() {
+				tokenChan <- tok
+			}
+		}
+		tokenChan <- Token{TokenPunctuator, tokenRB}
+		close(tokenChan)
+	}()
+	return tokenChan
+}
+
+// spreadFragments returns a copy of fs where the fields built by FragmentRegistry.Spread are replaced by named fragment spreads,
+// and the definitions of the spread fragments in the order of their first use.
+// The selection of a definition is the one of the first spread of the fragment.
+func spreadFragments(fs []*Field) ([]*Field, []*fragmentDefinition) {
+	var defs []*fragmentDefinition
+	defined := make(map[*Fragment]bool)
+	var spread func(fs []*Field) []*Field
+	spread = func(fs []*Field) []*Field {
+		if fs == nil {
+			return nil
+		}
+		copied := make([]*Field, len(fs))
+		for i, f := range fs {
+			if f == nil {
+				continue
+			}
+			if f.fragment != nil {
+				if !defined[f.fragment] {
+					defined[f.fragment] = true
+					def := &fragmentDefinition{fragment: f.fragment}
+					defs = append(defs, def)
+					def.fields = spread(f.Fields)
+				}
+				copied[i] = &Field{Name: "..." + f.fragment.Name, Directives: f.Directives}
+				continue
+			}
+			c := *f
+			c.compiled = nil
+			c.Fields = spread(f.Fields)
+			copied[i] = &c
+		}
+		return copied
+	}
+	return spread(fs), defs
+}
@@ -0,0 +1,77 @@
+package graphb
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFragmentRegistry_RegisterFragment(t *testing.T) {
+	r := NewFragmentRegistry()
+	assert.Nil(t, r.RegisterFragment("UserCard", "User", Fields("id", "name")...))
+
+	err := r.RegisterFragment("UserCard", "User", Fields("id")...)
+	assert.Equal(t, DuplicateFragmentErr{"UserCard"}, errors.Cause(err))
+	err = r.RegisterFragment("on", "User")
+	assert.Equal(t, InvalidNameErr{fragmentName, "on"}, errors.Cause(err))
+	err = r.RegisterFragment("Card", "[User]")
+	assert.Equal(t, InvalidNameErr{typeConditionName, "[User]"}, errors.Cause(err))
+
+	frag, ok := r.Fragment("UserCard")
+	assert.True(t, ok)
+	assert.Equal(t, "User", frag.TypeCondition)
+	_, err = r.Spread("Missing")
+	assert.Equal(t, UndefinedFragmentErr{"Missing"}, errors.Cause(err))
+}
+
+func TestFragmentRegistry_Spread(t *testing.T) {
+	r := NewFragmentRegistry()
+	assert.Nil(t, r.RegisterFragment("UserCard", "User", Fields("id", "name")...))
+	avatar := MakeField("avatar").SetArguments(ArgumentInt("size", 64))
+	assert.Nil(t, r.RegisterFragment("UserAvatar", "User", avatar))
+
+	build := func(options ...QueryOptionInterface) *Query {
+		q := NewQuery(TypeQuery, append(options,
+			OfField("me", OfSpread(r, "UserCard"), OfSpread(r, "UserAvatar")),
+			OfField("friends", OfSpread(r, "UserCard")),
+		)...)
+		assert.Nil(t, q.E)
+		return q
+	}
+
+	s, err := build().JSON()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"query":"query{me{... on User{id,name},... on User{avatar(size:64)}},friends{... on User{id,name}}}"}`, s)
+
+	q := build(OfFragmentDefinitions())
+	s, err = q.JSON()
+	assert.Nil(t, err)
+	expected := `query{me{...UserCard,...UserAvatar},friends{...UserCard}}fragment UserCard on User{id,name}fragment UserAvatar on User{avatar(size:64)}`
+	assert.Equal(t, `{"query":"`+expected+`"}`, s)
+
+	s, err = q.StringParallel(2)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, s)
+
+	s, err = q.StringRedacted("size")
+	assert.Nil(t, err)
+	assert.Equal(t, `query{me{...UserCard,...UserAvatar},friends{...UserCard}}fragment UserCard on User{id,name}fragment UserAvatar on User{avatar(size:"<redacted>")}`, s)
+}
+
+func TestFragmentRegistry_Spread_nested(t *testing.T) {
+	r := NewFragmentRegistry()
+	assert.Nil(t, r.RegisterFragment("UserCard", "User", Fields("id")...))
+	card, err := r.Spread("UserCard")
+	assert.Nil(t, err)
+	assert.Nil(t, r.RegisterFragment("PostCard", "Post", MakeField("title"), MakeField("author").SetFields(card)))
+
+	post, err := r.Spread("PostCard")
+	assert.Nil(t, err)
+	post.AddDirectives(MakeDirective("include", ArgumentVariable("if", "withPosts")))
+	q := MakeQuery(TypeQuery).AddVariables(Variable{Name: "withPosts", Type: "Boolean!"}).SetFields(MakeField("posts").SetFields(post))
+	q.FragmentDefinitions = true
+	strCh, err := q.StringChan()
+	assert.Nil(t, err)
+	assert.Equal(t, `query($withPosts:Boolean!){posts{...PostCard@include(if:$withPosts)}}fragment PostCard on Post{title,author{...UserCard}}fragment UserCard on User{id}`, StringFromChan(strCh))
+}
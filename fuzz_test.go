@@ -0,0 +1,180 @@
+package graphb
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// fuzzNames are valid names picked by treeFromBytes.
+var fuzzNames = []string{"a", "user", "_id", "node2", "A_B"}
+
+// treeFromBytes builds a query tree from fuzzer input: each byte decides the next field's name,
+// number of arguments and number of sub fields, until the input or the depth runs out.
+// String values are escaped, so that any value makes a valid document.
+func treeFromBytes(data []byte, value string) *Query {
+	pos := 0
+	next := func() int {
+		if pos >= len(data) {
+			return 0
+		}
+		pos++
+		return int(data[pos-1])
+	}
+	var build func(depth int) *Field
+	build = func(depth int) *Field {
+		b := next()
+		f := MakeField(fuzzNames[b%len(fuzzNames)])
+		if b&0x08 != 0 {
+			f.SetAlias("alias")
+		}
+		switch (b >> 4) % 4 {
+		case 1:
+			f.AddArguments(ArgumentString("s", value))
+		case 2:
+			f.AddArguments(ArgumentInt("i", b), ArgumentStringSlice("ss", value, value))
+		case 3:
+			f.AddArguments(ArgumentCustomType("o", ArgumentBool("b", b%2 == 0), ArgumentEnum("e", "E")))
+		}
+		if depth < 5 {
+			for n := next() % 4; n > 0 && pos < len(data); n-- {
+				f.Fields = append(f.Fields, build(depth+1))
+			}
+		}
+		return f
+	}
+
+	q := MakeQuery(TypeQuery, NewConfig(WithStrictEscaping()))
+	for pos < len(data) {
+		q.AddFields(build(0))
+	}
+	return q
+}
+
+func FuzzSerialize(f *testing.F) {
+	f.Add([]byte{0x10, 0x02, 0x21, 0x00, 0x33}, "hello")
+	f.Add([]byte{0xff, 0x03, 0x01, 0x02, 0x03, 0x04}, "")
+	f.Add([]byte{0x08}, "{[(")
+	f.Add([]byte{0x10}, "say \"hi\"\\\n\u2028")
+
+	f.Fuzz(func(t *testing.T, data []byte, value string) {
+		q := treeFromBytes(data, value)
+		tokens, err := q.Tokens()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var literals []string
+		depth := map[string]int{}
+		closing := map[string]string{tokenRB: tokenLB, tokenRP: tokenLP, tokenRSB: tokenLSB}
+		for tok := range tokens {
+			literals = append(literals, tok.Literal)
+			if tok.Kind != TokenPunctuator {
+				continue
+			}
+			if open, ok := closing[tok.Literal]; ok {
+				depth[open]--
+				if depth[open] < 0 {
+					t.Fatalf("unbalanced %q in %q", tok.Literal, strings.Join(literals, ""))
+				}
+			} else {
+				depth[tok.Literal]++
+			}
+		}
+		for open, d := range depth {
+			if d != 0 && open != tokenColumn && open != tokenComma {
+				t.Fatalf("unclosed %q in %q", open, strings.Join(literals, ""))
+			}
+		}
+
+		strCh, err := q.StringChan()
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := StringFromChan(strCh)
+		if s != strings.Join(literals, "") {
+			t.Fatalf("tokens %q differ from string %q", strings.Join(literals, ""), s)
+		}
+
+		if len(q.Fields) > 0 {
+			doc, err := ParseDocument(s)
+			if err != nil {
+				t.Fatalf("parsing %q: %v", s, err)
+			}
+			strCh, err = doc.Operations[0].StringChan()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if reparsed := StringFromChan(strCh); reparsed != s {
+				t.Fatalf("%q parses back to %q", s, reparsed)
+			}
+		}
+
+		j, err := q.JSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var body struct {
+			Query string `json:"query"`
+		}
+		if err := json.Unmarshal([]byte(j), &body); err != nil {
+			t.Fatalf("invalid JSON %s: %v", j, err)
+		}
+		if body.Query != s {
+			t.Fatalf("JSON %s does not carry %q", j, s)
+		}
+	})
+}
+
+func FuzzEscapedString(f *testing.F) {
+	for _, seed := range []string{"", "a", `"`, `\`, "\n\r\t", "\x00\x1f", "\u2028", "é我", "\xff", `"""`} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, value string) {
+		escaped := jsonString(value)
+		lexemes, err := lex(escaped)
+		if err != nil {
+			t.Fatalf("lexing %s: %v", escaped, err)
+		}
+		if len(lexemes) != 1 || lexemes[0].Kind != TokenString || lexemes[0].Literal != escaped {
+			t.Fatalf("%s lexes as %v", escaped, lexemes)
+		}
+		var decoded string
+		if err := json.Unmarshal([]byte(escaped), &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if want := string([]rune(value)); decoded != want {
+			t.Fatalf("%s decodes to %q, not %q", escaped, decoded, want)
+		}
+	})
+}
+
+// referenceName implements the Name production of the spec rune by rune:
+// Name :: /[_A-Za-z][_0-9A-Za-z]*/
+func referenceName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		letter := r == '_' || (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z')
+		digit := r >= '0' && r <= '9'
+		if !letter && !(digit && i > 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func FuzzValidName(f *testing.F) {
+	for _, seed := range []string{"", "a", "_1", "1a", "a-b", "我", "a\n", "A_b9"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, name string) {
+		if got, want := validName.MatchString(name), referenceName(name); got != want {
+			t.Fatalf("validName(%q) = %v, the spec says %v", name, got, want)
+		}
+		if _, offset := invalidNameRune(name); (offset < 0) != referenceName(name) {
+			t.Fatalf("invalidNameRune(%q) offset = %d, the spec says %v", name, offset, referenceName(name))
+		}
+	})
+}
@@ -53,7 +53,7 @@ func TestTheWholePackage(t *testing.T) {
 			},
 		}
 		strCh, err := q.StringChan()
-		assert.Equal(t, "'Lets_Have_An_Alias看' is an invalid alias name in GraphQL. A valid name matches /[_A-Za-z][_0-9A-Za-z]*/, see: http://facebook.github.io/graphql/October2016/#sec-Names", err.Error())
+		assert.Equal(t, "'Lets_Have_An_Alias看' is an invalid alias name in GraphQL: unexpected '看' (U+770B) at byte offset 18. A valid name matches /[_A-Za-z][_0-9A-Za-z]*/, see: http://facebook.github.io/graphql/October2016/#sec-Names", err.Error())
 		value, ok := <-strCh
 		assert.Equal(t, "", value)
 		assert.Equal(t, false, ok)
@@ -151,8 +151,8 @@ func TestTheWholePackage(t *testing.T) {
 	})
 
 	t.Run("Nil field error 2", func(t *testing.T) {
-		f := Field{Fields: []*Field{nil}}
-		err := f.checkCycle()
+		f := Field{Name: "f", Fields: []*Field{nil}}
+		err := f.check()
 		assert.IsTypef(t, NilFieldErr{}, errors.Cause(err), "")
 	})
 }
@@ -0,0 +1,85 @@
+package graphb
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// WithHedging returns an ExecuteFunc which sends a duplicate of a query when no response arrived after delay,
+// and returns the first successful response, canceling the other request.
+// Only query operations are hedged, mutations and subscriptions are executed once.
+// A request failing with a transport error before delay is hedged right away, see isTransportErr.
+// Other errors, such as GraphQL errors, are answers of the endpoint and are returned as soon as they arrive.
+// If both requests fail with transport errors, the error of the first one to fail is returned.
+func WithHedging(execute ExecuteFunc, delay time.Duration) ExecuteFunc {
+	return func(ctx context.Context, q *Query) (json.RawMessage, error) {
+		if q.Type.Canonical() != TypeQuery {
+			return execute(ctx, q)
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		type response struct {
+			data json.RawMessage
+			err  error
+		}
+		responses := make(chan response, 2)
+		send := func() {
+			data, err := execute(ctx, q)
+			responses <- response{data, err}
+		}
+
+		go send()
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		pending := 1
+		hedged := false
+		var firstErr error
+		for {
+			select {
+			case <-timer.C:
+				if !hedged {
+					hedged = true
+					pending++
+					go send()
+				}
+			case r := <-responses:
+				pending--
+				if r.err == nil {
+					return r.data, nil
+				}
+				if !isTransportErr(r.err) {
+					return nil, errors.WithStack(r.err)
+				}
+				if firstErr == nil {
+					firstErr = r.err
+				}
+				if !hedged {
+					// the first request failed to reach the endpoint before the delay, no need to wait for it
+					hedged = true
+					pending++
+					go send()
+				} else if pending == 0 {
+					return nil, errors.WithStack(firstErr)
+				}
+			}
+		}
+	}
+}
+
+// isTransportErr reports whether err tells that a request did not get an answer from the endpoint:
+// a network error, which includes the errors of http.Client, or a 5xx HTTP status.
+func isTransportErr(err error) bool {
+	switch e := errors.Cause(err).(type) {
+	case net.Error:
+		return true
+	case UnexpectedStatusErr:
+		return e.Status >= 500
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package graphb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithHedging(t *testing.T) {
+	t.Run("the hedge wins over a slow request", func(t *testing.T) {
+		var calls int32
+		execute := func(ctx context.Context, q *Query) (json.RawMessage, error) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+			return json.RawMessage(`{"me":"hedge"}`), nil
+		}
+		data, err := WithHedging(execute, time.Millisecond)(context.Background(), MakeQuery(TypeQuery))
+		assert.Nil(t, err)
+		assert.Equal(t, `{"me":"hedge"}`, string(data))
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("fast requests are not hedged", func(t *testing.T) {
+		var calls int32
+		execute := func(ctx context.Context, q *Query) (json.RawMessage, error) {
+			atomic.AddInt32(&calls, 1)
+			return json.RawMessage(`{}`), nil
+		}
+		_, err := WithHedging(execute, time.Hour)(context.Background(), MakeQuery(TypeQuery))
+		assert.Nil(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("a transport error is retried once", func(t *testing.T) {
+		var calls int32
+		execute := func(ctx context.Context, q *Query) (json.RawMessage, error) {
+			return nil, &url.Error{Op: "Post", URL: "http://api", Err: fmt.Errorf("call %d", atomic.AddInt32(&calls, 1))}
+		}
+		_, err := WithHedging(execute, time.Hour)(context.Background(), MakeQuery(TypeQuery))
+		assert.Equal(t, `Post "http://api": call 1`, errors.Cause(err).Error())
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+		calls = 0
+		execute = func(ctx context.Context, q *Query) (json.RawMessage, error) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				return nil, errors.WithStack(UnexpectedStatusErr{503})
+			}
+			return json.RawMessage(`{}`), nil
+		}
+		_, err = WithHedging(execute, time.Hour)(context.Background(), MakeQuery(TypeQuery))
+		assert.Nil(t, err)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("other errors are not retried", func(t *testing.T) {
+		for _, failure := range []error{fmt.Errorf("Cannot query field 'me'"), UnexpectedStatusErr{400}} {
+			var calls int32
+			execute := func(ctx context.Context, q *Query) (json.RawMessage, error) {
+				atomic.AddInt32(&calls, 1)
+				return nil, failure
+			}
+			_, err := WithHedging(execute, time.Hour)(context.Background(), MakeQuery(TypeQuery))
+			assert.Equal(t, failure, errors.Cause(err))
+			assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+		}
+	})
+
+	t.Run("mutations are never hedged", func(t *testing.T) {
+		var calls int32
+		execute := func(ctx context.Context, q *Query) (json.RawMessage, error) {
+			atomic.AddInt32(&calls, 1)
+			return nil, fmt.Errorf("down")
+		}
+		_, err := WithHedging(execute, 0)(context.Background(), MakeQuery(TypeMutation))
+		assert.NotNil(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+}
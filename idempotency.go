@@ -0,0 +1,97 @@
+package graphb
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultIdempotencyKeyHeader is the header carrying idempotency keys when neither a header nor an extension is given,
+// see the IETF draft "The Idempotency-Key HTTP Header Field".
+const DefaultIdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotencyPolicy configures WithIdempotencyKey.
+type IdempotencyPolicy struct {
+	Header    string        // The header carrying the key, DefaultIdempotencyKeyHeader if both Header and Extension are empty.
+	Extension string        // The member of the request extensions carrying the key, e.g. "idempotencyKey", see Query.Extensions.
+	NewKey    func() string // Generates the keys, random UUIDs if nil.
+	Retries   int           // How many times a mutation failing with a transport error is sent again with the same key.
+	Backoff   time.Duration // The wait before each retry.
+}
+
+// WithIdempotencyKey returns an ExecuteFunc which attaches a key to each mutation, in a header or an extension of the request,
+// and sends the mutation again with the same key when it fails with a transport error, see isTransportErr,
+// so that a server deduplicating requests by key applies a retried mutation once.
+// A mutation carrying a key already, e.g. set by the caller to retry across processes, keeps it.
+// The key is set on a copy of the mutation. Queries and subscriptions are executed as they are, once.
+func WithIdempotencyKey(execute ExecuteFunc, policy IdempotencyPolicy) ExecuteFunc {
+	return func(ctx context.Context, q *Query) (json.RawMessage, error) {
+		if q.Type.Canonical() != TypeMutation {
+			return execute(ctx, q)
+		}
+		keyed := policy.keyed(q)
+		for attempt := 0; ; attempt++ {
+			data, err := execute(ctx, keyed)
+			if err == nil || !isTransportErr(err) || attempt >= policy.Retries {
+				return data, err
+			}
+			timer := time.NewTimer(policy.Backoff)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, errors.WithStack(ctx.Err())
+			}
+		}
+	}
+}
+
+// keyed returns a copy of q carrying its idempotency key, generated unless q carries one already.
+func (p IdempotencyPolicy) keyed(q *Query) *Query {
+	header, extension := p.Header, p.Extension
+	if header == "" && extension == "" {
+		header = DefaultIdempotencyKeyHeader
+	}
+	var key string
+	if header != "" {
+		key = q.Headers[header]
+	}
+	if key == "" && extension != "" {
+		key, _ = q.Extensions[extension].(string)
+	}
+	if key == "" {
+		if p.NewKey != nil {
+			key = p.NewKey()
+		} else {
+			key = newUUID()
+		}
+	}
+
+	keyed := withCopiedHeaders(q)
+	if header != "" {
+		keyed.Headers[header] = key
+	}
+	if extension != "" {
+		keyed.Extensions = make(map[string]interface{}, len(q.Extensions)+1)
+		for k, v := range q.Extensions {
+			keyed.Extensions[k] = v
+		}
+		keyed.Extensions[extension] = key
+	}
+	return keyed
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err) // crypto/rand does not fail on supported platforms
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
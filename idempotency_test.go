@@ -0,0 +1,101 @@
+package graphb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithIdempotencyKey(t *testing.T) {
+	var keys []string
+	failures := 2
+	execute := WithIdempotencyKey(func(ctx context.Context, q *Query) (json.RawMessage, error) {
+		keys = append(keys, q.Headers[DefaultIdempotencyKeyHeader])
+		if failures > 0 {
+			failures--
+			return nil, errors.WithStack(UnexpectedStatusErr{http.StatusServiceUnavailable})
+		}
+		return json.RawMessage(`{"like":true}`), nil
+	}, IdempotencyPolicy{Retries: 2})
+
+	q := MakeQuery(TypeMutation).SetFields(MakeField("like"))
+	data, err := execute(context.Background(), q)
+	assert.Nil(t, err)
+	assert.Equal(t, json.RawMessage(`{"like":true}`), data)
+	assert.Len(t, keys, 3)
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), keys[0])
+	assert.Equal(t, keys[0], keys[1])
+	assert.Equal(t, keys[0], keys[2])
+	// the key is set on a copy
+	assert.Empty(t, q.Headers)
+
+	// a new key for each mutation
+	keys = nil
+	_, err = execute(context.Background(), q)
+	assert.Nil(t, err)
+	_, err = execute(context.Background(), q)
+	assert.Nil(t, err)
+	assert.NotEqual(t, keys[0], keys[1])
+
+	// retries are exhausted
+	keys = nil
+	failures = 5
+	_, err = execute(context.Background(), q)
+	assert.Equal(t, UnexpectedStatusErr{http.StatusServiceUnavailable}, errors.Cause(err))
+	assert.Len(t, keys, 3)
+
+	// queries are not keyed
+	keys = nil
+	failures = 1
+	_, err = execute(context.Background(), MakeQuery(TypeQuery).SetFields(MakeField("me")))
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{""}, keys)
+}
+
+func TestWithIdempotencyKey_extension(t *testing.T) {
+	var requests []Request
+	attempts := 0
+	transport := TransportFunc(func(ctx context.Context, req Request) (Response, error) {
+		requests = append(requests, req)
+		attempts++
+		if attempts == 1 {
+			return Response{}, errors.WithStack(UnexpectedStatusErr{http.StatusBadGateway})
+		}
+		return Response{Data: json.RawMessage(`{}`), Errors: json.RawMessage(`[{"message":"denied"}]`)}, nil
+	})
+	execute := WithIdempotencyKey(ExecuteWith(transport), IdempotencyPolicy{
+		Extension: "idempotencyKey",
+		NewKey:    func() string { return "generated" },
+		Retries:   3,
+		Backoff:   time.Millisecond,
+	})
+
+	q := MakeQuery(TypeMutation).SetFields(MakeField("like"))
+	q.Extensions = map[string]interface{}{"idempotencyKey": "k1", "trace": "t"}
+	_, err := execute(context.Background(), q)
+	// GraphQL errors are answers, not retried
+	assert.IsType(t, GraphQLErrorsErr{}, errors.Cause(err))
+	assert.Len(t, requests, 2)
+	for _, req := range requests {
+		assert.Equal(t, map[string]interface{}{"idempotencyKey": "k1", "trace": "t"}, req.Extensions)
+	}
+
+	s, err := q.JSON()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"query":"mutation{like}","extensions":{"idempotencyKey":"k1","trace":"t"}}`, s)
+
+	// canceled while waiting to retry
+	ctx, cancel := context.WithCancel(context.Background())
+	execute = WithIdempotencyKey(func(ctx context.Context, q *Query) (json.RawMessage, error) {
+		cancel()
+		return nil, errors.WithStack(UnexpectedStatusErr{http.StatusBadGateway})
+	}, IdempotencyPolicy{Retries: 1, Backoff: time.Hour})
+	_, err = execute(ctx, q)
+	assert.Equal(t, context.Canceled, errors.Cause(err))
+}
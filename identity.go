@@ -0,0 +1,62 @@
+package graphb
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// ClientIdentityHeaders names the headers telling the name and the version of the client sending a query,
+// which managed GraphQL platforms use to attribute operations to clients.
+type ClientIdentityHeaders struct {
+	Name    string
+	Version string
+}
+
+// DefaultClientIdentityHeaders are the headers of Apollo, also read by many other platforms,
+// used by Query.SetClientIdentity when no header names are given.
+var DefaultClientIdentityHeaders = ClientIdentityHeaders{
+	Name:    "apollographql-client-name",
+	Version: "apollographql-client-version",
+}
+
+// HiveClientIdentityHeaders are the headers of GraphQL Hive.
+var HiveClientIdentityHeaders = ClientIdentityHeaders{
+	Name:    "graphql-client-name",
+	Version: "graphql-client-version",
+}
+
+// SetClientIdentity sets the headers telling the name and the version of the client sending this Query,
+// named by every ClientIdentityHeaders of headers, or by DefaultClientIdentityHeaders if omitted.
+// An empty version sets no version header.
+func (q *Query) SetClientIdentity(name, version string, headers ...ClientIdentityHeaders) *Query {
+	if len(headers) == 0 {
+		headers = []ClientIdentityHeaders{DefaultClientIdentityHeaders}
+	}
+	for _, names := range headers {
+		q.AddHeader(names.Name, name)
+		if version != "" {
+			q.AddHeader(names.Version, version)
+		}
+	}
+	return q
+}
+
+// OfClientIdentity returns a QueryOption which sets the client identity headers of the targeting query, see Query.SetClientIdentity.
+func OfClientIdentity(name, version string, headers ...ClientIdentityHeaders) QueryOption {
+	return func(query *Query) error {
+		query.SetClientIdentity(name, version, headers...)
+		return nil
+	}
+}
+
+// WithClientIdentity returns an ExecuteFunc which sets the client identity headers on every query executed by execute,
+// see Query.SetClientIdentity. The headers are set on a copy of the query, and a query setting its own identity keeps it.
+func WithClientIdentity(execute ExecuteFunc, name, version string, headers ...ClientIdentityHeaders) ExecuteFunc {
+	return func(ctx context.Context, q *Query) (json.RawMessage, error) {
+		identified := withCopiedHeaders(q).SetClientIdentity(name, version, headers...)
+		for k, v := range q.Headers {
+			identified.Headers[k] = v
+		}
+		return execute(ctx, identified)
+	}
+}
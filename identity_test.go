@@ -0,0 +1,59 @@
+package graphb
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery_SetClientIdentity(t *testing.T) {
+	q := MakeQuery(TypeQuery).SetClientIdentity("web", "1.2")
+	assert.Equal(t, map[string]string{
+		"apollographql-client-name":    "web",
+		"apollographql-client-version": "1.2",
+	}, q.Headers)
+
+	q = MakeQuery(TypeQuery).SetClientIdentity("web", "", DefaultClientIdentityHeaders, HiveClientIdentityHeaders, ClientIdentityHeaders{"X-Client", "X-Client-Version"})
+	assert.Equal(t, map[string]string{
+		"apollographql-client-name": "web",
+		"graphql-client-name":       "web",
+		"X-Client":                  "web",
+	}, q.Headers)
+
+	q = NewQuery(TypeQuery, OfClientIdentity("ios", "3.0", HiveClientIdentityHeaders), OfField("a"))
+	assert.Nil(t, q.E)
+	assert.Equal(t, map[string]string{"graphql-client-name": "ios", "graphql-client-version": "3.0"}, q.Headers)
+
+	req, err := q.NewRequest(context.Background(), "POST", "http://example.com")
+	assert.Nil(t, err)
+	assert.Equal(t, "ios", req.Header.Get("Graphql-Client-Name"))
+}
+
+func TestWithClientIdentity(t *testing.T) {
+	var sent map[string]string
+	execute := WithClientIdentity(func(ctx context.Context, q *Query) (json.RawMessage, error) {
+		sent = q.Headers
+		return nil, nil
+	}, "web", "1.2")
+
+	q := MakeQuery(TypeQuery).AddHeader("Authorization", "Bearer q")
+	_, err := execute(context.Background(), q)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{
+		"Authorization":                "Bearer q",
+		"apollographql-client-name":    "web",
+		"apollographql-client-version": "1.2",
+	}, sent)
+	// not modified
+	assert.Equal(t, map[string]string{"Authorization": "Bearer q"}, q.Headers)
+
+	// the identity of the query is kept
+	_, err = execute(context.Background(), MakeQuery(TypeQuery).SetClientIdentity("admin", "0.1"))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{
+		"apollographql-client-name":    "admin",
+		"apollographql-client-version": "0.1",
+	}, sent)
+}
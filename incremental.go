@@ -0,0 +1,130 @@
+package graphb
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// IncrementalOption sets an optional argument of a @defer or @stream directive.
+type IncrementalOption func(d *Directive)
+
+// IncrementalIf sets the "if" argument of the directive, which disables incremental delivery when false.
+func IncrementalIf(condition bool) IncrementalOption {
+	return func(d *Directive) {
+		d.Arguments = append(d.Arguments, ArgumentBool("if", condition))
+	}
+}
+
+// IncrementalIfVariable sets the "if" argument of the directive to a reference to the Boolean variable of the given name.
+func IncrementalIfVariable(variable string) IncrementalOption {
+	return func(d *Directive) {
+		d.Arguments = append(d.Arguments, ArgumentVariable("if", variable))
+	}
+}
+
+// Defer returns a @defer directive, to be attached to an inline fragment, e.g. MakeField("...").AddDirectives(Defer("details")).
+// The label is omitted when empty, and escaped otherwise.
+func Defer(label string, options ...IncrementalOption) Directive {
+	return incrementalDirective(Directive{Name: "defer"}, label, options)
+}
+
+// Stream returns a @stream directive, to be attached to a list field.
+// The label is omitted when empty, and escaped otherwise.
+func Stream(label string, initialCount int, options ...IncrementalOption) Directive {
+	return incrementalDirective(Directive{Name: "stream", Arguments: []Argument{ArgumentInt("initialCount", initialCount)}}, label, options)
+}
+
+func incrementalDirective(d Directive, label string, options []IncrementalOption) Directive {
+	if label != "" {
+		d.Arguments = append(d.Arguments, Argument{"label", argTokens{{TokenString, jsonString(label)}}})
+	}
+	for _, option := range options {
+		option(&d)
+	}
+	return d
+}
+
+// IncrementalPayload is one part of a response to a query using @defer or @stream.
+// The first payload carries Data, the following ones carry Incremental patches until HasNext is false.
+// A payload whose Err is not nil reports a decoding failure and is the last one.
+type IncrementalPayload struct {
+	Data        json.RawMessage    `json:"data,omitempty"`
+	Errors      json.RawMessage    `json:"errors,omitempty"`
+	Incremental []IncrementalPatch `json:"incremental,omitempty"`
+	HasNext     bool               `json:"hasNext"`
+	Err         error              `json:"-"`
+}
+
+// IncrementalPatch is the result of a deferred fragment (Data) or of streamed list items (Items) at Path.
+type IncrementalPatch struct {
+	Data   json.RawMessage `json:"data,omitempty"`
+	Items  json.RawMessage `json:"items,omitempty"`
+	Errors json.RawMessage `json:"errors,omitempty"`
+	Path   []interface{}   `json:"path"`
+	Label  string          `json:"label,omitempty"`
+}
+
+// DecodeIncremental decodes a multipart/mixed incremental delivery response body, which it closes once done.
+// contentType is the Content-Type header of the response, which carries the multipart boundary.
+// The returned channel is closed after the payload whose HasNext is false, at the end of body, after a payload reporting an error,
+// or once ctx is done, so that a consumer which stops reading cancels ctx rather than leaking the decoding goroutine.
+func DecodeIncremental(ctx context.Context, body io.ReadCloser, contentType string) (<-chan IncrementalPayload, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		body.Close()
+		return nil, errors.WithStack(err)
+	}
+	if !strings.HasPrefix(mediaType, "multipart/") || params["boundary"] == "" {
+		body.Close()
+		return nil, errors.WithStack(UnexpectedContentTypeErr{contentType})
+	}
+
+	reader := multipart.NewReader(body, params["boundary"])
+	payloadChan := make(chan IncrementalPayload)
+	send := func(payload IncrementalPayload) bool {
+		select {
+		case payloadChan <- payload:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	done := make(chan struct{})
+	go func() {
+		// closing body unblocks a pending read once ctx is done
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		body.Close()
+	}()
+	go func() {
+		defer close(payloadChan)
+		defer close(done)
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				send(IncrementalPayload{Err: errors.WithStack(err)})
+				return
+			}
+			var payload IncrementalPayload
+			if err := json.NewDecoder(part).Decode(&payload); err != nil {
+				send(IncrementalPayload{Err: errors.WithStack(err)})
+				return
+			}
+			if !send(payload) || !payload.HasNext {
+				return
+			}
+		}
+	}()
+	return payloadChan, nil
+}
@@ -0,0 +1,110 @@
+package graphb
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeferAndStream(t *testing.T) {
+	q := MakeQuery(TypeQuery).SetFields(
+		MakeField("user").SetFields(
+			MakeField("id"),
+			MakeField("... on User").AddDirectives(Defer("details")).SetFields(MakeField("bio")),
+			MakeField("...").AddDirectives(Defer("")).SetFields(MakeField("age")),
+			MakeField("friends").AddDirectives(Stream("friendList", 2)).SetFields(MakeField("id")),
+		),
+	)
+	strCh, err := q.StringChan()
+	assert.Nil(t, err)
+	assert.Equal(t, `query{user{id,... on User@defer(label:"details"){bio},...@defer{age},friends@stream(initialCount:2,label:"friendList"){id}}}`, StringFromChan(strCh))
+
+	q = MakeQuery(TypeQuery).AddVariables(Variable{Name: "slow", Type: "Boolean!"}).SetFields(
+		MakeField("user").SetFields(
+			MakeField("...").AddDirectives(Defer(`say "hi"`, IncrementalIfVariable("slow"))).SetFields(MakeField("bio")),
+			MakeField("friends").AddDirectives(Stream("", 0, IncrementalIf(false))).SetFields(MakeField("id")),
+		),
+	)
+	strCh, err = q.StringChan()
+	assert.Nil(t, err)
+	assert.Equal(t, `query($slow:Boolean!){user{...@defer(label:"say \"hi\"",if:$slow){bio},friends@stream(initialCount:0,if:false){id}}}`, StringFromChan(strCh))
+}
+
+// closeRecorder records whether it was closed, and closes its reader if it can be.
+type closeRecorder struct {
+	io.Reader
+	closed chan struct{}
+}
+
+func (r *closeRecorder) Close() error {
+	close(r.closed)
+	if c, ok := r.Reader.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+func TestDecodeIncremental(t *testing.T) {
+	body := "\r\n--graphql\r\n" +
+		"Content-Type: application/json\r\n\r\n" +
+		`{"data":{"user":{"id":"1"}},"hasNext":true}` + "\r\n" +
+		"--graphql\r\n" +
+		"Content-Type: application/json\r\n\r\n" +
+		`{"incremental":[{"data":{"bio":"hi"},"path":["user"],"label":"details"},{"items":[{"id":"2"}],"path":["user","friends",2]}],"hasNext":false}` + "\r\n" +
+		"--graphql--\r\n"
+
+	t.Run("success", func(t *testing.T) {
+		rc := &closeRecorder{strings.NewReader(body), make(chan struct{})}
+		ch, err := DecodeIncremental(context.Background(), rc, `multipart/mixed; boundary="graphql"; deferSpec=20220824`)
+		assert.Nil(t, err)
+
+		var payloads []IncrementalPayload
+		for p := range ch {
+			payloads = append(payloads, p)
+		}
+		assert.Equal(t, []IncrementalPayload{
+			{Data: json.RawMessage(`{"user":{"id":"1"}}`), HasNext: true},
+			{
+				Incremental: []IncrementalPatch{
+					{Data: json.RawMessage(`{"bio":"hi"}`), Path: []interface{}{"user"}, Label: "details"},
+					{Items: json.RawMessage(`[{"id":"2"}]`), Path: []interface{}{"user", "friends", float64(2)}},
+				},
+			},
+		}, payloads)
+		<-rc.closed
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		pr, pw := io.Pipe()
+		rc := &closeRecorder{pr, make(chan struct{})}
+		ch, err := DecodeIncremental(ctx, rc, `multipart/mixed; boundary=graphql`)
+		assert.Nil(t, err)
+		go io.WriteString(pw, "\r\n--graphql\r\n\r\n"+`{"data":{},"hasNext":true}`+"\r\n--graphql\r\n")
+		// the consumer stops reading before the first payload, then the stream stalls
+		cancel()
+		<-rc.closed
+		for range ch {
+		}
+	})
+
+	t.Run("malformed part", func(t *testing.T) {
+		ch, err := DecodeIncremental(context.Background(), io.NopCloser(strings.NewReader("\r\n--graphql\r\n\r\n{\r\n--graphql--\r\n")), `multipart/mixed; boundary=graphql`)
+		assert.Nil(t, err)
+		p, ok := <-ch
+		assert.True(t, ok)
+		assert.NotNil(t, p.Err)
+		_, ok = <-ch
+		assert.False(t, ok)
+	})
+
+	t.Run("unexpected content type", func(t *testing.T) {
+		_, err := DecodeIncremental(context.Background(), io.NopCloser(strings.NewReader(body)), "application/json")
+		assert.Equal(t, UnexpectedContentTypeErr{"application/json"}, errors.Cause(err))
+	})
+}
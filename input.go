@@ -0,0 +1,92 @@
+package graphb
+
+import (
+	"github.com/pkg/errors"
+)
+
+// InputObjectBuilder builds an input object argument field by field, e.g.
+//
+//	InputObject("input").Set("title", "x").SetEnum("status", "DRAFT").SetList("tags", "a", "b").Argument()
+//
+// It is a more discoverable alternative to nesting ArgumentCustomType calls for large mutation inputs.
+// The first error met by a setter is kept in E and returned by Argument, later setters are no-ops.
+type InputObjectBuilder struct {
+	Name   string
+	Fields []Argument
+	E      error
+}
+
+// InputObject starts building the input object argument of the given name.
+func InputObject(name string) *InputObjectBuilder {
+	return &InputObjectBuilder{Name: name}
+}
+
+// Set sets the field of the given name to value, which is either a nested *InputObjectBuilder
+// or a value accepted by ArgumentAny.
+func (b *InputObjectBuilder) Set(name string, value interface{}) *InputObjectBuilder {
+	if b.E != nil {
+		return b
+	}
+	v, err := inputValue(name, value)
+	if err != nil {
+		b.E = errors.WithStack(err)
+		return b
+	}
+	b.Fields = append(b.Fields, Argument{name, v})
+	return b
+}
+
+// SetEnum sets the field of the given name to an enum value.
+func (b *InputObjectBuilder) SetEnum(name string, value string) *InputObjectBuilder {
+	if b.E != nil {
+		return b
+	}
+	b.Fields = append(b.Fields, ArgumentEnum(name, value))
+	return b
+}
+
+// SetList sets the field of the given name to a list of values, each of which is accepted by Set.
+func (b *InputObjectBuilder) SetList(name string, values ...interface{}) *InputObjectBuilder {
+	if b.E != nil {
+		return b
+	}
+	list := make(argList, len(values))
+	for i, value := range values {
+		v, err := inputValue(name, value)
+		if err != nil {
+			b.E = errors.WithStack(err)
+			return b
+		}
+		list[i] = v
+	}
+	b.Fields = append(b.Fields, Argument{name, list})
+	return b
+}
+
+// Argument returns the built input object argument, or the first error met while building it.
+func (b *InputObjectBuilder) Argument() (Argument, error) {
+	if b.E != nil {
+		return Argument{}, errors.WithStack(b.E)
+	}
+	for _, arg := range b.Fields {
+		if !validName.MatchString(arg.Name) {
+			return Argument{}, errors.WithStack(InvalidNameErr{argumentName, arg.Name})
+		}
+	}
+	return ArgumentCustomType(b.Name, b.Fields...), nil
+}
+
+func inputValue(name string, value interface{}) (argumentValue, error) {
+	if nested, ok := value.(*InputObjectBuilder); ok {
+		arg, err := nested.Argument()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return arg.Value, nil
+	}
+	arg, err := ArgumentAny(name, value)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return arg.Value, nil
+}
@@ -0,0 +1,44 @@
+package graphb
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInputObject(t *testing.T) {
+	arg, err := InputObject("input").
+		Set("title", "x").
+		SetEnum("status", "DRAFT").
+		SetList("tags", "a", "b").
+		Set("author", InputObject("").Set("id", 1)).
+		SetList("links", InputObject("").Set("url", "u"), InputObject("").Set("url", "v").Set("primary", true)).
+		Argument()
+	assert.Nil(t, err)
+	f := MakeField("createPost").SetArguments(arg)
+	assert.Equal(t, `createPost(input:{title:"x",status:DRAFT,tags:["a","b"],author:{id:1},links:[{url:"u"},{url:"v",primary:true}]})`,
+		StringFromChan(f.stringChan()))
+}
+
+func TestInputObject_errors(t *testing.T) {
+	b := InputObject("input").Set("ratio", int64(1)).Set("title", "x")
+	_, err := b.Argument()
+	assert.Equal(t, argumentTypeNotSupported("ratio", int64(1)), errors.Cause(err))
+	assert.Empty(t, b.Fields)
+
+	_, err = InputObject("input").SetList("tags", "a", int64(1)).Argument()
+	assert.Equal(t, argumentTypeNotSupported("tags", int64(1)), errors.Cause(err))
+
+	_, err = InputObject("input").Set("author", InputObject("").SetEnum("bad name", "A")).Argument()
+	assert.Equal(t, InvalidNameErr{argumentName, "bad name"}, errors.Cause(err))
+}
+
+func TestInputObject_listValuesAreVisible(t *testing.T) {
+	in, err := InputObject("in").SetList("users", InputObject("").Set("password", "hunter2")).Argument()
+	assert.Nil(t, err)
+	q := MakeQuery(TypeQuery).SetFields(MakeField("import").SetArguments(in))
+	s, err := q.StringRedacted("password")
+	assert.Nil(t, err)
+	assert.Equal(t, `query{import(in:{users:[{password:"<redacted>"}]})}`, s)
+}
@@ -0,0 +1,59 @@
+package graphb
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// ArgumentLong returns an argument of a 64-bit integer value, serialized as a string such as "9007199254740993",
+// which is how custom scalars like Long or BigInt usually accept values out of the range of Int.
+func ArgumentLong(name string, value int64) Argument {
+	return Argument{name, argLong(value)}
+}
+
+// argLong represents a 64-bit integer value.
+type argLong int64
+
+func (v argLong) tokenChan() <-chan Token {
+	tokenChan := make(chan Token)
+	go func() {
+		tokenChan <- Token{TokenString, `"` + strconv.FormatInt(int64(v), 10) + `"`}
+		close(tokenChan)
+	}()
+	return tokenChan
+}
+
+// checkIntRange checks the Int values of arguments, looking into input objects and lists, if c checks them.
+func checkIntRange(args []Argument, c *Config) error {
+	if c == nil || !c.CheckIntRange {
+		return nil
+	}
+	for _, arg := range args {
+		err := eachValue(arg.Value, func(value argumentValue) error {
+			switch v := value.(type) {
+			case argInt:
+				return checkInt32(arg.Name, int(v))
+			case argIntSlice:
+				for _, i := range v {
+					if err := checkInt32(arg.Name, i); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+func checkInt32(name string, i int) error {
+	if i < math.MinInt32 || i > math.MaxInt32 {
+		return IntOutOfRangeErr{name, i}
+	}
+	return nil
+}
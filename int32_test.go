@@ -0,0 +1,31 @@
+package graphb
+
+import (
+	"math"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArgumentLong(t *testing.T) {
+	arg := ArgumentLong("id", 9007199254740993)
+	assert.Equal(t, `id:"9007199254740993"`, StringFromChan(literals(arg.tokenChan())))
+}
+
+func TestWithIntRangeCheck(t *testing.T) {
+	big := MakeField("a").SetArguments(ArgumentCustomType("where", ArgumentIntSlice("ids", 1, math.MaxInt32+1)))
+	assert.Nil(t, big.check())
+
+	c := NewConfig(WithIntRangeCheck())
+	assert.Equal(t, IntOutOfRangeErr{"where", math.MaxInt32 + 1}, errors.Cause(big.checkWith(c)))
+	f := MakeField("a").SetArguments(ArgumentInt("min", math.MinInt32), ArgumentInt("max", math.MaxInt32), ArgumentLong("id", math.MaxInt64))
+	assert.Nil(t, f.checkWith(c))
+	f = MakeField("a").AddDirectives(MakeDirective("cost", ArgumentInt("weight", math.MinInt32-1)))
+	assert.Equal(t, IntOutOfRangeErr{"weight", math.MinInt32 - 1}, errors.Cause(f.checkWith(c)))
+
+	_, err := MakeQuery(TypeQuery, c).SetFields(big).JSON()
+	assert.Equal(t, IntOutOfRangeErr{"where", math.MaxInt32 + 1}, errors.Cause(err))
+	_, err = MakeQuery(TypeQuery).SetFields(big).JSON()
+	assert.Nil(t, err)
+}
@@ -0,0 +1,64 @@
+package graphb
+
+import (
+	"strconv"
+	"sync"
+)
+
+const (
+	// interned names are bounded in number and length, so that arbitrary documents can not grow the table forever.
+	internCapacity = 4096
+	internMaxLen   = 64
+
+	tokenTrue  = "true"
+	tokenFalse = "false"
+)
+
+// internTable caches names, which repeat a lot across the documents parsed by a process,
+// and the tokens the serializer makes of a prefix and a name, which repeat a lot across the queries it serializes.
+// Interning them also frees the source of a parsed document, which the names would otherwise keep in memory.
+// Argument values are never interned, they may be secrets and are rarely repeated.
+type internTable struct {
+	prefix string // Prepended to the names interned, e.g. "$" for variable tokens.
+	mu     sync.RWMutex
+	names  map[string]string // The interned strings by name.
+}
+
+var (
+	names           = &internTable{names: make(map[string]string)}
+	variableTokens  = &internTable{prefix: tokenDollar, names: make(map[string]string)}
+	directiveTokens = &internTable{prefix: tokenAt, names: make(map[string]string)}
+)
+
+// intern returns a string equal to the prefix of this table followed by name, reusing an earlier equal one if possible.
+func (t *internTable) intern(name string) string {
+	t.mu.RLock()
+	s, ok := t.names[name]
+	t.mu.RUnlock()
+	if ok {
+		return s
+	}
+
+	if len(name) > internMaxLen {
+		return t.prefix + name
+	}
+	s = string(append([]byte(t.prefix), name...)) // a copy, not a substring of the source
+	t.mu.Lock()
+	if len(t.names) < internCapacity {
+		t.names[s[len(t.prefix):]] = s
+	}
+	t.mu.Unlock()
+	return s
+}
+
+func boolLiteral(v bool) string {
+	if v {
+		return tokenTrue
+	}
+	return tokenFalse
+}
+
+// intLiteral formats v; strconv does not allocate for small integers.
+func intLiteral(v int) string {
+	return strconv.Itoa(v)
+}
@@ -0,0 +1,66 @@
+package graphb
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_internTable_intern(t *testing.T) {
+	table := &internTable{names: make(map[string]string)}
+	src := "user{id}"
+	assert.Equal(t, "user", table.intern(src[:4]))
+	assert.Equal(t, "user", table.intern("user"))
+	assert.Len(t, table.names, 1)
+
+	long := fmt.Sprintf("a%064d", 0)
+	assert.Equal(t, long, table.intern(long))
+	assert.Len(t, table.names, 1)
+
+	for i := 0; i < internCapacity+10; i++ {
+		table.intern(fmt.Sprint("n", i))
+	}
+	assert.Len(t, table.names, internCapacity)
+	assert.Equal(t, "x", table.intern("x"))
+}
+
+func Test_internTable_prefix(t *testing.T) {
+	table := &internTable{prefix: tokenDollar, names: make(map[string]string)}
+	assert.Equal(t, "$id", table.intern("id"))
+	assert.Equal(t, "$id", table.intern("id"))
+	assert.Equal(t, map[string]string{"id": "$id"}, table.names)
+
+	long := fmt.Sprintf("a%064d", 0)
+	assert.Equal(t, "$"+long, table.intern(long))
+	assert.Len(t, table.names, 1)
+}
+
+func Test_serializerInterning(t *testing.T) {
+	q := MakeQuery(TypeQuery).
+		AddVariables(Variable{Name: "internedVar", Type: "ID"}).
+		SetFields(MakeField("a").
+			SetArguments(ArgumentVariable("id", "internedVar")).
+			AddDirectives(MakeDirective("internedDirective", ArgumentBool("if", true))))
+	s, err := q.StringChan()
+	assert.Nil(t, err)
+	assert.Equal(t, "query($internedVar:ID){a(id:$internedVar)@internedDirective(if:true)}", StringFromChan(s))
+	assert.Equal(t, "$internedVar", variableTokens.names["internedVar"])
+	assert.Equal(t, "@internedDirective", directiveTokens.names["internedDirective"])
+}
+
+func Test_argumentValuesNotInterned(t *testing.T) {
+	before := len(names.names)
+	f := MakeField("login").SetArguments(ArgumentString("password", "hunter2"), ArgumentStringSlice("tokens", "t1"))
+	assert.Equal(t, `login(password:"hunter2",tokens:["t1"])`, StringFromChan(f.stringChan()))
+	assert.Equal(t, before, len(names.names))
+	for name := range names.names {
+		assert.NotEqual(t, "hunter2", name)
+	}
+}
+
+func Test_boolLiteral_intLiteral(t *testing.T) {
+	assert.Equal(t, "true", boolLiteral(true))
+	assert.Equal(t, "false", boolLiteral(false))
+	assert.Equal(t, "-12", intLiteral(-12))
+}
@@ -0,0 +1,115 @@
+package graphb
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// argTokens represents a value converted to tokens once, at construction.
+type argTokens []Token
+
+func (v argTokens) tokenChan() <-chan Token {
+	tokenChan := make(chan Token)
+	go func() {
+		for _, tok := range v {
+			tokenChan <- tok
+		}
+		close(tokenChan)
+	}()
+	return tokenChan
+}
+
+// jsonFrame is an object or an array being converted by jsonTokens.
+type jsonFrame struct {
+	object bool
+	n      int // the number of keys and values read so far
+}
+
+// jsonTokens converts a single JSON value to the tokens of the equivalent GraphQL literal, keeping the order of object keys.
+func jsonTokens(raw []byte) ([]Token, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var tokens []Token
+	var stack []*jsonFrame
+	done := false
+	for {
+		t, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.WithStack(InvalidJSONValueErr{Reason: err.Error()})
+		}
+		if done {
+			return nil, errors.WithStack(InvalidJSONValueErr{Reason: "more than one value"})
+		}
+
+		if d, ok := t.(json.Delim); ok && (d == '}' || d == ']') {
+			stack = stack[:len(stack)-1]
+			if d == '}' {
+				tokens = append(tokens, Token{TokenPunctuator, tokenRB})
+			} else {
+				tokens = append(tokens, Token{TokenPunctuator, tokenRSB})
+			}
+			done = len(stack) == 0
+			continue
+		}
+
+		if len(stack) > 0 {
+			top := stack[len(stack)-1]
+			if top.n > 0 && (!top.object || top.n%2 == 0) {
+				tokens = append(tokens, Token{TokenPunctuator, tokenComma})
+			}
+			top.n++
+			if top.object && top.n%2 == 1 {
+				key := t.(string)
+				if !validName.MatchString(key) {
+					return nil, errors.WithStack(InvalidNameErr{argumentName, key})
+				}
+				tokens = append(tokens, Token{TokenName, key}, Token{TokenPunctuator, tokenColumn})
+				continue
+			}
+		}
+
+		switch v := t.(type) {
+		case json.Delim:
+			if v == '{' {
+				tokens = append(tokens, Token{TokenPunctuator, tokenLB})
+			} else {
+				tokens = append(tokens, Token{TokenPunctuator, tokenLSB})
+			}
+			stack = append(stack, &jsonFrame{object: v == '{'})
+			continue
+		case bool:
+			tokens = append(tokens, Token{TokenBoolean, boolLiteral(v)})
+		case json.Number:
+			if strings.ContainsAny(string(v), ".eE") {
+				tokens = append(tokens, Token{TokenFloat, string(v)})
+			} else {
+				tokens = append(tokens, Token{TokenInt, string(v)})
+			}
+		case string:
+			tokens = append(tokens, Token{TokenString, jsonString(v)})
+		case nil:
+			tokens = append(tokens, Token{TokenNull, "null"})
+		}
+		done = len(stack) == 0
+	}
+	if !done {
+		return nil, errors.WithStack(InvalidJSONValueErr{Reason: "no value"})
+	}
+	return tokens, nil
+}
+
+// jsonString quotes s with JSON escapes, which GraphQL string values share.
+func jsonString(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s) // encoding a string never fails
+	return strings.TrimSuffix(buf.String(), "\n")
+}
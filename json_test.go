@@ -0,0 +1,47 @@
+package graphb
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArgumentJSON(t *testing.T) {
+	cases := []struct {
+		raw      string
+		expected string
+	}{
+		{`{"status": "OPEN", "tags": ["a", "b"], "archived": false}`, `filter:{status:"OPEN",tags:["a","b"],archived:false}`},
+		{`{"and": [{"price": {"gte": 10.5}}, {"rank": -3}]}`, `filter:{and:[{price:{gte:10.5}},{rank:-3}]}`},
+		{`[1, 2e3, null, [], {}]`, `filter:[1,2e3,null,[],{}]`},
+		{` "say \"hi\" <b>\n" `, `filter:"say \"hi\" <b>\n"`},
+		{`true`, `filter:true`},
+	}
+	for _, c := range cases {
+		arg, err := ArgumentAny("filter", json.RawMessage(c.raw))
+		assert.Nil(t, err, c.raw)
+		assert.Equal(t, c.expected, StringFromChan(literals(arg.tokenChan())))
+	}
+
+	arg, err := ArgumentJSON("filter", json.RawMessage(`{"n": 1.5}`))
+	assert.Nil(t, err)
+	var kinds []TokenKind
+	for tok := range arg.tokenChan() {
+		kinds = append(kinds, tok.Kind)
+	}
+	assert.Equal(t, TokenFloat, kinds[len(kinds)-2])
+}
+
+func TestArgumentJSON_errors(t *testing.T) {
+	_, err := ArgumentJSON("filter", json.RawMessage(`{"first-name": "a"}`))
+	assert.Equal(t, InvalidNameErr{argumentName, "first-name"}, errors.Cause(err))
+
+	for _, raw := range []string{``, `{"a":`, `1 2`, `{"a": 1}}`, `nope`} {
+		_, err := ArgumentJSON("filter", json.RawMessage(raw))
+		e, ok := errors.Cause(err).(InvalidJSONValueErr)
+		assert.True(t, ok, raw)
+		assert.Equal(t, "filter", e.Argument)
+	}
+}
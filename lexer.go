@@ -0,0 +1,247 @@
+package graphb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// lexeme is a token of a GraphQL document with its position, 1-based, for error messages.
+type lexeme struct {
+	Token
+	line   int
+	column int
+}
+
+// lexer splits a GraphQL document into tokens according to the spec: http://facebook.github.io/graphql/October2016/#sec-Source-Text
+// Ignored tokens, i.e. white space, line terminators, commas and comments, are dropped.
+// Names, including keywords, are TokenName; the kinds of other tokens follow their lexical grammar.
+type lexer struct {
+	src    string
+	pos    int
+	line   int
+	column int
+}
+
+func lex(src string) ([]lexeme, error) {
+	l := &lexer{src: strings.TrimPrefix(src, "\uFEFF"), line: 1, column: 1}
+	var lexemes []lexeme
+	for {
+		l.skipIgnored()
+		if l.pos >= len(l.src) {
+			return lexemes, nil
+		}
+		line, column := l.line, l.column
+		tok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		lexemes = append(lexemes, lexeme{tok, line, column})
+	}
+}
+
+func newSyntaxErr(line, column int, format string, args ...interface{}) error {
+	return errors.WithStack(SyntaxErr{line, column, fmt.Sprintf(format, args...)})
+}
+
+// advance moves past n bytes on the current line.
+func (l *lexer) advance(n int) {
+	l.pos += n
+	l.column += n
+}
+
+func (l *lexer) skipIgnored() {
+	for l.pos < len(l.src) {
+		switch c := l.src[l.pos]; c {
+		case ' ', '\t', ',':
+			l.advance(1)
+		case '\n':
+			l.newLine(1)
+		case '\r':
+			if strings.HasPrefix(l.src[l.pos:], "\r\n") {
+				l.newLine(2)
+			} else {
+				l.newLine(1)
+			}
+		case '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' && l.src[l.pos] != '\r' {
+				l.advance(1)
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (l *lexer) newLine(n int) {
+	l.pos += n
+	l.line++
+	l.column = 1
+}
+
+func (l *lexer) next() (Token, error) {
+	c := l.src[l.pos]
+	switch {
+	case strings.IndexByte("!$():=@[]{|}&", c) >= 0:
+		l.advance(1)
+		return Token{TokenPunctuator, string(c)}, nil
+	case c == '.':
+		if !strings.HasPrefix(l.src[l.pos:], "...") {
+			return Token{}, newSyntaxErr(l.line, l.column, "unexpected '.', did you mean '...'?")
+		}
+		l.advance(3)
+		return Token{TokenPunctuator, "..."}, nil
+	case isNameStart(c):
+		start := l.pos
+		for l.pos < len(l.src) && isNameContinue(l.src[l.pos]) {
+			l.advance(1)
+		}
+		return Token{TokenName, names.intern(l.src[start:l.pos])}, nil
+	case c == '-' || isDigit(c):
+		return l.number()
+	case c == '"':
+		if strings.HasPrefix(l.src[l.pos:], `"""`) {
+			return l.blockString()
+		}
+		return l.string()
+	}
+	return Token{}, newSyntaxErr(l.line, l.column, "unexpected character %q", l.runeAt())
+}
+
+func (l *lexer) runeAt() rune {
+	for _, r := range l.src[l.pos:] {
+		return r
+	}
+	return 0
+}
+
+// number lexes an IntValue or a FloatValue.
+func (l *lexer) number() (Token, error) {
+	line, column := l.line, l.column
+	start := l.pos
+	if l.src[l.pos] == '-' {
+		l.advance(1)
+	}
+	if l.pos < len(l.src) && l.src[l.pos] == '0' {
+		l.advance(1)
+		if l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+			return Token{}, newSyntaxErr(line, column, "invalid number, unexpected digit after 0")
+		}
+	} else if err := l.digits(line, column); err != nil {
+		return Token{}, err
+	}
+	kind := TokenInt
+	if l.pos < len(l.src) && l.src[l.pos] == '.' {
+		kind = TokenFloat
+		l.advance(1)
+		if err := l.digits(line, column); err != nil {
+			return Token{}, err
+		}
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+		kind = TokenFloat
+		l.advance(1)
+		if l.pos < len(l.src) && (l.src[l.pos] == '+' || l.src[l.pos] == '-') {
+			l.advance(1)
+		}
+		if err := l.digits(line, column); err != nil {
+			return Token{}, err
+		}
+	}
+	if l.pos < len(l.src) && (isNameStart(l.src[l.pos]) || l.src[l.pos] == '.') {
+		return Token{}, newSyntaxErr(line, column, "invalid number, unexpected %q", l.runeAt())
+	}
+	return Token{kind, l.src[start:l.pos]}, nil
+}
+
+func (l *lexer) digits(line, column int) error {
+	if l.pos >= len(l.src) || !isDigit(l.src[l.pos]) {
+		return newSyntaxErr(line, column, "invalid number, expected a digit")
+	}
+	for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+		l.advance(1)
+	}
+	return nil
+}
+
+// string lexes a StringValue, validating its escape sequences.
+func (l *lexer) string() (Token, error) {
+	line, column := l.line, l.column
+	start := l.pos
+	l.advance(1)
+	for l.pos < len(l.src) {
+		switch c := l.src[l.pos]; c {
+		case '"':
+			l.advance(1)
+			return Token{TokenString, l.src[start:l.pos]}, nil
+		case '\n', '\r':
+			return Token{}, newSyntaxErr(line, column, "unterminated string")
+		case '\\':
+			if l.pos+1 >= len(l.src) {
+				return Token{}, newSyntaxErr(line, column, "unterminated string")
+			}
+			switch e := l.src[l.pos+1]; e {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+				l.advance(2)
+			case 'u':
+				if l.pos+6 > len(l.src) || !isHex(l.src[l.pos+2:l.pos+6]) {
+					return Token{}, newSyntaxErr(l.line, l.column, "invalid unicode escape sequence")
+				}
+				l.advance(6)
+			default:
+				return Token{}, newSyntaxErr(l.line, l.column, "invalid escape sequence '\\%c'", e)
+			}
+		default:
+			l.advance(1)
+		}
+	}
+	return Token{}, newSyntaxErr(line, column, "unterminated string")
+}
+
+// blockString lexes a block string, whose raw content is kept as is, line terminators included.
+func (l *lexer) blockString() (Token, error) {
+	line, column := l.line, l.column
+	start := l.pos
+	l.advance(3)
+	for l.pos < len(l.src) {
+		switch {
+		case strings.HasPrefix(l.src[l.pos:], `\"""`):
+			l.advance(4)
+		case strings.HasPrefix(l.src[l.pos:], `"""`):
+			l.advance(3)
+			return Token{TokenString, l.src[start:l.pos]}, nil
+		case l.src[l.pos] == '\n':
+			l.newLine(1)
+		case strings.HasPrefix(l.src[l.pos:], "\r\n"):
+			l.newLine(2)
+		case l.src[l.pos] == '\r':
+			l.newLine(1)
+		default:
+			l.advance(1)
+		}
+	}
+	return Token{}, newSyntaxErr(line, column, "unterminated block string")
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z')
+}
+
+func isNameContinue(c byte) bool {
+	return isNameStart(c) || isDigit(c)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !isDigit(c) && !(c >= 'a' && c <= 'f') && !(c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package graphb
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// StringMax serializes this Query like StringChan, but stops with ErrQueryTooLarge as soon as the query exceeds maxBytes,
+// so that a service enforcing a payload limit rejects a huge query without serializing all of it.
+// Queries are serialized one top level field at a time, which stops at the field crossing the limit,
+// the rest of which is discarded in the background. Queries styled other than StyleCompact by their Config are serialized as a whole.
+func (q *Query) StringMax(maxBytes int) (string, error) {
+	if err := q.checkAll(); err != nil {
+		return "", errors.WithStack(err)
+	}
+	b := &limitedBuilder{max: maxBytes}
+	if q.Config != nil && q.Config.Style != StyleCompact {
+		// the white space of a style depends on the tokens preceding each field
+		if !b.writeChan(q.stringChan()) {
+			return "", errors.WithStack(ErrQueryTooLarge)
+		}
+		return b.String(), nil
+	}
+
+	fields, defs := q.fieldsAndFragments()
+	if !b.write(collect(q.emitHeader)) {
+		return "", errors.WithStack(ErrQueryTooLarge)
+	}
+	for i, f := range fields {
+		if i != 0 && !b.write(tokenComma) {
+			return "", errors.WithStack(ErrQueryTooLarge)
+		}
+		if !b.writeChan(f.stringChan()) {
+			return "", errors.WithStack(ErrQueryTooLarge)
+		}
+	}
+	if !b.write(collect(q.emitFooter)) {
+		return "", errors.WithStack(ErrQueryTooLarge)
+	}
+	for _, def := range defs {
+		if !b.writeChan(literals(def.tokenChan())) {
+			return "", errors.WithStack(ErrQueryTooLarge)
+		}
+	}
+	return b.String(), nil
+}
+
+// limitedBuilder builds a string of up to max bytes.
+type limitedBuilder struct {
+	strings.Builder
+	max int
+}
+
+// write writes s and reports whether it fits.
+func (b *limitedBuilder) write(s string) bool {
+	if b.Len()+len(s) > b.max {
+		return false
+	}
+	b.WriteString(s)
+	return true
+}
+
+// writeChan writes the strings of c and reports whether they fit.
+// Once one does not, the rest of c is discarded in the background, so that the goroutines sending it end.
+func (b *limitedBuilder) writeChan(c <-chan string) bool {
+	for s := range c {
+		if !b.write(s) {
+			go func() {
+				for range c {
+				}
+			}()
+			return false
+		}
+	}
+	return true
+}
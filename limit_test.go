@@ -0,0 +1,37 @@
+package graphb
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery_StringMax(t *testing.T) {
+	r := NewFragmentRegistry()
+	assert.Nil(t, r.RegisterFragment("UserCard", "User", Fields("id", "name")...))
+	q := NewQuery(TypeQuery, OfFragmentDefinitions(),
+		OfField("me", OfSpread(r, "UserCard")),
+		OfField("posts", OfArguments(ArgumentInt("first", 10)), OfFields("title")),
+	)
+	assert.Nil(t, q.E)
+	expected := "query{me{...UserCard},posts(first:10){title}}fragment UserCard on User{id,name}"
+
+	s, err := q.StringMax(len(expected))
+	assert.Nil(t, err)
+	assert.Equal(t, expected, s)
+	for _, max := range []int{0, 5, 20, len(expected) - 30, len(expected) - 1} {
+		_, err = q.StringMax(max)
+		assert.Equal(t, ErrQueryTooLarge, errors.Cause(err), max)
+	}
+
+	q.Config = NewConfig(WithSerializerStyle(StyleSpaced))
+	s, err = q.StringMax(1 << 10)
+	assert.Nil(t, err)
+	assert.Equal(t, StringFromChan(q.stringChan()), s)
+	_, err = q.StringMax(len(s) - 1)
+	assert.Equal(t, ErrQueryTooLarge, errors.Cause(err))
+
+	_, err = MakeQuery(TypeQuery).SetFields(nil).StringMax(1 << 10)
+	assert.Equal(t, NilFieldErr{}, errors.Cause(err))
+}
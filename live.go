@@ -0,0 +1,66 @@
+package graphb
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Live returns the @live directive of the operations whose results servers supporting live queries push as they change,
+// e.g. MakeQuery(TypeQuery).AddDirectives(Live()). See Poll for servers without live queries.
+func Live() Directive {
+	return MakeDirective("live")
+}
+
+// Poll is the fallback of live queries for servers without them. It executes q without its @live directive
+// right away and then every interval until ctx is done, and sends a result to the returned channel whenever
+// it differs from the previous one, starting with the first one, like a subscription.
+// The results after the first one come with their Changes from the previous one.
+// An error is sent as a SubscriptionPayload with Err and polling goes on, so that a consumer can ride out transient failures.
+// The channel is closed once ctx is done.
+func Poll(ctx context.Context, q *Query, interval time.Duration, execute ExecuteFunc) <-chan SubscriptionPayload {
+	polled := withCopiedHeaders(q)
+	polled.Directives = nil
+	for _, d := range q.Directives {
+		if d.Name != "live" {
+			polled.Directives = append(polled.Directives, d)
+		}
+	}
+
+	payloads := make(chan SubscriptionPayload)
+	go func() {
+		defer close(payloads)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		var last json.RawMessage
+		for {
+			var payload SubscriptionPayload
+			data, err := execute(ctx, polled)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				payload.Err = err
+			} else if last == nil {
+				last, payload.Data = data, data
+			} else if changes, err := DiffResults(last, data); err != nil {
+				payload.Err = err
+			} else if len(changes) > 0 {
+				last, payload.Data, payload.Changes = data, data, changes
+			}
+			if payload.Err != nil || payload.Data != nil {
+				select {
+				case payloads <- payload:
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return payloads
+}
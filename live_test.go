@@ -0,0 +1,56 @@
+package graphb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLive(t *testing.T) {
+	q := MakeQuery(TypeQuery).SetName("Likes").AddDirectives(Live()).SetFields(MakeField("likes"))
+	s, err := q.JSON()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"query":"query Likes@live{likes}"}`, s)
+
+	d, err := ParseDocument(`query Likes @live { likes }`)
+	assert.Nil(t, err)
+	assert.Equal(t, []Directive{Live()}, d.Operations[0].Directives)
+
+	_, err = MakeQuery(TypeQuery).AddDirectives(MakeDirective("live", ArgumentVariable("throttle", "ms"))).SetFields(MakeField("likes")).JSON()
+	assert.Equal(t, UndefinedVariableErr{"ms"}, errors.Cause(err))
+}
+
+func TestPoll(t *testing.T) {
+	results := []string{`{"likes":1}`, `{ "likes": 1 }`, "", `{"likes":2}`}
+	calls := 0
+	var executed []string
+	execute := func(ctx context.Context, q *Query) (json.RawMessage, error) {
+		s, _ := q.StringChan()
+		executed = append(executed, StringFromChan(s))
+		result := results[calls%len(results)]
+		calls++
+		if result == "" {
+			return nil, fmt.Errorf("down")
+		}
+		return json.RawMessage(result), nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	q := MakeQuery(TypeQuery).AddDirectives(Live()).SetFields(MakeField("likes"))
+	payloads := Poll(ctx, q, time.Millisecond, execute)
+
+	assert.Equal(t, json.RawMessage(`{"likes":1}`), (<-payloads).Data)
+	assert.EqualError(t, (<-payloads).Err, "down", "an unchanged result is not sent")
+	changed := <-payloads
+	assert.Equal(t, json.RawMessage(`{"likes":2}`), changed.Data)
+	assert.Equal(t, []PatchOperation{{Op: "replace", Path: "/likes", Value: json.RawMessage(`2`)}}, changed.Changes)
+	cancel()
+	for range payloads {
+	}
+	assert.Equal(t, "query{likes}", executed[0], "without @live")
+	assert.Len(t, q.Directives, 1, "the query is not modified")
+}
@@ -0,0 +1,82 @@
+package graphb
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// SourceLocation is a position in Go source code.
+type SourceLocation struct {
+	File string
+	Line int
+}
+
+func (l SourceLocation) String() string {
+	return fmt.Sprintf("%s:%d", l.File, l.Line)
+}
+
+// packageDir is the directory of graphb's own source files, whose frames are skipped by callerLocation.
+var packageDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+// TrackLocation makes this Field record where TrackLocation and, from then on, its argument setters are called from,
+// so that validation errors and Query.FieldForPath lead back to the Go code which built it, e.g. MakeField("user").TrackLocation().
+// It costs a stack walk per call, use it for debugging.
+func (f *Field) TrackLocation() *Field {
+	f.tracked = true
+	f.Location = callerLocation()
+	return f
+}
+
+// OfLocation returns a FieldOption which makes the targeting field track its location, see Field.TrackLocation.
+func OfLocation() FieldOption {
+	return func(f *Field) error {
+		f.tracked = true
+		f.Location = callerLocation()
+		return nil
+	}
+}
+
+// locateArguments records where the arguments of this Field are set, if it tracks its location.
+func (f *Field) locateArguments() {
+	if f.tracked {
+		f.ArgumentsLocation = callerLocation()
+	}
+}
+
+// callerLocation returns the location of the first caller outside of graphb.
+func callerLocation() *SourceLocation {
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if filepath.Dir(frame.File) != packageDir || strings.HasSuffix(frame.File, "_test.go") {
+			return &SourceLocation{frame.File, frame.Line}
+		}
+		if !more {
+			return nil
+		}
+	}
+}
+
+// located annotates err with where this Field was built and where its arguments were set, if known.
+func (f *Field) located(err error) error {
+	var at []string
+	if f.Location != nil {
+		at = append(at, "built at "+f.Location.String())
+	}
+	if f.ArgumentsLocation != nil {
+		at = append(at, "arguments set at "+f.ArgumentsLocation.String())
+	}
+	if len(at) == 0 {
+		return err
+	}
+	return errors.Wrapf(err, "field '%s' %s", f.Name, strings.Join(at, ", "))
+}
@@ -0,0 +1,41 @@
+package graphb
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestField_TrackLocation(t *testing.T) {
+	t.Run("off", func(t *testing.T) {
+		f := MakeField("a").SetArguments(ArgumentInt("b", 1))
+		assert.Nil(t, f.Location)
+		assert.Nil(t, f.ArgumentsLocation)
+	})
+
+	t.Run("on", func(t *testing.T) {
+		_, file, line, _ := runtime.Caller(0)
+		f := MakeField("a").TrackLocation()
+		f.AddArguments(ArgumentInt("b-c", 1))
+		assert.Equal(t, &SourceLocation{file, line + 1}, f.Location)
+		assert.Equal(t, &SourceLocation{file, line + 2}, f.ArgumentsLocation)
+
+		_, err := MakeQuery(TypeQuery).SetFields(MakeField("x").SetFields(f)).JSON()
+		assert.IsType(t, InvalidNameErr{}, errors.Cause(err))
+		assert.Contains(t, err.Error(), fmt.Sprintf("field 'a' built at %s:%d, arguments set at %s:%d: ", file, line+1, file, line+2))
+
+		q := NewQuery(TypeQuery, OfField("n", OfLocation(), OfArguments(ArgumentInt("i", 1))))
+		assert.Equal(t, file, q.Fields[0].Location.File)
+		assert.Equal(t, file, q.Fields[0].ArgumentsLocation.File)
+
+		// other fields are not tracked
+		assert.Nil(t, MakeField("x").SetArguments(ArgumentInt("i", 1)).ArgumentsLocation)
+	})
+}
+
+func TestSourceLocation_String(t *testing.T) {
+	assert.Equal(t, "a.go:1", SourceLocation{"a.go", 1}.String())
+}
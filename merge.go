@@ -0,0 +1,68 @@
+package graphb
+
+import (
+	"github.com/pkg/errors"
+)
+
+// MergePolicy is how MergeArguments resolves arguments of the same name.
+type MergePolicy int
+
+const (
+	MergeError     MergePolicy = iota // Return a DuplicateArgumentErr.
+	MergeLastWins                     // Keep the argument of the last set, at the position of the first.
+	MergeFirstWins                    // Keep the argument of the first set.
+)
+
+// MergeArguments layers sets of arguments, e.g. the defaults of a field and the arguments of a caller,
+// into arguments of distinct names, in the order their names first appear, resolving duplicates following policy.
+func MergeArguments(policy MergePolicy, sets ...[]Argument) ([]Argument, error) {
+	var merged []Argument
+	indices := make(map[string]int)
+	for _, args := range sets {
+		for _, arg := range args {
+			i, ok := indices[arg.Name]
+			if !ok {
+				indices[arg.Name] = len(merged)
+				merged = append(merged, arg)
+				continue
+			}
+			switch policy {
+			case MergeError:
+				return nil, errors.WithStack(DuplicateArgumentErr{arg.Name})
+			case MergeLastWins:
+				merged[i] = arg
+			}
+		}
+	}
+	return merged, nil
+}
+
+// checkDuplicateArguments checks that no two arguments of args, nor two fields of their input objects, share a name.
+func checkDuplicateArguments(args []Argument) error {
+	if err := checkDistinctNames(args); err != nil {
+		return errors.WithStack(err)
+	}
+	for _, arg := range args {
+		err := eachValue(arg.Value, func(value argumentValue) error {
+			if v, ok := value.(argumentCustom); ok {
+				return checkDistinctNames(v)
+			}
+			return nil
+		})
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+func checkDistinctNames(args []Argument) error {
+	names := make(map[string]bool, len(args))
+	for _, arg := range args {
+		if names[arg.Name] {
+			return DuplicateArgumentErr{arg.Name}
+		}
+		names[arg.Name] = true
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package graphb
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeArguments(t *testing.T) {
+	defaults := []Argument{ArgumentInt("first", 10), ArgumentEnum("orderBy", "NEWEST")}
+	given := []Argument{ArgumentString("after", "abc"), ArgumentInt("first", 20)}
+
+	_, err := MergeArguments(MergeError, defaults, given)
+	assert.Equal(t, DuplicateArgumentErr{"first"}, errors.Cause(err))
+
+	merged, err := MergeArguments(MergeLastWins, defaults, given)
+	assert.Nil(t, err)
+	assert.Equal(t, []Argument{ArgumentInt("first", 20), ArgumentEnum("orderBy", "NEWEST"), ArgumentString("after", "abc")}, merged)
+
+	merged, err = MergeArguments(MergeFirstWins, defaults, given)
+	assert.Nil(t, err)
+	assert.Equal(t, []Argument{ArgumentInt("first", 10), ArgumentEnum("orderBy", "NEWEST"), ArgumentString("after", "abc")}, merged)
+
+	merged, err = MergeArguments(MergeError, defaults[:1], given[:1])
+	assert.Nil(t, err)
+	assert.Len(t, merged, 2)
+}
+
+func TestCheckDuplicateArguments(t *testing.T) {
+	_, err := MakeQuery(TypeQuery).SetFields(MakeField("posts").SetArguments(ArgumentInt("first", 1), ArgumentInt("first", 2))).StringChan()
+	assert.Equal(t, DuplicateArgumentErr{"first"}, errors.Cause(err))
+
+	_, err = MakeQuery(TypeQuery).SetFields(MakeField("posts").SetArguments(
+		ArgumentSlice("where", []Argument{ArgumentInt("id", 1), ArgumentInt("id", 2)}),
+	)).StringChan()
+	assert.Equal(t, DuplicateArgumentErr{"id"}, errors.Cause(err))
+
+	_, err = MakeQuery(TypeQuery).SetFields(MakeField("posts").AddDirectives(
+		MakeDirective("include", ArgumentBool("if", true), ArgumentBool("if", false)),
+	)).StringChan()
+	assert.Equal(t, DuplicateArgumentErr{"if"}, errors.Cause(err))
+
+	_, err = MakeQuery(TypeQuery).SetFields(MakeField("posts").SetArguments(
+		ArgumentCustomType("a", ArgumentInt("id", 1)), ArgumentCustomType("b", ArgumentInt("id", 1)),
+	)).StringChan()
+	assert.Nil(t, err)
+}
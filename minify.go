@@ -0,0 +1,36 @@
+package graphb
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Minify returns the GraphQL document doc without comments and insignificant white space and commas,
+// e.g. to send a hand-written .graphql file efficiently. doc is checked against the grammar of executable documents.
+// Block strings are kept as they are.
+func Minify(doc string) (string, error) {
+	lexemes, err := lex(doc)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	p := &parser{lexemes: lexemes, syntaxOnly: true}
+	if _, err := p.parseDocument(); err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	var b strings.Builder
+	for i, l := range lexemes {
+		if i > 0 && isWordLike(lexemes[i-1].Literal) && isWordLike(l.Literal) {
+			b.WriteString(tokenSpace)
+		}
+		b.WriteString(l.Literal)
+	}
+	return b.String(), nil
+}
+
+// isWordLike reports whether a token is a name or a number, which two have to be separated from each other.
+func isWordLike(literal string) bool {
+	c := literal[0]
+	return isNameContinue(c) || c == '-'
+}
@@ -0,0 +1,65 @@
+package graphb
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMinify(t *testing.T) {
+	doc := `
+# fetches a user
+query User($id: ID!, $size: Int = 64) @cached {
+  user(id: $id) {
+    id, # the id
+    name
+    avatar(size: $size, shape: ROUND, tags: ["a", "b"], where: {min: -1.5e3, max: 0})
+    ... on Admin { level }
+    ...Extra @include(if: true)
+    ... @skip(if: false) { note(text: """a
+  "block" \""" string""") }
+  }
+}
+
+fragment Extra on User {
+  friends(first: 10) { id }
+}
+`
+	s, err := Minify(doc)
+	assert.Nil(t, err)
+	assert.Equal(t, `query User($id:ID!$size:Int=64)@cached{user(id:$id){id name avatar(size:$size shape:ROUND tags:["a""b"]where:{min:-1.5e3 max:0})...on Admin{level}...Extra@include(if:true)...@skip(if:false){note(text:"""a
+  "block" \""" string""")}}}fragment Extra on User{friends(first:10){id}}`, s)
+
+	again, err := Minify(s)
+	assert.Nil(t, err)
+	assert.Equal(t, s, again)
+
+	s, err = Minify("{ a }")
+	assert.Nil(t, err)
+	assert.Equal(t, "{a}", s)
+}
+
+func TestMinify_syntaxErrors(t *testing.T) {
+	cases := []struct {
+		doc string
+		err SyntaxErr
+	}{
+		{"", SyntaxErr{1, 1, "unexpected end of document, expected an operation or a fragment definition"}},
+		{"{ a", SyntaxErr{1, 4, "unexpected end of document, expected a Name"}},
+		{"query {\n  a(x: $y)\n  b(x:)\n}", SyntaxErr{3, 7, "unexpected ')', expected a value"}},
+		{"type User { id: ID }", SyntaxErr{1, 1, "unexpected 'type', expected an operation or a fragment definition"}},
+		{"fragment on on User { a }", SyntaxErr{1, 10, "unexpected 'on', expected a fragment name"}},
+		{"query($a: Int = $b) { a }", SyntaxErr{1, 17, "unexpected '$', expected a value"}},
+		{"{ a(x: 01) }", SyntaxErr{1, 8, "invalid number, unexpected digit after 0"}},
+		{"{ a(x: 1a) }", SyntaxErr{1, 8, "invalid number, unexpected 'a'"}},
+		{"{ a(x: \"b\n\") }", SyntaxErr{1, 8, "unterminated string"}},
+		{`{ a(x: "\q") }`, SyntaxErr{1, 9, `invalid escape sequence '\q'`}},
+		{"{ a. }", SyntaxErr{1, 4, "unexpected '.', did you mean '...'?"}},
+		{"{ a ? }", SyntaxErr{1, 5, "unexpected character '?'"}},
+	}
+	for _, c := range cases {
+		_, err := Minify(c.doc)
+		assert.Equal(t, c.err, errors.Cause(err), c.doc)
+	}
+}
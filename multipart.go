@@ -0,0 +1,122 @@
+package graphb
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// AcceptMultipartSubscription is the Accept header of a subscription over multipart HTTP,
+// which also accepts a single JSON result, e.g. a request error.
+const AcceptMultipartSubscription = `multipart/mixed;subscriptionSpec="1.0", ` + ContentTypeJSON
+
+// MultipartClient executes subscriptions with the multipart HTTP protocol of Apollo Router and Apollo Server,
+// where the response to a POST request is a multipart/mixed stream whose parts are the results of the subscription.
+type MultipartClient struct {
+	Endpoint string
+	Client   *http.Client    // http.DefaultClient if nil.
+	Options  []RequestOption // Options of every request, e.g. authorization headers.
+	// Buffer is the number of results a subscription buffers for a slow consumer, and Overflow what it does once they are buffered.
+	// By default, no result is buffered and a slow consumer holds back the stream.
+	Buffer   int
+	Overflow OverflowPolicy
+}
+
+// multipartPart is a part of the multipart stream: a heartbeat if empty, a result in Payload,
+// or errors of the transport ending the subscription.
+type multipartPart struct {
+	Payload *SubscriptionPayload `json:"payload"`
+	Errors  json.RawMessage      `json:"errors"`
+}
+
+// Subscribe executes the subscription q and returns the channel of its results,
+// which is closed when the server ends the stream, the stream fails, or ctx is done.
+// Errors ending the subscription are sent as a last payload carrying them, whose Err is a MultipartSubscriptionErr.
+func (c *MultipartClient) Subscribe(ctx context.Context, q *Query) (<-chan SubscriptionPayload, error) {
+	options := append(append([]RequestOption(nil), c.Options...), WithRequestHeader("Accept", AcceptMultipartSubscription))
+	req, err := q.NewRequest(ctx, http.MethodPost, c.Endpoint, options...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, errors.WithStack(UnexpectedStatusErr{resp.StatusCode})
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	payloads := newPayloads(c.Buffer, c.Overflow)
+	switch {
+	case mediaType == ContentTypeJSON || mediaType == ContentTypeGraphQLResponse:
+		go func() {
+			defer close(payloads)
+			defer resp.Body.Close()
+			var payload SubscriptionPayload
+			if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+				payload = SubscriptionPayload{Err: errors.WithStack(err)}
+			}
+			deliverPayload(ctx, payloads, payload, c.Overflow)
+		}()
+	case mediaType == "multipart/mixed" && params["boundary"] != "":
+		go func() {
+			defer close(payloads)
+			defer resp.Body.Close()
+			if err := c.readParts(ctx, multipart.NewReader(resp.Body, params["boundary"]), payloads); err != nil && ctx.Err() == nil {
+				select {
+				case payloads <- SubscriptionPayload{Err: err}:
+				case <-ctx.Done():
+				}
+			}
+		}()
+	default:
+		resp.Body.Close()
+		return nil, errors.WithStack(UnexpectedContentTypeErr{contentType})
+	}
+	return payloads, nil
+}
+
+// readParts delivers the results of the parts of reader until the stream ends,
+// and returns why it ended unless the server closed it.
+func (c *MultipartClient) readParts(ctx context.Context, reader *multipart.Reader, payloads chan SubscriptionPayload) error {
+	for {
+		p, err := reader.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		var part multipartPart
+		err = json.NewDecoder(p).Decode(&part)
+		p.Close()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		switch {
+		case len(part.Errors) > 0 && string(part.Errors) != "null":
+			payload := SubscriptionPayload{Errors: part.Errors, Err: errors.WithStack(MultipartSubscriptionErr{part.Errors})}
+			if err := deliverPayload(ctx, payloads, payload, c.Overflow); err != nil {
+				return errors.WithStack(err)
+			}
+			return nil
+		case part.Payload != nil:
+			if err := deliverPayload(ctx, payloads, *part.Payload, c.Overflow); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		// a part without payload nor errors is a heartbeat
+	}
+}
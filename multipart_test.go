@@ -0,0 +1,124 @@
+package graphb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeParts writes the multipart subscription stream of parts.
+func writeParts(w http.ResponseWriter, parts ...string) {
+	mw := multipart.NewWriter(w)
+	mw.SetBoundary("graphql")
+	w.Header().Set("Content-Type", `multipart/mixed;boundary="graphql";subscriptionSpec="1.0"`)
+	for _, part := range parts {
+		pw, _ := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {ContentTypeJSON}})
+		io.WriteString(pw, part)
+	}
+	mw.Close()
+}
+
+func TestMultipartClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, AcceptMultipartSubscription, r.Header.Get("Accept"))
+		assert.Equal(t, "Bearer t", r.Header.Get("Authorization"))
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, `{"query":"subscription{likes}"}`, string(body))
+		writeParts(w,
+			`{}`,
+			`{"payload":{"data":{"likes":1}}}`,
+			`{}`,
+			`{"payload":{"data":{"likes":2},"errors":[{"message":"partial"}]}}`,
+		)
+	}))
+	defer server.Close()
+
+	c := &MultipartClient{Endpoint: server.URL, Options: []RequestOption{WithRequestHeader("Authorization", "Bearer t")}}
+	payloads, err := c.Subscribe(context.Background(), MakeQuery(TypeSubscription).SetFields(MakeField("likes")))
+	assert.Nil(t, err)
+	assert.Equal(t, []SubscriptionPayload{
+		{Data: json.RawMessage(`{"likes":1}`)},
+		{Data: json.RawMessage(`{"likes":2}`), Errors: json.RawMessage(`[{"message":"partial"}]`)},
+	}, collectPayloads(payloads))
+}
+
+func TestMultipartClient_errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/transport":
+			writeParts(w, `{"payload":{"data":{"likes":1}}}`, `{"payload":null,"errors":[{"message":"subgraph lost"}]}`, `{"payload":{"data":{"likes":2}}}`)
+		case "/json":
+			w.Header().Set("Content-Type", ContentTypeGraphQLResponse)
+			fmt.Fprint(w, `{"errors":[{"message":"invalid"}]}`)
+		case "/text":
+			w.Header().Set("Content-Type", "text/plain")
+		case "/cut":
+			w.Header().Set("Content-Type", `multipart/mixed;boundary="graphql"`)
+			fmt.Fprint(w, "--graphql\r\nContent-Type: application/json\r\n\r\n{\"payload\":{\"data\":{\"likes\":1}}}\r\n")
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+	q := MakeQuery(TypeSubscription).SetFields(MakeField("likes"))
+
+	_, err := (&MultipartClient{Endpoint: server.URL}).Subscribe(context.Background(), q)
+	assert.Equal(t, UnexpectedStatusErr{http.StatusBadRequest}, errors.Cause(err))
+	_, err = (&MultipartClient{Endpoint: server.URL + "/text"}).Subscribe(context.Background(), q)
+	assert.Equal(t, UnexpectedContentTypeErr{"text/plain"}, errors.Cause(err))
+
+	payloads, err := (&MultipartClient{Endpoint: server.URL + "/transport"}).Subscribe(context.Background(), q)
+	assert.Nil(t, err)
+	all := collectPayloads(payloads)
+	assert.Len(t, all, 2)
+	assert.Equal(t, json.RawMessage(`{"likes":1}`), all[0].Data)
+	assert.Equal(t, json.RawMessage(`[{"message":"subgraph lost"}]`), all[1].Errors)
+	assert.Equal(t, MultipartSubscriptionErr{json.RawMessage(`[{"message":"subgraph lost"}]`)}, errors.Cause(all[1].Err))
+
+	payloads, err = (&MultipartClient{Endpoint: server.URL + "/json"}).Subscribe(context.Background(), q)
+	assert.Nil(t, err)
+	assert.Equal(t, []SubscriptionPayload{{Errors: json.RawMessage(`[{"message":"invalid"}]`)}}, collectPayloads(payloads))
+
+	payloads, err = (&MultipartClient{Endpoint: server.URL + "/cut"}).Subscribe(context.Background(), q)
+	assert.Nil(t, err)
+	all = collectPayloads(payloads)
+	assert.Len(t, all, 2)
+	assert.Equal(t, json.RawMessage(`{"likes":1}`), all[0].Data)
+	assert.NotNil(t, all[1].Err)
+}
+
+func TestMultipartClient_cancel(t *testing.T) {
+	next := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", `multipart/mixed;boundary="graphql"`)
+		for i := 1; ; i++ {
+			fmt.Fprintf(w, "--graphql\r\nContent-Type: application/json\r\n\r\n{\"payload\":{\"data\":{\"likes\":%d}}}\r\n", i)
+			w.(http.Flusher).Flush()
+			select {
+			case <-next:
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	payloads, err := (&MultipartClient{Endpoint: server.URL}).Subscribe(ctx, MakeQuery(TypeSubscription).SetFields(MakeField("likes")))
+	assert.Nil(t, err)
+	next <- struct{}{}
+	assert.Equal(t, json.RawMessage(`{"likes":1}`), (<-payloads).Data)
+	cancel()
+	for range payloads {
+	}
+}
@@ -0,0 +1,188 @@
+package graphb
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Normalize replaces the selection sets repeated in this Query by the spreads of fragments defining them once,
+// and makes the Query serialized with fragment definitions, see OfFragmentDefinitions, which shortens queries repeating large selections.
+// A fragment applies to a type: typeOf returns the type of the field at path, the names of the fields from the root
+// such as "... on User" for an inline fragment, e.g. looked up in a schema. The selections of the fields of unknown type are kept.
+// Fragments are named after their type, e.g. UserFields, unless the query spreads a fragment of that name already.
+// The fields of this Query are replaced by copies, so that the fields shared with other queries or at several positions are left as they are.
+func (q *Query) Normalize(typeOf func(path []string) (string, bool)) error {
+	if err := q.checkCycles(); err != nil {
+		return errors.WithStack(err)
+	}
+	n := &normalizer{
+		typeOf:    typeOf,
+		counts:    make(map[string]int),
+		fragments: make(map[string]*Fragment),
+		names:     make(map[string]bool),
+		ours:      make(map[*Fragment]bool),
+		spreads:   make(map[*Fragment]int),
+		defined:   make(map[*Fragment]bool),
+	}
+	if err := n.count(q.Fields, nil); err != nil {
+		return errors.WithStack(err)
+	}
+	fields := n.replace(q.Fields, nil)
+	if len(n.fragments) == 0 {
+		return nil
+	}
+	n.countSpreads(fields)
+	q.Fields = n.inline(fields)
+	q.FragmentDefinitions = true
+	q.invalidate()
+	return nil
+}
+
+// normalizer replaces the repeated selection sets of a query by fragment spreads, see Query.Normalize.
+type normalizer struct {
+	typeOf    func(path []string) (string, bool)
+	counts    map[string]int       // The occurrences of the selection sets by key, see selection.
+	fragments map[string]*Fragment // The fragments of the repeated selection sets by key.
+	names     map[string]bool      // The names of the fragments spread in the query.
+	ours      map[*Fragment]bool   // The fragments of the repeated selection sets, not the ones of a FragmentRegistry.
+	spreads   map[*Fragment]int    // The spreads of the fragments once replaced.
+	defined   map[*Fragment]bool   // The fragments named already, see inline.
+}
+
+// selection returns the key of the selection set of f on its type, and whether it may be replaced by a fragment.
+func (n *normalizer) selection(f *Field, path []string) (string, string, bool) {
+	if len(f.Fields) == 0 {
+		return "", "", false
+	}
+	typeName, ok := n.typeOf(path)
+	if !ok {
+		return "", "", false
+	}
+	var b strings.Builder
+	writeKey(&b, typeName)
+	onPath := make(map[*Field]bool)
+	for _, subF := range f.Fields {
+		// the cycles are checked already
+		subF.writeShapeKey(&b, onPath)
+	}
+	return b.String(), typeName, true
+}
+
+// count counts the occurrences of the selection sets of fs, at path.
+func (n *normalizer) count(fs []*Field, path []string) error {
+	for _, f := range fs {
+		if f == nil || f.compiled != nil {
+			continue
+		}
+		if f.fragment != nil {
+			n.names[f.fragment.Name] = true
+			continue
+		}
+		fieldPath := append(path[:len(path):len(path)], f.Name)
+		if key, typeName, ok := n.selection(f, fieldPath); ok {
+			if !validName.MatchString(typeName) {
+				return errors.WithStack(InvalidNameErr{typeConditionName, typeName})
+			}
+			n.counts[key]++
+		}
+		if err := n.count(f.Fields, fieldPath); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// replace returns a copy of fs, at path, whose repeated selection sets are replaced by fragment spreads.
+func (n *normalizer) replace(fs []*Field, path []string) []*Field {
+	if fs == nil {
+		return nil
+	}
+	copied := make([]*Field, len(fs))
+	for i, f := range fs {
+		if f == nil || f.compiled != nil || f.fragment != nil {
+			copied[i] = f
+			continue
+		}
+		c := *f
+		fieldPath := append(path[:len(path):len(path)], f.Name)
+		if key, typeName, ok := n.selection(f, fieldPath); ok && n.counts[key] > 1 {
+			frag, ok := n.fragments[key]
+			if !ok {
+				frag = &Fragment{TypeCondition: typeName}
+				n.fragments[key] = frag
+				n.ours[frag] = true
+				frag.Fields = n.replace(f.Fields, fieldPath)
+			}
+			c.Fields = []*Field{{Name: "... on " + typeName, Fields: frag.Fields, fragment: frag}}
+		} else {
+			c.Fields = n.replace(f.Fields, fieldPath)
+		}
+		copied[i] = &c
+	}
+	return copied
+}
+
+// countSpreads counts the spreads of the fragments of n in fs and in the fragments they spread.
+// A selection set repeated only within a repeated one is spread once.
+func (n *normalizer) countSpreads(fs []*Field) {
+	for _, f := range fs {
+		if f == nil || f.compiled != nil {
+			continue
+		}
+		if f.fragment != nil {
+			if !n.ours[f.fragment] {
+				continue
+			}
+			n.spreads[f.fragment]++
+			if n.spreads[f.fragment] > 1 {
+				continue
+			}
+		}
+		n.countSpreads(f.Fields)
+	}
+}
+
+// inline returns a copy of fs where the fragments of n spread once are replaced by their fields,
+// and names the other fragments in the order of their first spread.
+func (n *normalizer) inline(fs []*Field) []*Field {
+	if fs == nil {
+		return nil
+	}
+	copied := make([]*Field, 0, len(fs))
+	for _, f := range fs {
+		if f == nil || f.compiled != nil || (f.fragment != nil && !n.ours[f.fragment]) {
+			copied = append(copied, f)
+			continue
+		}
+		if f.fragment == nil {
+			c := *f
+			c.Fields = n.inline(f.Fields)
+			copied = append(copied, &c)
+			continue
+		}
+		frag := f.fragment
+		if n.spreads[frag] == 1 {
+			copied = append(copied, n.inline(frag.Fields)...)
+			continue
+		}
+		if !n.defined[frag] {
+			n.defined[frag] = true
+			frag.Name = n.name(frag.TypeCondition)
+			frag.Fields = n.inline(frag.Fields)
+		}
+		copied = append(copied, &Field{Name: f.Name, Fields: frag.Fields, fragment: frag})
+	}
+	return copied
+}
+
+// name returns a fragment name for typeName which the query does not spread yet.
+func (n *normalizer) name(typeName string) string {
+	name := typeName + "Fields"
+	for i := 2; n.names[name]; i++ {
+		name = typeName + "Fields" + strconv.Itoa(i)
+	}
+	n.names[name] = true
+	return name
+}
@@ -0,0 +1,98 @@
+package graphb
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// typesOf returns a typeOf function looking up the type of a field by its name.
+func typesOf(types map[string]string) func(path []string) (string, bool) {
+	return func(path []string) (string, bool) {
+		typeName, ok := types[path[len(path)-1]]
+		return typeName, ok
+	}
+}
+
+func TestQuery_sharedFields(t *testing.T) {
+	name := MakeField("name")
+	q := MakeQuery(TypeQuery).SetFields(MakeField("me").SetFields(name), MakeField("author").SetFields(name))
+	s, err := q.StringChan()
+	assert.Nil(t, err)
+	assert.Equal(t, "query{me{name},author{name}}", StringFromChan(s))
+
+	problems := errors.Cause(q.Validate()).(ValidationErr).Problems
+	assert.Len(t, problems, 1)
+	assert.Equal(t, SeverityWarning, problems[0].Severity)
+	assert.Equal(t, SharedFieldErr{"name"}, errors.Cause(problems[0].Err))
+
+	q.Config = NewConfig(WithSharedFieldCheck())
+	_, err = q.StringChan()
+	assert.Equal(t, SharedFieldErr{"name"}, errors.Cause(err))
+
+	// the fields of spreads are shared on purpose
+	r := NewFragmentRegistry()
+	assert.Nil(t, r.RegisterFragment("UserCard", "User", Fields("id", "name")...))
+	q = NewQuery(TypeQuery, OfConfig(NewConfig(WithSharedFieldCheck())),
+		OfField("me", OfSpread(r, "UserCard")),
+		OfField("author", OfSpread(r, "UserCard")),
+	)
+	assert.Nil(t, q.E)
+	assert.Nil(t, q.Validate())
+}
+
+func TestQuery_Normalize(t *testing.T) {
+	user := func() []*Field { return Fields("id", "name") }
+	me := MakeField("me").SetFields(append(user(), MakeField("friends").SetFields(user()...))...)
+	author := MakeField("author").SetFields(user()...)
+	q := MakeQuery(TypeQuery).SetFields(me, author, MakeField("viewer").SetFields(user()...))
+
+	assert.Nil(t, q.Normalize(typesOf(map[string]string{"me": "User", "friends": "User", "author": "User"})))
+	s, err := q.StringChan()
+	assert.Nil(t, err)
+	assert.Equal(t, "query{me{id,name,friends{...UserFields}},author{...UserFields},viewer{id,name}}fragment UserFields on User{id,name}", StringFromChan(s))
+	// the fields are copied
+	assert.Equal(t, user(), author.Fields)
+	assert.Len(t, me.Fields, 3)
+
+	// a selection set repeated only within a repeated one is not a fragment
+	post := func() *Field {
+		return MakeField("posts").SetFields(MakeField("title"), MakeField("author").SetFields(user()...))
+	}
+	q = MakeQuery(TypeQuery).SetFields(MakeField("a").SetFields(post()), MakeField("b").SetFields(post()))
+	assert.Nil(t, q.Normalize(typesOf(map[string]string{"posts": "Post", "author": "User"})))
+	s, err = q.StringChan()
+	assert.Nil(t, err)
+	assert.Equal(t, "query{a{posts{...PostFields}},b{posts{...PostFields}}}fragment PostFields on Post{title,author{id,name}}", StringFromChan(s))
+
+	// nothing repeated
+	q = MakeQuery(TypeQuery).SetFields(MakeField("me").SetFields(user()...))
+	assert.Nil(t, q.Normalize(typesOf(map[string]string{"me": "User"})))
+	assert.False(t, q.FragmentDefinitions)
+}
+
+func TestQuery_Normalize_names(t *testing.T) {
+	r := NewFragmentRegistry()
+	assert.Nil(t, r.RegisterFragment("UserFields", "User", Fields("id")...))
+	q := NewQuery(TypeQuery,
+		OfField("me", OfSpread(r, "UserFields")),
+		OfField("a", OfFields("name", "email")),
+		OfField("b", OfFields("name", "email")),
+	)
+	assert.Nil(t, q.E)
+	assert.Nil(t, q.Normalize(typesOf(map[string]string{"a": "User", "b": "User", "me": "User"})))
+	s, err := q.StringChan()
+	assert.Nil(t, err)
+	assert.Equal(t, "query{me{...UserFields},a{...UserFields2},b{...UserFields2}}fragment UserFields on User{id}fragment UserFields2 on User{name,email}", StringFromChan(s))
+
+	q = MakeQuery(TypeQuery).SetFields(MakeField("a").SetFields(MakeField("id")), MakeField("b").SetFields(MakeField("id")))
+	err = q.Normalize(typesOf(map[string]string{"a": "[User]", "b": "[User]"}))
+	assert.Equal(t, InvalidNameErr{typeConditionName, "[User]"}, errors.Cause(err))
+
+	cyclic := MakeField("a")
+	cyclic.Fields = []*Field{cyclic}
+	q = MakeQuery(TypeQuery).SetFields(cyclic)
+	_, ok := errors.Cause(q.Normalize(typesOf(nil))).(CyclicFieldErr)
+	assert.True(t, ok)
+}
@@ -0,0 +1,140 @@
+package graphb
+
+import (
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// OpenAPIParameter is an OpenAPI style parameter, e.g. {"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}.
+// It helps migration layers translating REST calls into GraphQL queries.
+type OpenAPIParameter struct {
+	Name     string        `json:"name"`
+	In       string        `json:"in"` // One of "path", "query", "header" and "cookie".
+	Required bool          `json:"required"`
+	Schema   OpenAPISchema `json:"schema"`
+}
+
+// OpenAPISchema is the subset of an OpenAPI schema object describing a parameter value.
+type OpenAPISchema struct {
+	Type   string         `json:"type"` // One of "string", "integer", "number", "boolean" and "array".
+	Format string         `json:"format"`
+	Items  *OpenAPISchema `json:"items"`
+}
+
+// openAPITypes maps OpenAPI primitive types to GraphQL scalars.
+var openAPITypes = map[string]string{
+	"string":  "String",
+	"integer": "Int",
+	"number":  "Float",
+	"boolean": "Boolean",
+}
+
+// graphQLType returns the GraphQL type reference of a value of this schema.
+func (s OpenAPISchema) graphQLType() (string, bool) {
+	if s.Type == "array" {
+		if s.Items == nil {
+			return "", false
+		}
+		t, ok := s.Items.graphQLType()
+		return "[" + t + "]", ok
+	}
+	if s.Type == "string" && s.Format == "uuid" {
+		return "ID", true
+	}
+	t, ok := openAPITypes[s.Type]
+	return t, ok
+}
+
+// convert converts the float64 values of integer schemas to int, in arrays too.
+func (s OpenAPISchema) convert(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case float64:
+		if s.Type != "integer" {
+			return v, nil
+		}
+		if v != math.Trunc(v) || v > math.MaxInt32 || v < math.MinInt32 {
+			e := argumentTypeNotSupported("", v)
+			e.Suggestion = "an integer schema requires a whole number of 32 bits"
+			return nil, errors.WithStack(e)
+		}
+		return int(v), nil
+	case []interface{}:
+		if s.Type != "array" || s.Items == nil {
+			return v, nil
+		}
+		converted := make([]interface{}, len(v))
+		for i, item := range v {
+			c, err := s.Items.convert(item)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			converted[i] = c
+		}
+		return converted, nil
+	}
+	return value, nil
+}
+
+// isArgument reports whether the parameter is part of the resource addressing, which GraphQL expresses with arguments.
+// Header and cookie parameters belong to the transport and are left to the caller.
+func (p OpenAPIParameter) isArgument() bool {
+	return p.In == "path" || p.In == "query"
+}
+
+// ArgumentsFromOpenAPI returns the arguments of the path and query parameters which have a value in values.
+// Values are converted like ArgumentAny, except that the float64 values of integer parameters and items,
+// as decoded by encoding/json, are converted to int, and must be whole.
+func ArgumentsFromOpenAPI(params []OpenAPIParameter, values map[string]interface{}) ([]Argument, error) {
+	var args []Argument
+	for _, p := range params {
+		value, ok := values[p.Name]
+		if !p.isArgument() || !ok {
+			continue
+		}
+		if !validName.MatchString(p.Name) {
+			return nil, errors.WithStack(InvalidNameErr{argumentName, p.Name})
+		}
+		value, err := p.Schema.convert(value)
+		if e, ok := errors.Cause(err).(ArgumentTypeNotSupportedErr); ok {
+			e.Argument = p.Name
+			return nil, errors.WithStack(e)
+		}
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		arg, err := ArgumentAny(p.Name, value)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		args = append(args, arg)
+	}
+	return args, nil
+}
+
+// VariablesFromOpenAPI returns a variable definition for each path and query parameter, typed after its schema,
+// and the arguments referencing them, e.g. `$id:String!` and `id:$id` for a required string parameter.
+// The values of the variables are taken from values, if any.
+func VariablesFromOpenAPI(params []OpenAPIParameter, values map[string]interface{}) ([]Variable, []Argument, error) {
+	var variables []Variable
+	var args []Argument
+	for _, p := range params {
+		if !p.isArgument() {
+			continue
+		}
+		t, ok := p.Schema.graphQLType()
+		if !ok {
+			return nil, nil, errors.WithStack(OpenAPISchemaNotSupportedErr{p.Name, p.Schema.Type})
+		}
+		if p.Required {
+			t += "!"
+		}
+		v := Variable{Name: p.Name, Type: t, Value: values[p.Name]}
+		if err := v.check(); err != nil {
+			return nil, nil, errors.WithStack(err)
+		}
+		variables = append(variables, v)
+		args = append(args, ArgumentVariable(p.Name, p.Name))
+	}
+	return variables, args, nil
+}
@@ -0,0 +1,74 @@
+package graphb
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const openAPIParams = `[
+	{"name": "id", "in": "path", "required": true, "schema": {"type": "string", "format": "uuid"}},
+	{"name": "limit", "in": "query", "schema": {"type": "integer"}},
+	{"name": "tags", "in": "query", "schema": {"type": "array", "items": {"type": "string"}}},
+	{"name": "X-Request-ID", "in": "header", "schema": {"type": "string"}}
+]`
+
+func TestArgumentsFromOpenAPI(t *testing.T) {
+	var params []OpenAPIParameter
+	assert.Nil(t, json.Unmarshal([]byte(openAPIParams), &params))
+	var values map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(`{"id": "42", "limit": 10, "X-Request-ID": "abc"}`), &values))
+
+	args, err := ArgumentsFromOpenAPI(params, values)
+	assert.Nil(t, err)
+	assert.Equal(t, []Argument{ArgumentString("id", "42"), ArgumentInt("limit", 10)}, args)
+
+	_, err = ArgumentsFromOpenAPI(params, map[string]interface{}{"limit": 1.5})
+	assert.Equal(t, ArgumentTypeNotSupportedErr{"limit", 1.5, reflect.TypeOf(1.5), reflect.Float64, "an integer schema requires a whole number of 32 bits"}, errors.Cause(err))
+
+	_, err = ArgumentsFromOpenAPI([]OpenAPIParameter{{Name: "page-size", In: "query"}}, map[string]interface{}{"page-size": 1})
+	assert.Equal(t, InvalidNameErr{argumentName, "page-size"}, errors.Cause(err))
+}
+
+func TestArgumentsFromOpenAPI_types(t *testing.T) {
+	params := []OpenAPIParameter{
+		{Name: "name", In: "query", Schema: OpenAPISchema{Type: "string"}},
+		{Name: "limit", In: "query", Schema: OpenAPISchema{Type: "integer"}},
+		{Name: "ratio", In: "query", Schema: OpenAPISchema{Type: "number"}},
+		{Name: "active", In: "query", Schema: OpenAPISchema{Type: "boolean"}},
+		{Name: "ids", In: "query", Schema: OpenAPISchema{Type: "array", Items: &OpenAPISchema{Type: "integer"}}},
+		{Name: "tags", In: "query", Schema: OpenAPISchema{Type: "array", Items: &OpenAPISchema{Type: "string"}}},
+		{Name: "filter", In: "query", Schema: OpenAPISchema{Type: "object"}},
+	}
+	var values map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(`{
+		"name": "x", "limit": 10, "ratio": 0.5, "active": true, "ids": [1, 2], "tags": ["a", null],
+		"filter": {"status": "open", "min": 2, "nested": {"any": [1.5]}}
+	}`), &values))
+
+	args, err := ArgumentsFromOpenAPI(params, values)
+	assert.Nil(t, err)
+	s := StringFromChan(MakeField("items").SetArguments(args...).stringChan())
+	assert.Equal(t, `items(name:"x",limit:10,ratio:0.5,active:true,ids:[1,2],tags:["a",null],filter:{min:2.0,nested:{any:[1.5]},status:"open"})`, s)
+
+	_, err = ArgumentsFromOpenAPI(params, map[string]interface{}{"ids": []interface{}{1.5}})
+	assert.Equal(t, "ids", errors.Cause(err).(ArgumentTypeNotSupportedErr).Argument)
+}
+
+func TestVariablesFromOpenAPI(t *testing.T) {
+	var params []OpenAPIParameter
+	assert.Nil(t, json.Unmarshal([]byte(openAPIParams), &params))
+
+	variables, args, err := VariablesFromOpenAPI(params, map[string]interface{}{"id": "42"})
+	assert.Nil(t, err)
+	q := MakeQuery(TypeQuery).AddVariables(variables...).SetFields(MakeField("items").SetArguments(args...).SetFields(Fields("id")...))
+	s, err := q.JSON()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"query":"query($id:ID!,$limit:Int,$tags:[String]){items(id:$id,limit:$limit,tags:$tags){id}}","variables":{"id":"42"}}`, s)
+
+	_, _, err = VariablesFromOpenAPI([]OpenAPIParameter{{Name: "filter", In: "query", Schema: OpenAPISchema{Type: "object"}}}, nil)
+	assert.Equal(t, OpenAPISchemaNotSupportedErr{"filter", "object"}, errors.Cause(err))
+}
@@ -0,0 +1,39 @@
+package graphb
+
+// OperationOption lets dialect packages serialize nonstandard operation metadata around an operation,
+// without forking the serializer. Prefix tokens are emitted before the operation type, Suffix tokens after the selection set.
+type OperationOption interface {
+	Prefix() []Token
+	Suffix() []Token
+}
+
+// OperationAffix is an OperationOption made of fixed tokens.
+type OperationAffix struct {
+	Before []Token
+	After  []Token
+}
+
+// Prefix implements OperationOption.
+func (a OperationAffix) Prefix() []Token {
+	return a.Before
+}
+
+// Suffix implements OperationOption.
+func (a OperationAffix) Suffix() []Token {
+	return a.After
+}
+
+// AddOperationOptions adds OperationOption(s) to this Query.
+// Prefixes are emitted in the order the options are added, suffixes in the reverse order so that options nest.
+func (q *Query) AddOperationOptions(options ...OperationOption) *Query {
+	q.OperationOptions = append(q.OperationOptions, options...)
+	return q.invalidate()
+}
+
+// OfOperationOptions returns a QueryOption which adds OperationOption(s) to a query.
+func OfOperationOptions(options ...OperationOption) QueryOption {
+	return func(query *Query) error {
+		query.OperationOptions = append(query.OperationOptions, options...)
+		return nil
+	}
+}
@@ -0,0 +1,33 @@
+package graphb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery_AddOperationOptions(t *testing.T) {
+	q := MakeQuery(TypeSubscription).
+		AddOperationOptions(
+			OperationAffix{
+				Before: []Token{{TokenName, "realtime"}, {TokenSpace, " "}},
+				After:  []Token{{TokenSpace, " "}, {TokenName, "end"}},
+			},
+			OperationAffix{
+				Before: []Token{{TokenPunctuator, "<"}},
+				After:  []Token{{TokenPunctuator, ">"}},
+			},
+		).
+		SetFields(MakeField("onMessage"))
+	strCh, err := q.StringChan()
+	assert.Nil(t, err)
+	assert.Equal(t, "realtime <subscription{onMessage}> end", StringFromChan(strCh))
+}
+
+func TestOfOperationOptions(t *testing.T) {
+	q := NewQuery(TypeQuery, OfOperationOptions(OperationAffix{After: []Token{{TokenSpace, "\n"}}}), OfField("me"))
+	assert.Nil(t, q.E)
+	strCh, err := q.StringChan()
+	assert.Nil(t, err)
+	assert.Equal(t, "query{me}\n", StringFromChan(strCh))
+}
@@ -1,10 +1,42 @@
 package graphb
 
-type operationType string
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// OperationType is the keyword of an operation, which begins its serialization.
+// Any casing is accepted, the keyword being serialized in lower case, see OperationType.Canonical.
+type OperationType string
 
 // 3 types of operation.
 const (
-	TypeQuery        operationType = "query"
-	TypeMutation     operationType = "mutation"
-	TypeSubscription operationType = "subscription"
+	TypeQuery        OperationType = "query"
+	TypeMutation     OperationType = "mutation"
+	TypeSubscription OperationType = "subscription"
 )
+
+// OperationTypeFromString returns the OperationType of the keyword s in any casing, e.g. TypeMutation for "Mutation".
+// s being none of query, mutation and subscription returns an InvalidOperationTypeErr.
+func OperationTypeFromString(s string) (OperationType, error) {
+	t := OperationType(s).Canonical()
+	if !t.IsValid() {
+		return "", errors.WithStack(InvalidOperationTypeErr{OperationType(s)})
+	}
+	return t, nil
+}
+
+// Canonical returns this OperationType in lower case, e.g. TypeQuery for "QUERY", to compare it with the constants.
+func (t OperationType) Canonical() OperationType {
+	return OperationType(strings.ToLower(string(t)))
+}
+
+// IsValid reports whether this OperationType is one of query, mutation and subscription, in any casing.
+func (t OperationType) IsValid() bool {
+	switch t.Canonical() {
+	case TypeQuery, TypeMutation, TypeSubscription:
+		return true
+	}
+	return false
+}
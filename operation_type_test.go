@@ -0,0 +1,36 @@
+package graphb
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperationTypeFromString(t *testing.T) {
+	for s, expected := range map[string]OperationType{
+		"query":        TypeQuery,
+		"QUERY":        TypeQuery,
+		"Mutation":     TypeMutation,
+		"subscription": TypeSubscription,
+	} {
+		Type, err := OperationTypeFromString(s)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, Type)
+	}
+
+	_, err := OperationTypeFromString("fragment")
+	assert.Equal(t, InvalidOperationTypeErr{"fragment"}, errors.Cause(err))
+}
+
+func TestOperationType_IsValid(t *testing.T) {
+	assert.True(t, TypeQuery.IsValid())
+	assert.True(t, OperationType("SubScription").IsValid())
+	assert.False(t, OperationType("").IsValid())
+	assert.False(t, OperationType("query ").IsValid())
+
+	assert.Equal(t, TypeMutation, OperationType("MUTATION").Canonical())
+	s, err := MakeQuery("MUTATION").SetFields(MakeField("a")).StringChan()
+	assert.Nil(t, err)
+	assert.Equal(t, "mutation{a}", StringFromChan(s))
+}
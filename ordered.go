@@ -0,0 +1,66 @@
+package graphb
+
+// OrderedArguments is a set of arguments of distinct names kept in insertion order,
+// for builders which look arguments up by name, e.g. from a config, and need a deterministic emission order.
+// The zero value is empty and ready to use.
+type OrderedArguments struct {
+	args    []Argument
+	indices map[string]int // The index of every argument in args by name.
+}
+
+// NewOrderedArguments returns the OrderedArguments of args, an argument replacing a previous one of the same name.
+func NewOrderedArguments(args ...Argument) *OrderedArguments {
+	o := &OrderedArguments{}
+	for _, arg := range args {
+		o.Set(arg)
+	}
+	return o
+}
+
+// Get returns the argument of the given name and whether there is one.
+func (o *OrderedArguments) Get(name string) (Argument, bool) {
+	i, ok := o.indices[name]
+	if !ok {
+		return Argument{}, false
+	}
+	return o.args[i], true
+}
+
+// Set replaces the argument of the same name in place, or adds arg last if there is none,
+// and returns the pointer to this OrderedArguments.
+func (o *OrderedArguments) Set(arg Argument) *OrderedArguments {
+	if i, ok := o.indices[arg.Name]; ok {
+		o.args[i] = arg
+		return o
+	}
+	if o.indices == nil {
+		o.indices = make(map[string]int)
+	}
+	o.indices[arg.Name] = len(o.args)
+	o.args = append(o.args, arg)
+	return o
+}
+
+// Delete removes the argument of the given name, if any, and returns the pointer to this OrderedArguments.
+func (o *OrderedArguments) Delete(name string) *OrderedArguments {
+	i, ok := o.indices[name]
+	if !ok {
+		return o
+	}
+	o.args = append(o.args[:i], o.args[i+1:]...)
+	delete(o.indices, name)
+	for j := i; j < len(o.args); j++ {
+		o.indices[o.args[j].Name] = j
+	}
+	return o
+}
+
+// Len returns the number of arguments.
+func (o *OrderedArguments) Len() int {
+	return len(o.args)
+}
+
+// Arguments returns a copy of the arguments in insertion order, e.g. for Field.SetArguments.
+func (o *OrderedArguments) Arguments() []Argument {
+	return append([]Argument(nil), o.args...)
+}
@@ -0,0 +1,32 @@
+package graphb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderedArguments(t *testing.T) {
+	o := NewOrderedArguments(ArgumentInt("first", 10), ArgumentEnum("orderBy", "NEWEST"), ArgumentInt("first", 20))
+	assert.Equal(t, 2, o.Len())
+	arg, ok := o.Get("first")
+	assert.True(t, ok)
+	assert.Equal(t, ArgumentInt("first", 20), arg)
+	_, ok = o.Get("after")
+	assert.False(t, ok)
+
+	o.Set(ArgumentString("after", "abc")).Set(ArgumentEnum("orderBy", "TOP")).Delete("first").Delete("missing")
+	assert.Equal(t, []Argument{ArgumentEnum("orderBy", "TOP"), ArgumentString("after", "abc")}, o.Arguments())
+	arg, ok = o.Get("after")
+	assert.True(t, ok)
+	assert.Equal(t, ArgumentString("after", "abc"), arg)
+
+	args := o.Arguments()
+	args[0] = ArgumentInt("last", 1)
+	assert.Equal(t, "posts(orderBy:TOP,after:\"abc\")", StringFromChan(MakeField("posts").SetArguments(o.Arguments()...).stringChan()))
+
+	var zero OrderedArguments
+	assert.Equal(t, 0, zero.Len())
+	zero.Set(ArgumentInt("id", 1))
+	assert.Equal(t, []Argument{ArgumentInt("id", 1)}, zero.Arguments())
+}
@@ -0,0 +1,58 @@
+package graphb
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// OverflowPolicy is what a subscription does with a result when the buffer of its consumer is full, see SSEClient.Buffer.
+type OverflowPolicy int
+
+const (
+	OverflowBlock      OverflowPolicy = iota // Wait for the consumer, which holds back the stream.
+	OverflowDropOldest                       // Drop the oldest buffered result to make room.
+	OverflowError                            // End the subscription with a SubscriptionOverflowErr.
+)
+
+// newPayloads returns the channel of the results of a subscription, buffering size results.
+// Policies other than OverflowBlock need a buffer of at least one result.
+func newPayloads(size int, policy OverflowPolicy) chan SubscriptionPayload {
+	if policy != OverflowBlock && size < 1 {
+		size = 1
+	}
+	return make(chan SubscriptionPayload, size)
+}
+
+// deliverPayload sends payload to payloads following policy. It returns the error of ctx if ctx is done first,
+// and a SubscriptionOverflowErr if the buffer is full and policy is OverflowError.
+// The caller has to be the only sender to payloads.
+func deliverPayload(ctx context.Context, payloads chan SubscriptionPayload, payload SubscriptionPayload, policy OverflowPolicy) error {
+	switch policy {
+	case OverflowDropOldest:
+		for {
+			select {
+			case payloads <- payload:
+				return nil
+			default:
+			}
+			select {
+			case <-payloads:
+			default:
+			}
+		}
+	case OverflowError:
+		select {
+		case payloads <- payload:
+			return nil
+		default:
+			return errors.WithStack(SubscriptionOverflowErr{cap(payloads)})
+		}
+	}
+	select {
+	case payloads <- payload:
+		return nil
+	case <-ctx.Done():
+		return errors.WithStack(ctx.Err())
+	}
+}
@@ -0,0 +1,39 @@
+package graphb
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_deliverPayload(t *testing.T) {
+	payload := func(i int) SubscriptionPayload {
+		return SubscriptionPayload{Data: json.RawMessage{byte('0' + i)}}
+	}
+	ctx := context.Background()
+
+	payloads := newPayloads(0, OverflowDropOldest)
+	assert.Equal(t, 1, cap(payloads))
+	payloads = newPayloads(2, OverflowDropOldest)
+	for i := 1; i <= 3; i++ {
+		assert.Nil(t, deliverPayload(ctx, payloads, payload(i), OverflowDropOldest))
+	}
+	assert.Equal(t, payload(2), <-payloads)
+	assert.Equal(t, payload(3), <-payloads)
+
+	payloads = newPayloads(1, OverflowError)
+	assert.Nil(t, deliverPayload(ctx, payloads, payload(1), OverflowError))
+	err := deliverPayload(ctx, payloads, payload(2), OverflowError)
+	assert.Equal(t, SubscriptionOverflowErr{1}, errors.Cause(err))
+	assert.Equal(t, payload(1), <-payloads)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	payloads = newPayloads(0, OverflowBlock)
+	assert.Equal(t, 0, cap(payloads))
+	err = deliverPayload(canceled, payloads, payload(1), OverflowBlock)
+	assert.Equal(t, context.Canceled, errors.Cause(err))
+}
@@ -0,0 +1,192 @@
+package graphb
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ExecuteFunc sends a Query and returns the "data" member of the response.
+// graphb does not send queries itself, ExecuteFunc is where one plugs in an HTTP client.
+type ExecuteFunc func(ctx context.Context, q *Query) (json.RawMessage, error)
+
+// PageIterator iterates over the pages of a cursor paginated connection, see Paginate.
+//
+//	it := Paginate(ctx, q, "repository.issues.pageInfo", execute)
+//	for it.Next() {
+//		for _, node := range it.Nodes() { ... }
+//	}
+//	if err := it.Err(); err != nil { ... }
+type PageIterator struct {
+	ctx        context.Context
+	query      *Query
+	connection []string
+	execute    ExecuteFunc
+
+	nodes  []json.RawMessage
+	cursor string
+	done   bool
+	err    error
+}
+
+// Paginate returns a PageIterator over the connection whose pageInfo is at pathToPageInfo,
+// a '.' separated path of response keys such as "repository.issues.pageInfo".
+// The connection field has to select pageInfo{hasNextPage,endCursor} and either nodes or edges{node}.
+// Before fetching every page but the first, the "after" argument of the connection field is set to the previous end cursor
+// in a copy of q, q itself is left untouched. If the argument is a variable, e.g. after:$cursor, the variable is set instead.
+// A page with a next page but no new end cursor stops the iteration with a CursorNotAdvancedErr.
+// A path without a connection field before pageInfo, e.g. "pageInfo", fails the first call to Next with a NoConnectionErr.
+func Paginate(ctx context.Context, q *Query, pathToPageInfo string, execute ExecuteFunc) *PageIterator {
+	path := strings.Split(pathToPageInfo, ".")
+	it := &PageIterator{
+		ctx:        ctx,
+		query:      q,
+		connection: path[:len(path)-1],
+		execute:    execute,
+	}
+	if len(it.connection) == 0 {
+		it.err = errors.WithStack(NoConnectionErr{pathToPageInfo})
+	}
+	return it
+}
+
+// Next fetches the next page and reports whether there is one. It returns false after the last page or on error.
+func (it *PageIterator) Next() bool {
+	if it.done || it.err != nil {
+		return false
+	}
+	if err := it.ctx.Err(); err != nil {
+		it.err = errors.WithStack(err)
+		return false
+	}
+
+	data, err := it.execute(it.ctx, it.query)
+	if err != nil {
+		it.err = errors.WithStack(err)
+		return false
+	}
+	page, err := decodePage(data, it.connection)
+	if err != nil {
+		it.err = errors.WithStack(err)
+		return false
+	}
+
+	it.nodes = page.Nodes
+	for _, edge := range page.Edges {
+		it.nodes = append(it.nodes, edge.Node)
+	}
+	if !page.PageInfo.HasNextPage {
+		it.done = true
+		return true
+	}
+
+	if page.PageInfo.EndCursor == "" || page.PageInfo.EndCursor == it.cursor {
+		it.err = errors.WithStack(CursorNotAdvancedErr{strings.Join(it.connection, "."), page.PageInfo.EndCursor})
+		return false
+	}
+	fields, variable, ok := withCursor(it.query.Fields, it.connection, page.PageInfo.EndCursor)
+	if !ok {
+		it.err = errors.WithStack(PathNotFoundErr{strings.Join(it.connection, ".")})
+		return false
+	}
+	next := withCopiedHeaders(it.query)
+	next.Fields = fields
+	if variable != "" {
+		next.Variables = make([]Variable, len(it.query.Variables))
+		for i, v := range it.query.Variables {
+			if v.Name == variable {
+				v.Value = page.PageInfo.EndCursor
+			}
+			next.Variables[i] = v
+		}
+	}
+	it.query = next
+	it.cursor = page.PageInfo.EndCursor
+	return true
+}
+
+// withCursor returns a copy of fs in which the "after" argument of the field at path, a path of response keys, is set to cursor,
+// or the name of the variable the argument is, to be set to cursor instead.
+// Only the fields along path are copied, fields of inline fragments are looked up as if they were selected by the enclosing field.
+func withCursor(fs []*Field, path []string, cursor string) ([]*Field, string, bool) {
+	for i, f := range fs {
+		if f == nil || (!f.isInlineFragment() && f.responseKey() != path[0]) {
+			continue
+		}
+		copied := *f
+		copied.compiled = nil
+		var variable string
+		switch {
+		case f.isInlineFragment():
+			fields, v, ok := withCursor(f.Fields, path, cursor)
+			if !ok {
+				continue
+			}
+			copied.Fields, variable = fields, v
+		case len(path) > 1:
+			fields, v, ok := withCursor(f.Fields, path[1:], cursor)
+			if !ok {
+				return nil, "", false
+			}
+			copied.Fields, variable = fields, v
+		default:
+			if after, ok := f.GetArgument("after"); ok {
+				if v, ok := after.Value.(argVariable); ok {
+					variable = string(v)
+					break
+				}
+			}
+			copied.Arguments = append([]Argument(nil), f.Arguments...)
+			// escaped, cursors are opaque
+			copied.ReplaceArgument(Argument{"after", argTokens{{TokenString, jsonString(cursor)}}})
+		}
+		replaced := append([]*Field(nil), fs...)
+		replaced[i] = &copied
+		return replaced, variable, true
+	}
+	return nil, "", false
+}
+
+// Nodes returns the nodes of the current page.
+func (it *PageIterator) Nodes() []json.RawMessage {
+	return it.nodes
+}
+
+// Err returns the error which stopped the iteration, if any.
+func (it *PageIterator) Err() error {
+	return it.err
+}
+
+type connectionPage struct {
+	PageInfo struct {
+		HasNextPage bool   `json:"hasNextPage"`
+		EndCursor   string `json:"endCursor"`
+	} `json:"pageInfo"`
+	Nodes []json.RawMessage `json:"nodes"`
+	Edges []struct {
+		Node json.RawMessage `json:"node"`
+	} `json:"edges"`
+}
+
+// decodePage walks data along path and decodes the connection found there.
+func decodePage(data json.RawMessage, path []string) (connectionPage, error) {
+	var page connectionPage
+	current := data
+	for i, key := range path {
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(current, &object); err != nil {
+			return page, errors.WithStack(err)
+		}
+		next, ok := object[key]
+		if !ok {
+			return page, errors.WithStack(PathNotFoundErr{strings.Join(path[:i+1], ".")})
+		}
+		current = next
+	}
+	if err := json.Unmarshal(current, &page); err != nil {
+		return page, errors.WithStack(err)
+	}
+	return page, nil
+}
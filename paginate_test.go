@@ -0,0 +1,168 @@
+package graphb
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaginate(t *testing.T) {
+	newQuery := func() *Query {
+		return MakeQuery(TypeQuery).SetFields(
+			MakeField("repository").SetFields(
+				MakeField("issues").SetAlias("open").SetArguments(ArgumentInt("first", 2)).SetFields(
+					MakeField("pageInfo").SetFields(MakeField("hasNextPage"), MakeField("endCursor")),
+					MakeField("nodes").SetFields(MakeField("id")),
+				),
+			),
+		)
+	}
+
+	t.Run("pages", func(t *testing.T) {
+		responses := []string{
+			`{"repository":{"open":{"pageInfo":{"hasNextPage":true,"endCursor":"c1"},"nodes":[{"id":1},{"id":2}]}}}`,
+			`{"repository":{"open":{"pageInfo":{"hasNextPage":false,"endCursor":"c2"},"edges":[{"node":{"id":3}}]}}}`,
+		}
+		var sent []string
+		execute := func(ctx context.Context, q *Query) (json.RawMessage, error) {
+			strCh, err := q.StringChan()
+			if err != nil {
+				return nil, err
+			}
+			sent = append(sent, StringFromChan(strCh))
+			return json.RawMessage(responses[len(sent)-1]), nil
+		}
+
+		q := newQuery()
+		it := Paginate(context.Background(), q, "repository.open.pageInfo", execute)
+		var nodes []string
+		for it.Next() {
+			for _, node := range it.Nodes() {
+				nodes = append(nodes, string(node))
+			}
+		}
+		assert.Nil(t, it.Err())
+		assert.Equal(t, []string{`{"id":1}`, `{"id":2}`, `{"id":3}`}, nodes)
+		assert.Equal(t, []string{
+			`query{repository{open:issues(first:2){pageInfo{hasNextPage,endCursor},nodes{id}}}}`,
+			`query{repository{open:issues(first:2,after:"c1"){pageInfo{hasNextPage,endCursor},nodes{id}}}}`,
+		}, sent)
+		assert.False(t, it.Next())
+		// the query is left untouched
+		s, err := q.StringChan()
+		assert.Nil(t, err)
+		assert.Equal(t, sent[0], StringFromChan(s))
+	})
+
+	t.Run("cursor not advanced", func(t *testing.T) {
+		for _, cursor := range []string{"c1", ""} {
+			responses := []string{
+				`{"repository":{"open":{"pageInfo":{"hasNextPage":true,"endCursor":"c1"},"nodes":[]}}}`,
+				`{"repository":{"open":{"pageInfo":{"hasNextPage":true,"endCursor":"` + cursor + `"},"nodes":[]}}}`,
+			}
+			calls := 0
+			execute := func(ctx context.Context, q *Query) (json.RawMessage, error) {
+				calls++
+				return json.RawMessage(responses[calls-1]), nil
+			}
+			it := Paginate(context.Background(), newQuery(), "repository.open.pageInfo", execute)
+			assert.True(t, it.Next())
+			assert.False(t, it.Next())
+			assert.Equal(t, CursorNotAdvancedErr{"repository.open", cursor}, errors.Cause(it.Err()))
+			assert.Equal(t, 2, calls)
+		}
+	})
+
+	t.Run("missing path", func(t *testing.T) {
+		execute := func(ctx context.Context, q *Query) (json.RawMessage, error) {
+			return json.RawMessage(`{"repository":{}}`), nil
+		}
+		it := Paginate(context.Background(), newQuery(), "repository.open.pageInfo", execute)
+		assert.False(t, it.Next())
+		assert.Equal(t, PathNotFoundErr{"repository.open"}, errors.Cause(it.Err()))
+	})
+
+	t.Run("execute error", func(t *testing.T) {
+		execute := func(ctx context.Context, q *Query) (json.RawMessage, error) {
+			return nil, errors.New("boom")
+		}
+		it := Paginate(context.Background(), newQuery(), "repository.open.pageInfo", execute)
+		assert.False(t, it.Next())
+		assert.EqualError(t, it.Err(), "boom")
+	})
+
+	t.Run("cursor variable", func(t *testing.T) {
+		responses := []string{
+			`{"repository":{"open":{"pageInfo":{"hasNextPage":true,"endCursor":"c1"},"nodes":[]}}}`,
+			`{"repository":{"open":{"pageInfo":{"hasNextPage":false,"endCursor":"c2"},"nodes":[]}}}`,
+		}
+		var sent []string
+		var cursors []interface{}
+		execute := func(ctx context.Context, q *Query) (json.RawMessage, error) {
+			strCh, err := q.StringChan()
+			if err != nil {
+				return nil, err
+			}
+			sent = append(sent, StringFromChan(strCh))
+			cursors = append(cursors, q.variableValues()["cursor"])
+			return json.RawMessage(responses[len(sent)-1]), nil
+		}
+		q := newQuery().AddVariables(Variable{Name: "cursor", Type: "String"})
+		q.Fields[0].Fields[0].AddArguments(ArgumentVariable("after", "cursor"))
+		it := Paginate(context.Background(), q, "repository.open.pageInfo", execute)
+		for it.Next() {
+		}
+		assert.Nil(t, it.Err())
+		query := `query($cursor:String){repository{open:issues(first:2,after:$cursor){pageInfo{hasNextPage,endCursor},nodes{id}}}}`
+		assert.Equal(t, []string{query, query}, sent)
+		assert.Equal(t, []interface{}{nil, "c1"}, cursors)
+		// the variables of the query are left untouched
+		assert.Nil(t, q.Variables[0].Value)
+	})
+
+	t.Run("escaped cursor", func(t *testing.T) {
+		responses := []string{
+			`{"repository":{"open":{"pageInfo":{"hasNextPage":true,"endCursor":"a\"b\\c"},"nodes":[]}}}`,
+			`{"repository":{"open":{"pageInfo":{"hasNextPage":false},"nodes":[]}}}`,
+		}
+		var sent []string
+		execute := func(ctx context.Context, q *Query) (json.RawMessage, error) {
+			strCh, err := q.StringChan()
+			if err != nil {
+				return nil, err
+			}
+			sent = append(sent, StringFromChan(strCh))
+			return json.RawMessage(responses[len(sent)-1]), nil
+		}
+		it := Paginate(context.Background(), newQuery(), "repository.open.pageInfo", execute)
+		for it.Next() {
+		}
+		assert.Nil(t, it.Err())
+		assert.Equal(t, `query{repository{open:issues(first:2,after:"a\"b\\c"){pageInfo{hasNextPage,endCursor},nodes{id}}}}`, sent[1])
+		_, err := Minify(sent[1])
+		assert.Nil(t, err)
+	})
+
+	t.Run("no connection", func(t *testing.T) {
+		calls := 0
+		execute := func(ctx context.Context, q *Query) (json.RawMessage, error) {
+			calls++
+			return json.RawMessage(`{"pageInfo":{"hasNextPage":true,"endCursor":"c1"}}`), nil
+		}
+		it := Paginate(context.Background(), newQuery(), "pageInfo", execute)
+		assert.False(t, it.Next())
+		assert.Equal(t, NoConnectionErr{"pageInfo"}, errors.Cause(it.Err()))
+		assert.Equal(t, 0, calls)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		it := Paginate(ctx, newQuery(), "repository.open.pageInfo", nil)
+		assert.False(t, it.Next())
+		assert.Equal(t, context.Canceled, errors.Cause(it.Err()))
+	})
+}
@@ -0,0 +1,61 @@
+package graphb
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// StringParallel serializes this Query like StringChan, but the top level fields are serialized concurrently
+// into separate buffers by up to workers goroutines, then concatenated in order, so the output is the same.
+// It pays off for very large queries only. workers <= 0 means runtime.GOMAXPROCS(0).
+// Queries styled other than StyleCompact by their Config are serialized sequentially.
+func (q *Query) StringParallel(workers int) (string, error) {
+	if err := q.checkAll(); err != nil {
+		return "", errors.WithStack(err)
+	}
+	if q.Config != nil && q.Config.Style != StyleCompact {
+		// the white space of a style depends on the tokens preceding each field
+		return StringFromChan(q.stringChan()), nil
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	fields, defs := q.fieldsAndFragments()
+	parts := make([]string, len(fields))
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(fields); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				parts[i] = StringFromChan(fields[i].stringChan())
+			}
+		}()
+	}
+	for i := range fields {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	s := collect(q.emitHeader) + strings.Join(parts, tokenComma) + collect(q.emitFooter)
+	for _, def := range defs {
+		s += StringFromChan(literals(def.tokenChan()))
+	}
+	return s, nil
+}
+
+// collect runs emit and returns the concatenated literals of the emitted tokens.
+func collect(emit func(chan<- Token)) string {
+	tokenChan := make(chan Token)
+	go func() {
+		emit(tokenChan)
+		close(tokenChan)
+	}()
+	return StringFromChan(literals(tokenChan))
+}
@@ -0,0 +1,37 @@
+package graphb
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery_StringParallel(t *testing.T) {
+	q := MakeQuery(TypeQuery).
+		SetName("big").
+		AddVariables(Variable{Name: "n", Type: "Int"}).
+		AddOperationOptions(OperationAffix{After: []Token{{TokenSpace, "\n"}}})
+	for i := 0; i < 50; i++ {
+		q.AddFields(MakeField(fmt.Sprintf("f%d", i)).
+			SetArguments(ArgumentVariable("n", "n")).
+			SetFields(MakeField("id"), MakeField("name")))
+	}
+	strCh, err := q.StringChan()
+	assert.Nil(t, err)
+	expected := StringFromChan(strCh)
+
+	for _, workers := range []int{0, 1, 3, 100} {
+		s, err := q.StringParallel(workers)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, s, "workers: %d", workers)
+	}
+
+	s, err := MakeQuery(TypeQuery).StringParallel(2)
+	assert.Nil(t, err)
+	assert.Equal(t, "query{}", s)
+
+	_, err = MakeQuery(TypeQuery).SetFields(nil).StringParallel(2)
+	assert.IsType(t, NilFieldErr{}, errors.Cause(err))
+}
@@ -0,0 +1,436 @@
+package graphb
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// parser parses an executable GraphQL document by recursive descent over its lexemes,
+// following the grammar of the spec: http://facebook.github.io/graphql/October2016/#sec-Document
+type parser struct {
+	lexemes []lexeme
+	pos     int
+	file    string   // The file the document is loaded from, if any, for the locations of the fields.
+	spreads []*Field // The fragment spreads, resolved once all fragments are parsed.
+
+	// syntaxOnly makes the parser accept the valid GraphQL which graphb can not represent, and drop it.
+	syntaxOnly bool
+}
+
+// peek returns the current lexeme, or a zero lexeme at the end of the document.
+func (p *parser) peek() lexeme {
+	if p.pos < len(p.lexemes) {
+		return p.lexemes[p.pos]
+	}
+	return lexeme{}
+}
+
+func (p *parser) atEnd() bool {
+	return p.pos >= len(p.lexemes)
+}
+
+// is reports whether the current lexeme is of kind with the given literal, any literal if literal is empty.
+func (p *parser) is(kind TokenKind, literal string) bool {
+	if p.atEnd() {
+		return false
+	}
+	l := p.lexemes[p.pos]
+	return l.Kind == kind && (literal == "" || l.Literal == literal)
+}
+
+func (p *parser) isPunctuator(literal string) bool {
+	return p.is(TokenPunctuator, literal)
+}
+
+// unexpected returns a SyntaxErr at the current lexeme, or at the last one at the end of the document.
+func (p *parser) unexpected(expected string) error {
+	if p.atEnd() {
+		line, column := 1, 1
+		if n := len(p.lexemes); n > 0 {
+			last := p.lexemes[n-1]
+			line, column = last.line, last.column+len(last.Literal)
+		}
+		return newSyntaxErr(line, column, "unexpected end of document, expected %s", expected)
+	}
+	l := p.lexemes[p.pos]
+	return newSyntaxErr(l.line, l.column, "unexpected '%s', expected %s", l.Literal, expected)
+}
+
+// expect consumes the current lexeme if it is of kind with the given literal.
+func (p *parser) expect(kind TokenKind, literal string) (lexeme, error) {
+	if !p.is(kind, literal) {
+		if literal != "" {
+			return lexeme{}, p.unexpected("'" + literal + "'")
+		}
+		return lexeme{}, p.unexpected("a " + kind.String())
+	}
+	p.pos++
+	return p.lexemes[p.pos-1], nil
+}
+
+func (p *parser) expectName() (string, error) {
+	l, err := p.expect(TokenName, "")
+	return l.Literal, err
+}
+
+func (p *parser) parseDocument() (*Document, error) {
+	if p.atEnd() {
+		return nil, p.unexpected("an operation or a fragment definition")
+	}
+	doc := &Document{}
+	for !p.atEnd() {
+		switch {
+		case p.is(TokenName, "fragment"):
+			frag, err := p.parseFragmentDefinition()
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			doc.Fragments = append(doc.Fragments, frag)
+		case p.isPunctuator(tokenLB), p.is(TokenName, "query"), p.is(TokenName, "mutation"), p.is(TokenName, "subscription"):
+			q, err := p.parseOperation()
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			doc.Operations = append(doc.Operations, q)
+		default:
+			return nil, p.unexpected("an operation or a fragment definition")
+		}
+	}
+	if p.syntaxOnly {
+		return doc, nil
+	}
+	if err := doc.resolve(p.spreads); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return doc, nil
+}
+
+func (p *parser) parseOperation() (*Query, error) {
+	q := MakeQuery(TypeQuery)
+	q.FragmentDefinitions = true
+	if !p.isPunctuator(tokenLB) {
+		q.Type = OperationType(p.peek().Literal)
+		p.pos++
+		if p.is(TokenName, "") {
+			q.Name = p.peek().Literal
+			p.pos++
+		}
+		if p.isPunctuator(tokenLP) {
+			variables, err := p.parseVariableDefinitions()
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			q.Variables = variables
+		}
+		directives, err := p.parseDirectives(false)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		q.Directives = directives
+	}
+	fields, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	q.Fields = fields
+	return q, nil
+}
+
+// skipUnsupportedDirectives parses and drops the directives at a place graphb can not represent them if syntaxOnly,
+// otherwise it returns a SyntaxErr.
+func (p *parser) skipUnsupportedDirectives(what string, constant bool) error {
+	if !p.isPunctuator(tokenAt) {
+		return nil
+	}
+	if !p.syntaxOnly {
+		l := p.peek()
+		return newSyntaxErr(l.line, l.column, "%s are not supported by graphb", what)
+	}
+	_, err := p.parseDirectives(constant)
+	return errors.WithStack(err)
+}
+
+func (p *parser) parseVariableDefinitions() ([]Variable, error) {
+	p.pos++ // (
+	var variables []Variable
+	for {
+		if _, err := p.expect(TokenPunctuator, tokenDollar); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		name, err := p.expectName()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if _, err := p.expect(TokenPunctuator, tokenColumn); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		Type, err := p.parseType()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		v := Variable{Name: name, Type: Type}
+		if p.isPunctuator(tokenEquals) {
+			p.pos++
+			if v.defaultValue, err = p.parseValue(true); err != nil {
+				return nil, errors.WithStack(err)
+			}
+		}
+		if err := p.skipUnsupportedDirectives("directives on variables", true); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		variables = append(variables, v)
+		if p.isPunctuator(tokenRP) {
+			p.pos++
+			return variables, nil
+		}
+	}
+}
+
+// parseType returns a type reference as written, without ignored tokens.
+func (p *parser) parseType() (string, error) {
+	var Type string
+	if p.isPunctuator(tokenLSB) {
+		p.pos++
+		ofType, err := p.parseType()
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		if _, err := p.expect(TokenPunctuator, tokenRSB); err != nil {
+			return "", errors.WithStack(err)
+		}
+		Type = tokenLSB + ofType + tokenRSB
+	} else {
+		name, err := p.expectName()
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		Type = name
+	}
+	if p.isPunctuator("!") {
+		p.pos++
+		Type += "!"
+	}
+	return Type, nil
+}
+
+func (p *parser) parseFragmentDefinition() (*Fragment, error) {
+	p.pos++ // fragment
+	if p.is(TokenName, "on") {
+		return nil, p.unexpected("a fragment name")
+	}
+	name, err := p.expectName()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if _, err := p.expect(TokenName, "on"); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	typeCondition, err := p.expectName()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := p.skipUnsupportedDirectives("directives on fragment definitions", false); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	fields, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &Fragment{Name: name, TypeCondition: typeCondition, Fields: fields}, nil
+}
+
+func (p *parser) parseSelectionSet() ([]*Field, error) {
+	if _, err := p.expect(TokenPunctuator, tokenLB); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var fields []*Field
+	for {
+		f, err := p.parseSelection()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		fields = append(fields, f)
+		if p.isPunctuator(tokenRB) {
+			p.pos++
+			return fields, nil
+		}
+	}
+}
+
+// location returns the location of the current lexeme if the document is loaded from a file.
+func (p *parser) location() *SourceLocation {
+	if p.file == "" {
+		return nil
+	}
+	return &SourceLocation{p.file, p.peek().line}
+}
+
+func (p *parser) parseSelection() (*Field, error) {
+	f := &Field{Location: p.location()}
+	if p.isPunctuator("...") {
+		return f, p.parseFragment(f)
+	}
+
+	name, err := p.expectName()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	f.Name = name
+	if p.isPunctuator(tokenColumn) {
+		p.pos++
+		if f.Name, err = p.expectName(); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		f.Alias = name
+	}
+	if p.isPunctuator(tokenLP) {
+		if f.Arguments, err = p.parseArguments(false); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	if f.Directives, err = p.parseDirectives(false); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if p.isPunctuator(tokenLB) {
+		if f.Fields, err = p.parseSelectionSet(); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	return f, nil
+}
+
+// parseFragment parses a fragment spread or an inline fragment into f.
+func (p *parser) parseFragment(f *Field) error {
+	p.pos++ // ...
+	var err error
+	if p.is(TokenName, "") && !p.is(TokenName, "on") {
+		f.fragment = &Fragment{Name: p.peek().Literal}
+		p.spreads = append(p.spreads, f)
+		p.pos++
+		f.Directives, err = p.parseDirectives(false)
+		return errors.WithStack(err)
+	}
+	f.Name = "..."
+	if p.is(TokenName, "on") {
+		p.pos++
+		typeCondition, err := p.expectName()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		f.Name += " on " + typeCondition
+	}
+	if f.Directives, err = p.parseDirectives(false); err != nil {
+		return errors.WithStack(err)
+	}
+	f.Fields, err = p.parseSelectionSet()
+	return errors.WithStack(err)
+}
+
+func (p *parser) parseArguments(constant bool) ([]Argument, error) {
+	p.pos++ // (
+	var args []Argument
+	for {
+		name, err := p.expectName()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if _, err := p.expect(TokenPunctuator, tokenColumn); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		value, err := p.parseValue(constant)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		args = append(args, Argument{name, value})
+		if p.isPunctuator(tokenRP) {
+			p.pos++
+			return args, nil
+		}
+	}
+}
+
+func (p *parser) parseDirectives(constant bool) ([]Directive, error) {
+	var directives []Directive
+	for p.isPunctuator(tokenAt) {
+		p.pos++
+		name, err := p.expectName()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		d := Directive{Name: name}
+		if p.isPunctuator(tokenLP) {
+			if d.Arguments, err = p.parseArguments(constant); err != nil {
+				return nil, errors.WithStack(err)
+			}
+		}
+		directives = append(directives, d)
+	}
+	return directives, nil
+}
+
+// parseValue parses a value, which may not reference variables if constant.
+// Values are converted to the argument value types built by the Argument functions where possible,
+// so that they are seen by the helpers looking into values, e.g. StringRedacted.
+func (p *parser) parseValue(constant bool) (argumentValue, error) {
+	l := p.peek()
+	switch {
+	case p.isPunctuator(tokenDollar) && !constant:
+		p.pos++
+		name, err := p.expectName()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return argVariable(name), nil
+	case p.is(TokenInt, ""):
+		p.pos++
+		if i, err := strconv.Atoi(l.Literal); err == nil {
+			return argInt(i), nil
+		}
+		return argTokens{l.Token}, nil
+	case p.is(TokenFloat, ""), p.is(TokenString, ""):
+		p.pos++
+		return argTokens{l.Token}, nil
+	case p.is(TokenName, ""):
+		p.pos++
+		switch l.Literal {
+		case tokenTrue, tokenFalse:
+			return argBool(l.Literal == tokenTrue), nil
+		case "null":
+			return argTokens{{TokenNull, l.Literal}}, nil
+		}
+		return argEnum(l.Literal), nil
+	case p.isPunctuator(tokenLSB):
+		p.pos++
+		list := argList{}
+		for !p.isPunctuator(tokenRSB) {
+			v, err := p.parseValue(constant)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			list = append(list, v)
+		}
+		p.pos++
+		return list, nil
+	case p.isPunctuator(tokenLB):
+		p.pos++
+		object := argumentCustom{}
+		for !p.isPunctuator(tokenRB) {
+			name, err := p.expectName()
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			if _, err := p.expect(TokenPunctuator, tokenColumn); err != nil {
+				return nil, errors.WithStack(err)
+			}
+			v, err := p.parseValue(constant)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			object = append(object, Argument{name, v})
+		}
+		p.pos++
+		return object, nil
+	}
+	return nil, p.unexpected("a value")
+}
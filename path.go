@@ -0,0 +1,97 @@
+package graphb
+
+import "strings"
+
+// FieldForPath returns the field which produced the response value at path, the "path" of a GraphQL error.
+// String elements are response keys, that is aliases or names. Other elements are list indices and are skipped.
+// Fields of inline fragments are looked up as if they were selected by the enclosing field.
+func (q *Query) FieldForPath(path []interface{}) (*Field, bool) {
+	fields := q.Fields
+	var found *Field
+	for _, p := range path {
+		key, ok := p.(string)
+		if !ok {
+			continue
+		}
+		found = findResponseKey(fields, key)
+		if found == nil {
+			return nil, false
+		}
+		fields = found.Fields
+	}
+	return found, found != nil
+}
+
+// findResponseKey finds the field of the response key among fields, looking into inline fragments.
+func findResponseKey(fields []*Field, key string) *Field {
+	for _, f := range fields {
+		if f == nil {
+			continue
+		}
+		if f.isInlineFragment() {
+			if found := findResponseKey(f.Fields, key); found != nil {
+				return found
+			}
+			continue
+		}
+		if f.responseKey() == key {
+			return f
+		}
+	}
+	return nil
+}
+
+// responseKey is the key of this Field in the response.
+func (f *Field) responseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+func (f *Field) isInlineFragment() bool {
+	return validInlineFragment.MatchString(f.Name)
+}
+
+// LeafPaths returns the paths of field names to the leaf fields selected by this Query, in selection order and without duplicates,
+// e.g. for a resolver to read only the columns requested. Paths use names rather than aliases, inline fragments and fragment spreads
+// are flattened into the enclosing field, and meta fields such as __typename are left out.
+func (q *Query) LeafPaths() [][]string {
+	return leafPaths(q.Fields)
+}
+
+// LeafPaths returns the paths of field names to the leaf fields selected under this Field, see Query.LeafPaths.
+// A leaf Field has a single empty path.
+func (f *Field) LeafPaths() [][]string {
+	if len(f.Fields) == 0 {
+		return [][]string{{}}
+	}
+	return leafPaths(f.Fields)
+}
+
+func leafPaths(fields []*Field) [][]string {
+	var paths [][]string
+	seen := make(map[string]bool)
+	visiting := make(map[*Field]bool)
+	var walk func(fields []*Field, prefix []string)
+	walk = func(fields []*Field, prefix []string) {
+		for _, f := range fields {
+			if f == nil || visiting[f] || strings.HasPrefix(f.Name, "__") {
+				continue
+			}
+			visiting[f] = true
+			switch path := append(prefix[:len(prefix):len(prefix)], f.Name); {
+			case f.isInlineFragment():
+				walk(f.Fields, prefix)
+			case len(f.Fields) > 0:
+				walk(f.Fields, path)
+			case !seen[strings.Join(path, ".")]:
+				seen[strings.Join(path, ".")] = true
+				paths = append(paths, path)
+			}
+			visiting[f] = false
+		}
+	}
+	walk(fields, nil)
+	return paths
+}
@@ -0,0 +1,70 @@
+package graphb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery_FieldForPath(t *testing.T) {
+	name := MakeField("name")
+	bio := MakeField("bio")
+	author := MakeField("user").SetAlias("author").SetFields(name)
+	q := MakeQuery(TypeQuery).SetFields(
+		MakeField("posts").SetFields(
+			author,
+			MakeField("... on Article").SetFields(bio),
+		),
+	)
+
+	f, ok := q.FieldForPath([]interface{}{"posts", float64(1), "author", "name"})
+	assert.True(t, ok)
+	assert.True(t, f == name)
+
+	f, ok = q.FieldForPath([]interface{}{"posts", 0, "author"})
+	assert.True(t, ok)
+	assert.True(t, f == author)
+
+	f, ok = q.FieldForPath([]interface{}{"posts", 0, "bio"})
+	assert.True(t, ok)
+	assert.True(t, f == bio)
+
+	f, ok = q.FieldForPath([]interface{}{"posts", 0, "user"})
+	assert.False(t, ok)
+	assert.Nil(t, f)
+
+	_, ok = q.FieldForPath(nil)
+	assert.False(t, ok)
+}
+
+func TestQuery_LeafPaths(t *testing.T) {
+	doc, err := ParseDocument(`
+query {
+  user(id: 1) {
+    __typename
+    id
+    handle: name
+    ...Contact
+    address { city, zip }
+    ... on Admin { id, role }
+  }
+}
+fragment Contact on User { email, address { city } }`)
+	assert.Nil(t, err)
+	q, _ := doc.Operation("")
+	assert.Equal(t, [][]string{
+		{"user", "id"},
+		{"user", "name"},
+		{"user", "email"},
+		{"user", "address", "city"},
+		{"user", "address", "zip"},
+		{"user", "role"},
+	}, q.LeafPaths())
+
+	assert.Equal(t, [][]string{{"id"}, {"name"}, {"email"}, {"address", "city"}, {"address", "zip"}, {"role"}}, q.Fields[0].LeafPaths())
+	assert.Equal(t, [][]string{{}}, MakeField("id").LeafPaths())
+
+	cyclic := MakeField("node")
+	cyclic.SetFields(MakeField("id"), cyclic)
+	assert.Equal(t, [][]string{{"node", "id"}}, MakeQuery(TypeQuery).SetFields(cyclic).LeafPaths())
+}
@@ -0,0 +1,152 @@
+package graphb
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// PersistedOperation is an operation of a persisted queries manifest.
+type PersistedOperation struct {
+	ID   string `json:"id"` // The Query.Hash of the operation.
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Body string `json:"body"`
+}
+
+// PersistedQueryManifest is an Apollo persisted queries manifest, which servers use to allowlist the operations of a client.
+type PersistedQueryManifest struct {
+	Format     string               `json:"format"`
+	Version    int                  `json:"version"`
+	Operations []PersistedOperation `json:"operations"`
+}
+
+// QueryRegistry collects the functions constructing the operations of a client, so that they can be persisted at deploy time.
+// It is safe for concurrent use.
+type QueryRegistry struct {
+	mu           sync.Mutex
+	constructors []func() *Query
+}
+
+// NewQueryRegistry returns an empty QueryRegistry.
+func NewQueryRegistry() *QueryRegistry {
+	return &QueryRegistry{}
+}
+
+// Register registers functions constructing operations. The operations must be named, with distinct names.
+func (r *QueryRegistry) Register(constructors ...func() *Query) {
+	r.mu.Lock()
+	r.constructors = append(r.constructors, constructors...)
+	r.mu.Unlock()
+}
+
+// Manifest constructs and serializes every registered operation into a manifest, sorted by operation name.
+func (r *QueryRegistry) Manifest() (PersistedQueryManifest, error) {
+	r.mu.Lock()
+	constructors := append([]func() *Query(nil), r.constructors...)
+	r.mu.Unlock()
+
+	manifest := PersistedQueryManifest{Format: "apollo-persisted-query-manifest", Version: 1, Operations: []PersistedOperation{}}
+	names := make(map[string]bool, len(constructors))
+	for _, constructor := range constructors {
+		q := constructor()
+		if q.Name == "" {
+			return manifest, errors.WithStack(InvalidNameErr{operationName, q.Name})
+		}
+		if names[q.Name] {
+			return manifest, errors.WithStack(DuplicateOperationErr{q.Name})
+		}
+		names[q.Name] = true
+		id, err := q.Hash()
+		if err != nil {
+			return manifest, errors.WithStack(err)
+		}
+		strCh, err := q.StringChan()
+		if err != nil {
+			return manifest, errors.WithStack(err)
+		}
+		manifest.Operations = append(manifest.Operations, PersistedOperation{
+			ID:   id,
+			Name: q.Name,
+			Type: string(q.Type.Canonical()),
+			Body: StringFromChan(strCh),
+		})
+	}
+	sort.Slice(manifest.Operations, func(i, j int) bool {
+		return manifest.Operations[i].Name < manifest.Operations[j].Name
+	})
+	return manifest, nil
+}
+
+// WriteManifest writes the JSON of the Manifest to w, e.g. a file uploaded to the server when deploying.
+func (r *QueryRegistry) WriteManifest(w io.Writer) error {
+	manifest, err := r.Manifest()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return errors.WithStack(enc.Encode(manifest))
+}
+
+// LoadManifest reads the persisted queries manifest at path, e.g. one written by QueryRegistry.WriteManifest,
+// to refuse sending the operations which are not in it, see WithManifest.
+func LoadManifest(path string) (PersistedQueryManifest, error) {
+	var manifest PersistedQueryManifest
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return manifest, errors.WithStack(err)
+	}
+	return manifest, errors.WithStack(json.Unmarshal(b, &manifest))
+}
+
+// Allows returns an OperationNotPersistedErr if the Query.Hash of q is not the ID of an operation of this manifest.
+func (m PersistedQueryManifest) Allows(q *Query) error {
+	return checkPersisted(q, m.ids())
+}
+
+func (m PersistedQueryManifest) ids() map[string]bool {
+	ids := make(map[string]bool, len(m.Operations))
+	for _, op := range m.Operations {
+		ids[op.ID] = true
+	}
+	return ids
+}
+
+func checkPersisted(q *Query, ids map[string]bool) error {
+	id, err := q.Hash()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if !ids[id] {
+		return errors.WithStack(OperationNotPersistedErr{q.Name, id})
+	}
+	return nil
+}
+
+// WithManifest makes Query.NewRequest refuse, with an OperationNotPersistedErr, the operations which are not in manifest,
+// catching ad-hoc queries in environments where the server only accepts persisted ones.
+func WithManifest(manifest PersistedQueryManifest) RequestOption {
+	ids := manifest.ids()
+	return func(o *requestOptions) {
+		o.persisted = ids
+	}
+}
+
+// WithManifestCheck returns an ExecuteFunc which refuses, with an OperationNotPersistedErr, the operations which are not in manifest,
+// and executes the other ones with execute.
+func WithManifestCheck(execute ExecuteFunc, manifest PersistedQueryManifest) ExecuteFunc {
+	ids := manifest.ids()
+	return func(ctx context.Context, q *Query) (json.RawMessage, error) {
+		if err := checkPersisted(q, ids); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return execute(ctx, q)
+	}
+}
@@ -0,0 +1,89 @@
+package graphb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryRegistry(t *testing.T) {
+	r := NewQueryRegistry()
+	getUser := func() *Query {
+		return MakeQuery(TypeQuery).SetName("GetUser").SetFields(MakeField("user").SetFields(MakeField("id")))
+	}
+	like := func() *Query {
+		return MakeQuery(TypeMutation).SetName("Like").SetFields(MakeField("like"))
+	}
+	r.Register(like, getUser)
+
+	var buf bytes.Buffer
+	assert.Nil(t, r.WriteManifest(&buf))
+	hash, _ := getUser().Hash()
+	assert.Contains(t, buf.String(), `"id": "`+hash+`"`)
+
+	manifest, err := r.Manifest()
+	assert.Nil(t, err)
+	assert.Equal(t, "apollo-persisted-query-manifest", manifest.Format)
+	assert.Equal(t, 1, manifest.Version)
+	assert.Equal(t, []PersistedOperation{
+		{hash, "GetUser", "query", "query GetUser{user{id}}"},
+		{manifest.Operations[1].ID, "Like", "mutation", "mutation Like{like}"},
+	}, manifest.Operations)
+
+	r.Register(getUser)
+	_, err = r.Manifest()
+	assert.Equal(t, DuplicateOperationErr{"GetUser"}, errors.Cause(err))
+
+	anonymous := NewQueryRegistry()
+	anonymous.Register(func() *Query { return MakeQuery(TypeQuery).SetFields(MakeField("me")) })
+	_, err = anonymous.Manifest()
+	assert.Equal(t, InvalidNameErr{operationName, ""}, errors.Cause(err))
+}
+
+func TestLoadManifest(t *testing.T) {
+	r := NewQueryRegistry()
+	getUser := func() *Query {
+		return MakeQuery(TypeQuery).SetName("GetUser").SetFields(MakeField("user").SetFields(MakeField("id")))
+	}
+	r.Register(getUser)
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	f, err := os.Create(path)
+	assert.Nil(t, err)
+	assert.Nil(t, r.WriteManifest(f))
+	assert.Nil(t, f.Close())
+
+	manifest, err := LoadManifest(path)
+	assert.Nil(t, err)
+	assert.Len(t, manifest.Operations, 1)
+	adHoc := MakeQuery(TypeQuery).SetName("GetUser").SetFields(MakeField("user").SetFields(MakeField("id"), MakeField("email")))
+	id, err := adHoc.Hash()
+	assert.Nil(t, err)
+	assert.Nil(t, manifest.Allows(getUser()))
+	assert.Equal(t, OperationNotPersistedErr{"GetUser", id}, errors.Cause(manifest.Allows(adHoc)))
+
+	_, err = getUser().NewRequest(context.Background(), http.MethodPost, "http://example.com/graphql", WithManifest(manifest))
+	assert.Nil(t, err)
+	_, err = adHoc.NewRequest(context.Background(), http.MethodPost, "http://example.com/graphql", WithManifest(manifest))
+	assert.Equal(t, OperationNotPersistedErr{"GetUser", id}, errors.Cause(err))
+
+	calls := 0
+	execute := WithManifestCheck(func(ctx context.Context, q *Query) (json.RawMessage, error) {
+		calls++
+		return json.RawMessage(`{}`), nil
+	}, manifest)
+	_, err = execute(context.Background(), getUser())
+	assert.Nil(t, err)
+	_, err = execute(context.Background(), adHoc)
+	assert.Equal(t, OperationNotPersistedErr{"GetUser", id}, errors.Cause(err))
+	assert.Equal(t, 1, calls)
+
+	_, err = LoadManifest(filepath.Join(t.TempDir(), "missing.json"))
+	assert.True(t, os.IsNotExist(errors.Cause(err)))
+}
@@ -0,0 +1,97 @@
+package graphb
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// postmanSchema is the schema of the collections written by WritePostmanCollection.
+const postmanSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+type postmanCollection struct {
+	Info struct {
+		Name   string `json:"name"`
+		Schema string `json:"schema"`
+	} `json:"info"`
+	Item []postmanItem `json:"item"`
+}
+
+type postmanItem struct {
+	Name    string `json:"name"`
+	Request struct {
+		Method string          `json:"method"`
+		Header []postmanHeader `json:"header"`
+		URL    struct {
+			Raw string `json:"raw"`
+		} `json:"url"`
+		Body struct {
+			Mode    string `json:"mode"`
+			GraphQL struct {
+				Query     string `json:"query"`
+				Variables string `json:"variables"`
+			} `json:"graphql"`
+		} `json:"body"`
+	} `json:"request"`
+}
+
+type postmanHeader struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// WritePostmanCollection writes a Postman collection, in the v2.1 format which Insomnia imports too, named name,
+// with a GraphQL request to endpoint for each of queries, so that one can try the operations a service sends.
+// Each request is named after its operation, has the headers of its query and the values of its variables as sample values.
+// The queries are pretty printed, see StylePretty.
+func WritePostmanCollection(w io.Writer, name, endpoint string, queries ...*Query) error {
+	var collection postmanCollection
+	collection.Info.Name = name
+	collection.Info.Schema = postmanSchema
+	collection.Item = make([]postmanItem, len(queries))
+	for i, q := range queries {
+		item := &collection.Item[i]
+		item.Name = q.Name
+		if item.Name == "" {
+			item.Name = string(q.Type.Canonical()) + " " + strconv.Itoa(i+1)
+		}
+		pretty := *q
+		config := NewConfig()
+		if q.Config != nil {
+			copied := *q.Config
+			config = &copied
+		}
+		config.Style = StylePretty
+		pretty.Config = config
+		strCh, err := pretty.StringChan()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		item.Request.Method = "POST"
+		item.Request.URL.Raw = endpoint
+		item.Request.Header = []postmanHeader{{"Content-Type", ContentTypeJSON}}
+		keys := make([]string, 0, len(q.Headers))
+		for k := range q.Headers {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			item.Request.Header = append(item.Request.Header, postmanHeader{k, q.Headers[k]})
+		}
+		item.Request.Body.Mode = "graphql"
+		item.Request.Body.GraphQL.Query = StringFromChan(strCh)
+		variables, err := json.MarshalIndent(q.variableValues(), "", "  ")
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		item.Request.Body.GraphQL.Variables = string(variables)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return errors.WithStack(enc.Encode(collection))
+}
@@ -0,0 +1,56 @@
+package graphb
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWritePostmanCollection(t *testing.T) {
+	getUser := MakeQuery(TypeQuery).
+		SetName("GetUser").
+		AddVariables(Variable{Name: "id", Type: "ID!", Value: "1"}).
+		SetFields(MakeField("user").SetArguments(ArgumentVariable("id", "id")).SetFields(MakeField("name"))).
+		AddHeader("X-Tenant", "a")
+	like := MakeQuery(TypeMutation).SetFields(MakeField("like"))
+
+	var buf bytes.Buffer
+	assert.Nil(t, WritePostmanCollection(&buf, "Users", "https://example.com/graphql", getUser, like))
+	var collection map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &collection))
+	expected := `{
+	"info": {"name": "Users", "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"},
+	"item": [
+		{
+			"name": "GetUser",
+			"request": {
+				"method": "POST",
+				"header": [{"key": "Content-Type", "value": "application/json"}, {"key": "X-Tenant", "value": "a"}],
+				"url": {"raw": "https://example.com/graphql"},
+				"body": {"mode": "graphql", "graphql": {
+					"query": "query GetUser($id: ID!) {\n  user(id: $id) {\n    name\n  }\n}",
+					"variables": "{\n  \"id\": \"1\"\n}"
+				}}
+			}
+		},
+		{
+			"name": "mutation 2",
+			"request": {
+				"method": "POST",
+				"header": [{"key": "Content-Type", "value": "application/json"}],
+				"url": {"raw": "https://example.com/graphql"},
+				"body": {"mode": "graphql", "graphql": {"query": "mutation {\n  like\n}", "variables": "{}"}}
+			}
+		}
+	]
+}`
+	assert.JSONEq(t, expected, buf.String())
+	// the queries are left untouched
+	assert.Nil(t, getUser.Config)
+
+	err := WritePostmanCollection(&buf, "Broken", "https://example.com/graphql", MakeQuery(TypeQuery).SetFields(nil))
+	assert.Equal(t, NilFieldErr{}, errors.Cause(err))
+}
@@ -0,0 +1,112 @@
+package graphb
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Priority is how a client schedules an operation, see WithPriorityRouting.
+type Priority int
+
+const (
+	PriorityNormal Priority = iota // The priority of operations not tagged otherwise.
+	PriorityHigh                   // E.g. the operations a user waits for.
+	PriorityLow                    // E.g. analytics and batch operations.
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	case PriorityLow:
+		return "low"
+	}
+	return "unknown"
+}
+
+// SetPriority sets the Priority of this Query.
+func (q *Query) SetPriority(p Priority) *Query {
+	q.Priority = p
+	return q
+}
+
+// SetTimeout sets the Timeout of this Query.
+func (q *Query) SetTimeout(timeout time.Duration) *Query {
+	q.Timeout = timeout
+	return q
+}
+
+// OfPriority returns a QueryOption which sets the Priority of a query.
+func OfPriority(p Priority) QueryOption {
+	return func(query *Query) error {
+		query.Priority = p
+		return nil
+	}
+}
+
+// OfTimeout returns a QueryOption which sets the Timeout of a query.
+func OfTimeout(timeout time.Duration) QueryOption {
+	return func(query *Query) error {
+		query.Timeout = timeout
+		return nil
+	}
+}
+
+// PriorityRouting configures WithPriorityRouting. Each map is keyed by priority, and a missing priority gets the default.
+type PriorityRouting struct {
+	// Executes are the ExecuteFunc(s) of the priorities, e.g. sending over http.Client(s) of separate connection pools,
+	// the ExecuteFunc given to WithPriorityRouting by default.
+	Executes map[Priority]ExecuteFunc
+	// Limits are the numbers of operations of the priorities executed at once, unlimited by default.
+	// Operations over the limit wait for a slot, or until their context is done.
+	Limits map[Priority]int
+	// Timeouts are the timeouts of the operations of the priorities without a Timeout of their own, none by default.
+	Timeouts map[Priority]time.Duration
+	// Header, unless empty, is set to the priority of each operation, e.g. "X-Priority: low", for the server or a proxy to apply QoS.
+	Header string
+}
+
+// WithPriorityRouting returns an ExecuteFunc which executes queries according to their Priority and Timeout:
+// through the ExecuteFunc and within the limit of their priority, so that low priority operations can not starve the others,
+// with a context canceled after their timeout, and with a header telling their priority.
+// The header is set on a copy of the query.
+func WithPriorityRouting(execute ExecuteFunc, routing PriorityRouting) ExecuteFunc {
+	slots := make(map[Priority]chan struct{}, len(routing.Limits))
+	for p, limit := range routing.Limits {
+		if limit > 0 {
+			slots[p] = make(chan struct{}, limit)
+		}
+	}
+	return func(ctx context.Context, q *Query) (json.RawMessage, error) {
+		timeout := q.Timeout
+		if timeout == 0 {
+			timeout = routing.Timeouts[q.Priority]
+		}
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		if slot, ok := slots[q.Priority]; ok {
+			select {
+			case slot <- struct{}{}:
+				defer func() { <-slot }()
+			case <-ctx.Done():
+				return nil, errors.WithStack(ctx.Err())
+			}
+		}
+		if routing.Header != "" {
+			q = withCopiedHeaders(q)
+			q.Headers[routing.Header] = q.Priority.String()
+		}
+		if routed, ok := routing.Executes[q.Priority]; ok {
+			return routed(ctx, q)
+		}
+		return execute(ctx, q)
+	}
+}
@@ -0,0 +1,74 @@
+package graphb
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithPriorityRouting(t *testing.T) {
+	pool := func(name string) ExecuteFunc {
+		return func(ctx context.Context, q *Query) (json.RawMessage, error) {
+			_, hasDeadline := ctx.Deadline()
+			b, _ := json.Marshal(map[string]interface{}{"pool": name, "priority": q.Headers["X-Priority"], "deadline": hasDeadline})
+			return b, nil
+		}
+	}
+	execute := WithPriorityRouting(pool("default"), PriorityRouting{
+		Executes: map[Priority]ExecuteFunc{PriorityLow: pool("analytics")},
+		Timeouts: map[Priority]time.Duration{PriorityLow: time.Minute},
+		Header:   "X-Priority",
+	})
+
+	q := MakeQuery(TypeQuery).SetFields(MakeField("me"))
+	data, err := execute(context.Background(), q)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"pool":"default","priority":"normal","deadline":false}`, string(data))
+
+	data, err = execute(context.Background(), NewQuery(TypeQuery, OfPriority(PriorityLow), OfField("events")))
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"pool":"analytics","priority":"low","deadline":true}`, string(data))
+
+	data, err = execute(context.Background(), q.SetPriority(PriorityHigh).SetTimeout(time.Second))
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"pool":"default","priority":"high","deadline":true}`, string(data))
+	// the header is set on a copy
+	assert.Empty(t, q.Headers)
+}
+
+func TestWithPriorityRouting_limits(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	execute := WithPriorityRouting(func(ctx context.Context, q *Query) (json.RawMessage, error) {
+		started <- struct{}{}
+		<-release
+		return nil, nil
+	}, PriorityRouting{Limits: map[Priority]int{PriorityLow: 1}})
+
+	low := MakeQuery(TypeQuery).SetFields(MakeField("events")).SetPriority(PriorityLow)
+	done := make(chan error)
+	go func() {
+		_, err := execute(context.Background(), low)
+		done <- err
+	}()
+	<-started
+
+	// a second low priority operation waits for the slot until its timeout
+	_, err := execute(context.Background(), MakeQuery(TypeQuery).SetFields(MakeField("events")).SetPriority(PriorityLow).SetTimeout(10*time.Millisecond))
+	assert.Equal(t, context.DeadlineExceeded, errors.Cause(err))
+
+	// other priorities are not limited
+	go func() {
+		_, err := execute(context.Background(), MakeQuery(TypeQuery).SetFields(MakeField("me")))
+		done <- err
+	}()
+	<-started
+	release <- struct{}{}
+	release <- struct{}{}
+	assert.Nil(t, <-done)
+	assert.Nil(t, <-done)
+}
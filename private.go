@@ -2,27 +2,44 @@ package graphb
 
 import (
 	"regexp"
-	"strings"
+	"unicode/utf8"
 )
 
 // checks the validity of a name according to the spec: http://facebook.github.io/graphql/October2016/#sec-Names
 var validName = regexp.MustCompile("^[_A-Za-z][_0-9A-Za-z]*$")
 
 // check the validity of an inline fragment as a name according to the spec: https://graphql.github.io/graphql-spec/June2018/#sec-Inline-Fragments
-var validInlineFragment = regexp.MustCompile("^... on [_A-Za-z][_0-9A-Za-z]*$")
+// the type condition is optional, for example to group fields under a directive: "... @include(if: $expanded) { ... }"
+var validInlineFragment = regexp.MustCompile(`^\.\.\.( on [_A-Za-z][_0-9A-Za-z]*)?$`)
 
-func isValidOperationType(Type operationType) bool {
-	low := strings.ToLower(string(Type))
-	return low == "query" || low == "mutation" || low == "subscription"
+// invalidNameRune returns the first rune of name breaking the Name grammar and its byte offset,
+// or an offset of -1 if name is valid. An empty name breaks the grammar at offset 0 with no rune, utf8.RuneError.
+func invalidNameRune(name string) (rune, int) {
+	if name == "" {
+		return utf8.RuneError, 0
+	}
+	for i, r := range name {
+		letter := r == '_' || (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z')
+		digit := r >= '0' && r <= '9'
+		if !letter && !(digit && i > 0) {
+			return r, i
+		}
+	}
+	return 0, -1
 }
 
 const (
 	// syntax tokens
-	tokenLB     = "{" // Left Brace
-	tokenRB     = "}" // Right Brace
-	tokenLP     = "(" // Left Parenthesis
-	tokenRP     = ")" // Right Parenthesis
-	tokenColumn = ":"
-	tokenComma  = ","
+	tokenLB     = string(PunctuatorLeftBrace)
+	tokenRB     = string(PunctuatorRightBrace)
+	tokenLP     = string(PunctuatorLeftParenthesis)
+	tokenRP     = string(PunctuatorRightParenthesis)
+	tokenLSB    = string(PunctuatorLeftBracket)
+	tokenRSB    = string(PunctuatorRightBracket)
+	tokenColumn = string(PunctuatorColon)
+	tokenComma  = string(PunctuatorComma)
 	tokenSpace  = " "
+	tokenDollar = "$"
+	tokenAt     = "@"
+	tokenEquals = string(PunctuatorEquals)
 )
@@ -0,0 +1,80 @@
+package graphb
+
+import (
+	"strings"
+	"testing"
+	"testing/quick"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	nameStart = "_ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	nameRest  = nameStart + "0123456789"
+)
+
+// makeName maps arbitrary bytes to a valid name.
+func makeName(b []byte) string {
+	if len(b) == 0 {
+		return "_"
+	}
+	name := []byte{nameStart[int(b[0])%len(nameStart)]}
+	for _, c := range b[1:] {
+		name = append(name, nameRest[int(c)%len(nameRest)])
+	}
+	return string(name)
+}
+
+func TestInvalidNameRune_validNames(t *testing.T) {
+	valid := func(b []byte) bool {
+		name := makeName(b)
+		_, offset := invalidNameRune(name)
+		return offset == -1 && validName.MatchString(name)
+	}
+	assert.Nil(t, quick.Check(valid, nil))
+}
+
+func TestInvalidNameRune_insertedRune(t *testing.T) {
+	// inserting a rune outside of the grammar into a valid name makes it invalid at the insertion offset
+	invalid := func(b []byte, at uint, r rune) bool {
+		if !utf8.ValidRune(r) || strings.ContainsRune(nameRest, r) {
+			return true
+		}
+		name := makeName(b)
+		i := int(at % uint(len(name)+1))
+		name = name[:i] + string(r) + name[i:]
+		got, offset := invalidNameRune(name)
+		return got == r && offset == i && !validName.MatchString(name)
+	}
+	assert.Nil(t, quick.Check(invalid, nil))
+}
+
+func TestInvalidNameRune_agreesWithValidName(t *testing.T) {
+	agree := func(name string) bool {
+		_, offset := invalidNameRune(name)
+		return (offset < 0) == validName.MatchString(name)
+	}
+	assert.Nil(t, quick.Check(agree, nil))
+}
+
+func TestInvalidNameErr_Error(t *testing.T) {
+	cases := []struct {
+		name   string
+		reason string
+	}{
+		{"", "it is empty"},
+		{"9lives", "unexpected '9' (U+0039) at byte offset 0"},
+		{"user-name", "unexpected '-' (U+002D) at byte offset 4"},
+		{"naïve", "unexpected 'ï' (U+00EF) at byte offset 2"},
+		{"a\xffb", "invalid UTF-8 byte 0xff at byte offset 1"},
+		{"a�", "unexpected '�' (U+FFFD) at byte offset 1"},
+	}
+	for _, c := range cases {
+		err := InvalidNameErr{fieldName, c.name}
+		assert.Contains(t, err.Error(), "is an invalid field name in GraphQL: "+c.reason+". ")
+	}
+	r, offset := InvalidNameErr{fieldName, "naïve"}.InvalidRune()
+	assert.Equal(t, 'ï', r)
+	assert.Equal(t, 2, offset)
+}
@@ -0,0 +1,119 @@
+package graphb
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ContextHeader maps the string value of a context key, e.g. a tenant or a trace ID, to a header of the queries sent.
+type ContextHeader struct {
+	Header string
+	Key    interface{}
+}
+
+// AuthProvider provides the token sent in the Authorization header of the queries, and when it expires.
+// A zero expiry means the token does not expire.
+type AuthProvider interface {
+	Token(ctx context.Context) (token string, expiry time.Time, err error)
+}
+
+// CachingAuthProvider caches the token of Provider until RefreshBefore its expiry.
+// It is safe for concurrent use, concurrent callers wait for a single refresh.
+type CachingAuthProvider struct {
+	Provider      AuthProvider
+	RefreshBefore time.Duration
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+	cached bool
+	now    func() time.Time // for tests
+}
+
+// Token implements AuthProvider.
+func (p *CachingAuthProvider) Token(ctx context.Context) (string, time.Time, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now
+	if p.now != nil {
+		now = p.now
+	}
+	if p.cached && (p.expiry.IsZero() || now().Add(p.RefreshBefore).Before(p.expiry)) {
+		return p.token, p.expiry, nil
+	}
+	token, expiry, err := p.Provider.Token(ctx)
+	if err != nil {
+		return "", time.Time{}, errors.WithStack(err)
+	}
+	p.token, p.expiry, p.cached = token, expiry, true
+	return token, expiry, nil
+}
+
+// Invalidate drops the cached token, e.g. after the server rejected it, so that the next call to Token refreshes it.
+func (p *CachingAuthProvider) Invalidate() {
+	p.mu.Lock()
+	p.cached = false
+	p.mu.Unlock()
+}
+
+// WithContextHeaders returns an ExecuteFunc which, before calling execute, sets the headers mapped by headers
+// from the values of the call's context, and the "Authorization: Bearer" header with a token of auth if not nil.
+// The headers are set on a copy of the query, which is safe to execute concurrently.
+func WithContextHeaders(execute ExecuteFunc, auth AuthProvider, headers ...ContextHeader) ExecuteFunc {
+	return func(ctx context.Context, q *Query) (json.RawMessage, error) {
+		copied := withCopiedHeaders(q)
+		for _, h := range headers {
+			if v, ok := ctx.Value(h.Key).(string); ok && v != "" {
+				copied.Headers[h.Header] = v
+			}
+		}
+		if auth != nil {
+			token, _, err := auth.Token(ctx)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			copied.Headers["Authorization"] = "Bearer " + token
+		}
+		return execute(ctx, copied)
+	}
+}
+
+// DefaultDeadlineHeader is the header set by WithDeadlineHeader when none is given.
+const DefaultDeadlineHeader = "X-Request-Timeout-Ms"
+
+// WithDeadlineHeader returns an ExecuteFunc which, when the call's context has a deadline,
+// sets header, DefaultDeadlineHeader if empty, to the milliseconds remaining before it so that the server can honor the client budget.
+// A call whose deadline already passed fails without calling execute.
+func WithDeadlineHeader(execute ExecuteFunc, header string) ExecuteFunc {
+	if header == "" {
+		header = DefaultDeadlineHeader
+	}
+	return func(ctx context.Context, q *Query) (json.RawMessage, error) {
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			return execute(ctx, q)
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, errors.WithStack(context.DeadlineExceeded)
+		}
+		copied := withCopiedHeaders(q)
+		copied.Headers[header] = strconv.FormatInt(int64(remaining/time.Millisecond), 10)
+		return execute(ctx, copied)
+	}
+}
+
+// withCopiedHeaders returns a copy of q whose headers can be set without modifying q.
+func withCopiedHeaders(q *Query) *Query {
+	copied := *q
+	copied.Headers = make(map[string]string, len(q.Headers)+1)
+	for k, v := range q.Headers {
+		copied.Headers[k] = v
+	}
+	return &copied
+}
@@ -0,0 +1,121 @@
+package graphb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type contextKey string
+
+type countingAuthProvider struct {
+	mu     sync.Mutex
+	calls  int
+	expiry time.Time
+	err    error
+}
+
+func (p *countingAuthProvider) Token(ctx context.Context) (string, time.Time, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return "", time.Time{}, p.err
+	}
+	p.calls++
+	return fmt.Sprintf("token%d", p.calls), p.expiry, nil
+}
+
+func TestCachingAuthProvider(t *testing.T) {
+	now := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	provider := &countingAuthProvider{expiry: now.Add(time.Hour)}
+	p := &CachingAuthProvider{Provider: provider, RefreshBefore: time.Minute, now: func() time.Time { return now }}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, _, err := p.Token(context.Background())
+			assert.Nil(t, err)
+			assert.Equal(t, "token1", token)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, provider.calls)
+
+	now = now.Add(59*time.Minute + time.Second)
+	token, _, err := p.Token(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "token2", token)
+
+	p.Invalidate()
+	token, _, _ = p.Token(context.Background())
+	assert.Equal(t, "token3", token)
+
+	provider.err = fmt.Errorf("unauthorized")
+	p.Invalidate()
+	_, _, err = p.Token(context.Background())
+	assert.Equal(t, provider.err, errors.Cause(err))
+}
+
+func TestWithContextHeaders(t *testing.T) {
+	var got map[string]string
+	execute := func(ctx context.Context, q *Query) (json.RawMessage, error) {
+		got = q.Headers
+		return json.RawMessage(`{}`), nil
+	}
+	auth := &CachingAuthProvider{Provider: &countingAuthProvider{}}
+	wrapped := WithContextHeaders(execute, auth,
+		ContextHeader{"X-Tenant-ID", contextKey("tenant")},
+		ContextHeader{"X-Trace-ID", contextKey("trace")},
+	)
+
+	q := MakeQuery(TypeQuery).SetFields(MakeField("me")).AddHeader("X-Client", "graphb")
+	ctx := context.WithValue(context.Background(), contextKey("tenant"), "acme")
+	_, err := wrapped(ctx, q)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"X-Client": "graphb", "X-Tenant-ID": "acme", "Authorization": "Bearer token1"}, got)
+	assert.Equal(t, map[string]string{"X-Client": "graphb"}, q.Headers)
+
+	_, err = WithContextHeaders(execute, &countingAuthProvider{err: fmt.Errorf("down")})(ctx, q)
+	assert.NotNil(t, err)
+}
+
+func TestWithDeadlineHeader(t *testing.T) {
+	var sent map[string]string
+	execute := func(ctx context.Context, q *Query) (json.RawMessage, error) {
+		sent = q.Headers
+		return nil, nil
+	}
+	q := MakeQuery(TypeQuery).AddHeader("Accept", "application/json")
+
+	_, err := WithDeadlineHeader(execute, "")(context.Background(), q)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"Accept": "application/json"}, sent)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	_, err = WithDeadlineHeader(execute, "")(ctx, q)
+	assert.Nil(t, err)
+	ms, err := strconv.Atoi(sent[DefaultDeadlineHeader])
+	assert.Nil(t, err)
+	assert.True(t, ms > 59*60*1000 && ms <= 60*60*1000, ms)
+	assert.Equal(t, map[string]string{"Accept": "application/json"}, q.Headers, "the query is not modified")
+
+	WithDeadlineHeader(execute, "Grpc-Timeout")(ctx, q)
+	assert.Contains(t, sent, "Grpc-Timeout")
+
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	sent = nil
+	_, err = WithDeadlineHeader(execute, "")(expired, q)
+	assert.Equal(t, context.DeadlineExceeded, errors.Cause(err))
+	assert.Nil(t, sent)
+}
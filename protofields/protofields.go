@@ -0,0 +1,41 @@
+// Package protofields derives graphb selection sets from protobuf messages,
+// for teams whose internal models are protos and whose GraphQL schema mirrors them.
+package protofields
+
+import (
+	"strings"
+
+	"github.com/udacity/graphb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// wellKnownPrefix is the package of the well-known types, which GraphQL schemas usually expose as scalars.
+const wellKnownPrefix = "google.protobuf."
+
+// FieldsFromProto returns the selection set mirroring the fields of msg, named by their json_name.
+// Message fields are selected recursively, except for the well-known types and maps which are selected as leaves,
+// and fields whose message type is already being selected by an enclosing field, which would recurse forever.
+func FieldsFromProto(msg proto.Message) []*graphb.Field {
+	desc := msg.ProtoReflect().Descriptor()
+	return fieldsOf(desc, map[protoreflect.FullName]bool{desc.FullName(): true})
+}
+
+func fieldsOf(desc protoreflect.MessageDescriptor, path map[protoreflect.FullName]bool) []*graphb.Field {
+	fds := desc.Fields()
+	fields := make([]*graphb.Field, 0, fds.Len())
+	for i := 0; i < fds.Len(); i++ {
+		fd := fds.Get(i)
+		f := graphb.MakeField(fd.JSONName())
+		if sub := fd.Message(); sub != nil && !fd.IsMap() && !strings.HasPrefix(string(sub.FullName()), wellKnownPrefix) {
+			if path[sub.FullName()] {
+				continue
+			}
+			path[sub.FullName()] = true
+			f.SetFields(fieldsOf(sub, path)...)
+			delete(path, sub.FullName())
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
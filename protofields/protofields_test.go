@@ -0,0 +1,65 @@
+package protofields
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/udacity/graphb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	_ "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func field(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, typeName string) *descriptorpb.FieldDescriptorProto {
+	f := &descriptorpb.FieldDescriptorProto{
+		Name:   proto.String(name),
+		Number: proto.Int32(number),
+		Type:   typ.Enum(),
+		Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+	}
+	if typeName != "" {
+		f.TypeName = proto.String(typeName)
+	}
+	return f
+}
+
+func TestFieldsFromProto(t *testing.T) {
+	displayName := field("display_name", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, "")
+	displayName.JsonName = proto.String("nickname")
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("user.proto"),
+		Package:    proto.String("test"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/timestamp.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("User"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("user_id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+					displayName,
+					field("address", 3, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".test.Address"),
+					field("created_at", 4, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".google.protobuf.Timestamp"),
+					field("manager", 5, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".test.User"),
+				},
+			},
+			{
+				Name: proto.String("Address"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("street_line", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+					field("resident", 2, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".test.User"),
+				},
+			},
+		},
+	}
+	fd, err := protodesc.NewFile(file, protoregistry.GlobalFiles)
+	assert.Nil(t, err)
+	msg := dynamicpb.NewMessage(fd.Messages().ByName("User"))
+
+	q := graphb.MakeQuery(graphb.TypeQuery).SetFields(graphb.MakeField("user").SetFields(FieldsFromProto(msg)...))
+	s, err := q.JSON()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"query":"query{user{userId,nickname,address{streetLine},createdAt}}"}`, s)
+}
@@ -11,11 +11,11 @@ import (
 
 // StringFromChan builds a string from a channel, assuming the channel has been closed.
 func StringFromChan(c <-chan string) string {
-	var strs []string
+	var b strings.Builder
 	for str := range c {
-		strs = append(strs, str)
+		b.WriteString(str)
 	}
-	return strings.Join(strs, "")
+	return b.String()
 }
 
 ///////////////////
@@ -68,6 +68,7 @@ func OfAlias(alias string) FieldOption {
 func OfArguments(arguments ...Argument) FieldOption {
 	return func(f *Field) error {
 		f.Arguments = arguments
+		f.locateArguments()
 		return nil
 	}
 }
@@ -80,7 +81,7 @@ func OfArguments(arguments ...Argument) FieldOption {
 // On error, the pointer is nil.
 // Type is required.
 // Other options such as operation name and alias are optional.
-func NewQuery(Type operationType, options ...QueryOptionInterface) *Query {
+func NewQuery(Type OperationType, options ...QueryOptionInterface) *Query {
 	// todo: change to new style error handling
 	q := &Query{Type: Type, Headers: make(map[string]string)}
 
@@ -116,6 +117,19 @@ func OfName(name string) QueryOption {
 	}
 }
 
+// OfVariable returns a QueryOption which validates and adds a variable definition to a query.
+// name is the variable name without '$' and Type is a GraphQL type reference, e.g. OfVariable("id", "ID!").
+func OfVariable(name string, Type string) QueryOption {
+	return func(query *Query) error {
+		v := Variable{Name: name, Type: Type}
+		if err := v.check(); err != nil {
+			return errors.WithStack(err)
+		}
+		query.Variables = append(query.Variables, v)
+		return nil
+	}
+}
+
 ////////////////////////////
 // fieldContainer Factory //
 ////////////////////////////
@@ -1,8 +1,12 @@
 package graphb
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
 	"fmt"
-	"strings"
+	"sort"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -11,11 +15,22 @@ import (
 // Though all fields (Go struct field, not GraphQL field) of this struct is public,
 // the author recommends you to use functions in public.go.
 type Query struct {
-	Type   operationType // The operation type is either query, mutation, or subscription.
+	Type   OperationType // The operation type is either query, mutation, or subscription.
 	Name   string        // The operation name is a meaningful and explicit name for your operation.
 	Fields []*Field
 	E      error
 	Headers map[string]string
+	Extensions map[string]interface{} // Sent in the "extensions" member of requests, e.g. an idempotency key.
+	Variables []Variable // The variable definitions of the operation.
+	Directives []Directive // The directives of the operation, e.g. Live().
+	OperationOptions []OperationOption // Nonstandard tokens serialized around the operation.
+	Priority Priority // How the client schedules the operation, see WithPriorityRouting.
+	Timeout time.Duration // The time the operation may take, unless zero, see WithPriorityRouting.
+
+	FragmentDefinitions bool    // Whether registered fragments are serialized as fragment definitions, see OfFragmentDefinitions.
+	Config              *Config // Serialization conventions, see MakeQuery.
+
+	serialized *serialization // The string cached by String, nil when changed, see Invalidate.
 }
 
 // implements fieldContainer
@@ -25,6 +40,7 @@ func (q *Query) getFields() []*Field {
 
 func (q *Query) setFields(fs []*Field) {
 	q.Fields = fs
+	q.invalidate()
 }
 
 // StringChan returns a string channel and an error.
@@ -32,64 +48,246 @@ func (q *Query) setFields(fs []*Field) {
 // When error is nil, the channel is guaranteed to be closed.
 // Warning: One should never receive from a nil channel for eternity awaits by a nil channel.
 func (q *Query) StringChan() (<-chan string, error) {
-	ch := make(chan string)
-
-	if err := q.check(); err != nil {
+	if err := q.checkAll(); err != nil {
+		ch := make(chan string)
 		close(ch)
 		return ch, errors.WithStack(err)
 	}
+	return q.stringChan(), nil
+}
 
-	for _, f := range q.Fields {
-		if f == nil {
-			close(ch)
-			return ch, errors.WithStack(NilFieldErr{})
-		}
-		if err := f.check(); err != nil {
-			close(ch)
-			return ch, errors.WithStack(err)
-		}
+// Tokens returns a channel of typed tokens and an error.
+// It follows the same contract as StringChan, but each token carries its kind
+// so that one can highlight, reformat or measure a query without re-lexing it.
+func (q *Query) Tokens() (<-chan Token, error) {
+	if err := q.checkAll(); err != nil {
+		ch := make(chan Token)
+		close(ch)
+		return ch, errors.WithStack(err)
 	}
-	return q.stringChan(), nil
+	return q.tokenChan(), nil
 }
 
 // StringChan returns a read only channel which is guaranteed to be closed in the future.
 func (q *Query) stringChan() <-chan string {
-	tokenChan := make(chan string)
+	return literals(q.tokenChan())
+}
+
+// tokenChan returns a read only token channel which is guaranteed to be closed in the future.
+func (q *Query) tokenChan() <-chan Token {
+	tokenChan := make(chan Token)
+	var styled <-chan Token = tokenChan
+	if q.Config != nil {
+		styled = styleTokens(tokenChan, q.Config.Style)
+	}
 	go func() {
-		tokenChan <- strings.ToLower(string(q.Type))
-		// emit operation name
-		if q.Name != "" {
-			tokenChan <- tokenSpace
-			tokenChan <- q.Name
-		}
-		// emit fields
-		tokenChan <- tokenLB
-		for i, field := range q.Fields {
-			if i != 0 {
-				tokenChan <- tokenComma
-			}
-			strs := field.stringChan()
-			for str := range strs {
-				tokenChan <- str
+		fields, defs := q.fieldsAndFragments()
+		q.emitOperation(tokenChan, fields)
+		// emit fragment definitions
+		for _, def := range defs {
+			for tok := range def.tokenChan() {
+				tokenChan <- tok
 			}
 		}
-		tokenChan <- tokenRB
 		close(tokenChan)
 	}()
-	return tokenChan
+	return styled
+}
+
+// fieldsAndFragments returns the fields to emit and the fragment definitions following the operation.
+// Both may be copies of the fields of this Query, transformed by its Config and its FragmentDefinitions.
+func (q *Query) fieldsAndFragments() ([]*Field, []*fragmentDefinition) {
+	fields := q.Fields
+	if q.Config != nil {
+		fields = q.Config.configure(fields, true)
+	}
+	if !q.FragmentDefinitions {
+		return fields, nil
+	}
+	return spreadFragments(fields)
+}
+
+// emitOperation emits the tokens of the operation selecting fields, without fragment definitions.
+func (q *Query) emitOperation(tokenChan chan<- Token, fields []*Field) {
+	q.emitHeader(tokenChan)
+	// emit fields
+	for i, field := range fields {
+		if i != 0 {
+			tokenChan <- Token{TokenPunctuator, tokenComma}
+		}
+		for tok := range field.tokenChan() {
+			tokenChan <- tok
+		}
+	}
+	q.emitFooter(tokenChan)
+}
+
+// emitHeader emits the tokens preceding the fields, up to the opening brace of the selection set.
+func (q *Query) emitHeader(tokenChan chan<- Token) {
+	for _, op := range q.OperationOptions {
+		for _, tok := range op.Prefix() {
+			tokenChan <- tok
+		}
+	}
+	tokenChan <- Token{TokenKeyword, string(q.Type.Canonical())}
+	// emit operation name
+	if q.Name != "" {
+		tokenChan <- Token{TokenSpace, tokenSpace}
+		tokenChan <- Token{TokenName, q.Name}
+	}
+	// emit variable definitions
+	if variables := q.variables(); len(variables) > 0 {
+		tokenChan <- Token{TokenPunctuator, tokenLP}
+		for i := range variables {
+			if i != 0 {
+				tokenChan <- Token{TokenPunctuator, tokenComma}
+			}
+			for tok := range variables[i].tokenChan() {
+				tokenChan <- tok
+			}
+		}
+		tokenChan <- Token{TokenPunctuator, tokenRP}
+	}
+	// emit directives
+	for i := range q.Directives {
+		for tok := range q.Directives[i].tokenChan() {
+			tokenChan <- tok
+		}
+	}
+	tokenChan <- Token{TokenPunctuator, tokenLB}
+}
+
+// emitFooter emits the tokens following the fields, from the closing brace of the selection set.
+func (q *Query) emitFooter(tokenChan chan<- Token) {
+	tokenChan <- Token{TokenPunctuator, tokenRB}
+	for i := len(q.OperationOptions) - 1; i >= 0; i-- {
+		for _, tok := range q.OperationOptions[i].Suffix() {
+			tokenChan <- tok
+		}
+	}
+}
+
+// checkAll checks the query itself and all of its fields.
+func (q *Query) checkAll() (err error) {
+	q.validate(firstProblem(&err))
+	return err
+}
+
+// validate reports the problems of the query and all of its fields to report, and returns whether validation goes on.
+func (q *Query) validate(report reporter) bool {
+	if !q.validateOperation(report) {
+		return false
+	}
+	for _, f := range q.Fields {
+		if f == nil {
+			if !q.Config.skipsNilFields() && !report.check(NilFieldErr{}) {
+				return false
+			}
+			continue
+		}
+		if !f.validate(q.Config, report) {
+			return false
+		}
+	}
+	if q.countFields() == 0 {
+		if q.Config != nil && q.Config.CheckSelections {
+			if !report.check(EmptySelectionErr{}) {
+				return false
+			}
+		} else if report.warnings && !report.warn(EmptySelectionErr{}) {
+			return false
+		}
+	}
+	if q.checkCycles() != nil {
+		// the variables of cyclic fields can not be walked, the cycles are reported already
+		return true
+	}
+	if !report.check(q.checkSharedFields(q.Config)) {
+		return false
+	}
+	if report.warnings && (q.Config == nil || !q.Config.CheckSharedFields) && !report.warn(q.checkSharedFields(q.Config.strict())) {
+		return false
+	}
+	return q.validateVariables(report)
 }
 
-func (q *Query) check() error {
-	// check query
-	if !isValidOperationType(q.Type) {
-		return errors.WithStack(InvalidOperationTypeErr{q.Type})
+// countFields returns the number of fields serialized at the root of this Query.
+func (q *Query) countFields() int {
+	if !q.Config.skipsNilFields() {
+		return len(q.Fields)
 	}
-	if err := q.checkName(); err != nil {
-		return errors.WithStack(err)
+	n := 0
+	for _, f := range q.Fields {
+		if f != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// checkCycles checks that no field of this Query reaches itself, which the helpers copying a Query rely on.
+func (q *Query) checkCycles() error {
+	for _, f := range q.Fields {
+		if f == nil {
+			continue
+		}
+		if err := f.checkCycle(); err != nil {
+			return errors.WithStack(err)
+		}
 	}
 	return nil
 }
 
+// checkSharedFields checks that no *Field is at several positions of this Query when c checks shared fields.
+// The fields of spreads are not walked, they are the ones of their fragment.
+func (q *Query) checkSharedFields(c *Config) error {
+	if c == nil || !c.CheckSharedFields {
+		return nil
+	}
+	seen := make(map[*Field]bool)
+	var walk func(fs []*Field) error
+	walk = func(fs []*Field) error {
+		for _, f := range fs {
+			if f == nil {
+				continue
+			}
+			if seen[f] {
+				return errors.WithStack(SharedFieldErr{f.Name})
+			}
+			seen[f] = true
+			if f.fragment != nil {
+				continue
+			}
+			if err := walk(f.Fields); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return walk(q.Fields)
+}
+
+func (q *Query) check() (err error) {
+	q.validateOperation(firstProblem(&err))
+	return err
+}
+
+// validateOperation reports the problems of the query itself, without its fields and variables.
+func (q *Query) validateOperation(report reporter) bool {
+	if !q.Type.IsValid() && !report.check(InvalidOperationTypeErr{q.Type}) {
+		return false
+	}
+	if !report.check(q.checkName()) {
+		return false
+	}
+	for i := range q.Directives {
+		if !report.check(q.Directives[i].check(q.Config)) {
+			return false
+		}
+	}
+	return true
+}
+
 func (q *Query) checkName() error {
 	if q.Name != "" && !validName.MatchString(q.Name) {
 		return errors.WithStack(InvalidNameErr{operationName, q.Name})
@@ -102,30 +300,82 @@ func (q *Query) checkName() error {
 ////////////////
 
 // MakeQuery constructs a Query of the given type and returns a pointer of it.
-func MakeQuery(Type operationType) *Query {
-	return &Query{Type: Type, Headers: make(map[string]string)}
+// The Query follows the conventions of config, if given.
+func MakeQuery(Type OperationType, config ...*Config) *Query {
+	q := &Query{Type: Type, Headers: make(map[string]string)}
+	if len(config) > 0 {
+		q.Config = config[0]
+	}
+	return q
 }
 
-// JSON returns a json string with "query" field.
+// JSON returns a json string with "query" field,
+// a "variables" field when any variable of this Query has a value, and an "extensions" field when it has Extensions.
 func (q *Query) JSON() (string, error) {
 	strCh, err := q.StringChan()
 	if err != nil {
 		return "", errors.WithStack(err)
 	}
-	s := StringFromChan(strCh)
-	return fmt.Sprintf(`{"query":"%s"}`, strings.Replace(s, `"`, `\"`, -1)), nil
+	return q.jsonOf(StringFromChan(strCh))
+}
+
+// jsonOf returns the JSON() of this Query given its serialized text s.
+func (q *Query) jsonOf(s string) (string, error) {
+	extensions, err := q.extensionsMember()
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	values := q.variableValues()
+	if len(values) == 0 {
+		return fmt.Sprintf(`{"query":%s%s}`, jsonString(s), extensions), nil
+	}
+	b, err := json.Marshal(values)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return fmt.Sprintf(`{"query":%s,"variables":%s%s}`, jsonString(s), b, extensions), nil
+}
+
+// extensionsMember returns the "extensions" member of the JSON() of this Query preceded by a comma, empty if it has no Extensions.
+func (q *Query) extensionsMember() (string, error) {
+	if len(q.Extensions) == 0 {
+		return "", nil
+	}
+	b, err := json.Marshal(q.Extensions)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return `,"extensions":` + string(b), nil
+}
+
+// GzipJSONBody returns the gzip compressed JSON() of this Query,
+// suitable as a request body sent with the header "Content-Encoding: gzip".
+func (q *Query) GzipJSONBody() ([]byte, error) {
+	s, err := q.JSON()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write([]byte(s)); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return buf.Bytes(), nil
 }
 
 // SetName sets the Name field of this Query.
 func (q *Query) SetName(name string) *Query {
 	q.Name = name
-	return q
+	return q.invalidate()
 }
 
 // GetField return the field identified by the name. Nil if not exist.
 func (q *Query) GetField(name string) *Field {
 	for _, f := range q.Fields {
-		if f.Name == name {
+		if f != nil && f.Name == name {
 			return f
 		}
 	}
@@ -136,17 +386,66 @@ func (q *Query) GetField(name string) *Field {
 // If q.Fields already contains data, they will be replaced.
 func (q *Query) SetFields(fields ...*Field) *Query {
 	q.Fields = fields
-	return q
+	return q.invalidate()
 }
 
 // AddFields adds to the Fields field of this Query.
 func (q *Query) AddFields(fields ...*Field) *Query {
 	q.Fields = append(q.Fields, fields...)
-	return q
+	return q.invalidate()
+}
+
+// SortFields sorts the fields of this Query and all their sub fields with less, keeping the order of equal fields.
+// Nil fields are left in place for validation to report them.
+func (q *Query) SortFields(less func(a, b *Field) bool) *Query {
+	sortFields(q.Fields, less, make(map[*Field]bool))
+	return q.invalidate()
+}
+
+// sortFields sorts fs recursively, visiting every field once even if the tree contains cycles.
+func sortFields(fs []*Field, less func(a, b *Field) bool, visited map[*Field]bool) {
+	var nonNil []int
+	for i, f := range fs {
+		if f != nil {
+			nonNil = append(nonNil, i)
+		}
+	}
+	sorted := make([]*Field, len(nonNil))
+	for i, j := range nonNil {
+		sorted[i] = fs[j]
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return less(sorted[i], sorted[j])
+	})
+	for i, j := range nonNil {
+		fs[j] = sorted[i]
+	}
+
+	for _, f := range sorted {
+		if !visited[f] {
+			visited[f] = true
+			sortFields(f.Fields, less, visited)
+		}
+	}
+}
+
+// AddDirectives adds directives to the operation of this Query and returns the pointer to this Query.
+func (q *Query) AddDirectives(directives ...Directive) *Query {
+	q.Directives = append(q.Directives, directives...)
+	return q.invalidate()
+}
+
+// AddVariables adds variable definitions to this Query.
+func (q *Query) AddVariables(variables ...Variable) *Query {
+	q.Variables = append(q.Variables, variables...)
+	return q.invalidate()
 }
 
 // AddHeader adds a header key-value to this Query
 func (q *Query) AddHeader(key, value string) *Query {
+	if q.Headers == nil {
+		q.Headers = make(map[string]string)
+	}
 	q.Headers[key] = value
 	return q
 }
@@ -1,6 +1,9 @@
 package graphb
 
 import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
 	"strings"
 	"testing"
 
@@ -12,14 +15,14 @@ func TestQuery_checkName(t *testing.T) {
 	q := Query{Name: "1"}
 	err := q.checkName()
 	assert.IsType(t, InvalidNameErr{}, errors.Cause(err))
-	assert.Equal(t, "'1' is an invalid operation name in GraphQL. A valid name matches /[_A-Za-z][_0-9A-Za-z]*/, see: http://facebook.github.io/graphql/October2016/#sec-Names", err.Error())
+	assert.Equal(t, "'1' is an invalid operation name in GraphQL: unexpected '1' (U+0031) at byte offset 0. A valid name matches /[_A-Za-z][_0-9A-Za-z]*/, see: http://facebook.github.io/graphql/October2016/#sec-Names", err.Error())
 }
 
 func TestQuery_check(t *testing.T) {
 	q := Query{Name: "1", Type: TypeQuery}
 	err := q.check()
 	assert.IsType(t, InvalidNameErr{}, errors.Cause(err))
-	assert.Equal(t, "'1' is an invalid operation name in GraphQL. A valid name matches /[_A-Za-z][_0-9A-Za-z]*/, see: http://facebook.github.io/graphql/October2016/#sec-Names", err.Error())
+	assert.Equal(t, "'1' is an invalid operation name in GraphQL: unexpected '1' (U+0031) at byte offset 0. A valid name matches /[_A-Za-z][_0-9A-Za-z]*/, see: http://facebook.github.io/graphql/October2016/#sec-Names", err.Error())
 }
 
 func TestQuery_GetField(t *testing.T) {
@@ -29,6 +32,20 @@ func TestQuery_GetField(t *testing.T) {
 
 	f = q.GetField("f2")
 	assert.Nil(t, f)
+
+	q.AddFields(nil)
+	assert.Nil(t, q.GetField("f2"))
+}
+
+func TestQuery_zeroValue(t *testing.T) {
+	var q Query
+	q.SetName("Q").AddFields(MakeField("a")).AddVariables(Variable{Name: "v", Type: "Int"}).AddHeader("k", "v").DeleteHeader("x")
+	assert.Equal(t, map[string]string{"k": "v"}, q.GetHeaders())
+	q.Type = TypeQuery
+	q.Variables = nil
+	strCh, err := q.StringChan()
+	assert.Nil(t, err)
+	assert.Equal(t, `query Q{a}`, StringFromChan(strCh))
 }
 
 func TestQuery_JSON(t *testing.T) {
@@ -64,3 +81,95 @@ func TestQuery_JSON(t *testing.T) {
 	})
 
 }
+
+func TestQuery_Tokens(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		q := MakeQuery(TypeQuery).
+			SetName("q").
+			SetFields(
+				MakeField("user").
+					SetAlias("u").
+					SetArguments(ArgumentInt("id", 1), ArgumentEnumSlice("roles", "ADMIN")).
+					SetFields(MakeField("name")),
+			)
+		ch, err := q.Tokens()
+		assert.Nil(t, err)
+
+		var tokens []Token
+		for tok := range ch {
+			tokens = append(tokens, tok)
+		}
+		assert.Equal(t, []Token{
+			{TokenKeyword, "query"},
+			{TokenSpace, " "},
+			{TokenName, "q"},
+			{TokenPunctuator, "{"},
+			{TokenName, "u"},
+			{TokenPunctuator, ":"},
+			{TokenName, "user"},
+			{TokenPunctuator, "("},
+			{TokenName, "id"},
+			{TokenPunctuator, ":"},
+			{TokenInt, "1"},
+			{TokenPunctuator, ","},
+			{TokenName, "roles"},
+			{TokenPunctuator, ":"},
+			{TokenPunctuator, "["},
+			{TokenEnum, "ADMIN"},
+			{TokenPunctuator, "]"},
+			{TokenPunctuator, ")"},
+			{TokenPunctuator, "{"},
+			{TokenName, "name"},
+			{TokenPunctuator, "}"},
+			{TokenPunctuator, "}"},
+		}, tokens)
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		q := MakeQuery(TypeQuery).SetName("1")
+		ch, err := q.Tokens()
+		assert.IsType(t, InvalidNameErr{}, errors.Cause(err))
+		_, ok := <-ch
+		assert.False(t, ok)
+	})
+}
+
+func TestQuery_GzipJSONBody(t *testing.T) {
+	q := MakeQuery(TypeQuery).SetFields(MakeField("user").SetFields(MakeField("id")))
+	body, err := q.GzipJSONBody()
+	assert.Nil(t, err)
+
+	r, err := gzip.NewReader(bytes.NewReader(body))
+	assert.Nil(t, err)
+	s, err := ioutil.ReadAll(r)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"query":"query{user{id}}"}`, string(s))
+
+	body, err = MakeQuery("muTatio").GzipJSONBody()
+	assert.IsType(t, InvalidOperationTypeErr{}, errors.Cause(err))
+	assert.Nil(t, body)
+}
+
+func TestQuery_SortFields(t *testing.T) {
+	shared := MakeField("shared").SetFields(MakeField("z"), MakeField("y"))
+	q := MakeQuery(TypeQuery).SetFields(
+		MakeField("b").SetFields(MakeField("d"), MakeField("c"), shared),
+		MakeField("a").SetFields(shared),
+		MakeField("b").SetAlias("first"),
+	)
+	byName := func(a, b *Field) bool { return a.Name < b.Name }
+	s, err := q.SortFields(byName).JSON()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"query":"query{a{shared{y,z}},b{c,d,shared{y,z}},first:b}"}`, s)
+
+	q = MakeQuery(TypeQuery).SetFields(MakeField("b"), nil, MakeField("a"))
+	q.SortFields(byName)
+	assert.Equal(t, "a", q.Fields[0].Name)
+	assert.Nil(t, q.Fields[1])
+	assert.Equal(t, "b", q.Fields[2].Name)
+
+	f := MakeField("a")
+	f.SetFields(MakeField("c"), f)
+	MakeQuery(TypeQuery).SetFields(f).SortFields(byName)
+	assert.Equal(t, "a", f.Fields[0].Name)
+}
@@ -0,0 +1,148 @@
+package graphb
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// RateLimit is the rate limit status a server reports in a response.
+type RateLimit struct {
+	Limit       int       // The maximum points of the window or bucket.
+	Remaining   int       // The points left.
+	Cost        int       // The points the request cost.
+	ResetAt     time.Time // When the points are restored, zero if unknown.
+	RestoreRate float64   // Points restored per second for leaky bucket limits, zero if unknown.
+}
+
+// RateLimitExtractor reads the rate limit status from a whole response body, reporting false if there is none.
+type RateLimitExtractor func(response json.RawMessage) (RateLimit, bool)
+
+// GitHubRateLimit extracts the rate limit of GitHub's API, which is in the response if the query selects
+//
+//	rateLimit{limit,cost,remaining,resetAt}
+func GitHubRateLimit(response json.RawMessage) (RateLimit, bool) {
+	var body struct {
+		Data struct {
+			RateLimit *struct {
+				Limit     int       `json:"limit"`
+				Cost      int       `json:"cost"`
+				Remaining int       `json:"remaining"`
+				ResetAt   time.Time `json:"resetAt"`
+			} `json:"rateLimit"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(response, &body); err != nil || body.Data.RateLimit == nil {
+		return RateLimit{}, false
+	}
+	rl := body.Data.RateLimit
+	return RateLimit{Limit: rl.Limit, Remaining: rl.Remaining, Cost: rl.Cost, ResetAt: rl.ResetAt}, true
+}
+
+// CostExtensionRateLimit extracts the calculated query cost extension used by Shopify and others:
+//
+//	"extensions":{"cost":{"actualQueryCost":2,"throttleStatus":{"maximumAvailable":1000,"currentlyAvailable":998,"restoreRate":50}}}
+func CostExtensionRateLimit(response json.RawMessage) (RateLimit, bool) {
+	var body struct {
+		Extensions struct {
+			Cost *struct {
+				ActualQueryCost int `json:"actualQueryCost"`
+				ThrottleStatus  struct {
+					MaximumAvailable   float64 `json:"maximumAvailable"`
+					CurrentlyAvailable float64 `json:"currentlyAvailable"`
+					RestoreRate        float64 `json:"restoreRate"`
+				} `json:"throttleStatus"`
+			} `json:"cost"`
+		} `json:"extensions"`
+	}
+	if err := json.Unmarshal(response, &body); err != nil || body.Extensions.Cost == nil {
+		return RateLimit{}, false
+	}
+	cost := body.Extensions.Cost
+	return RateLimit{
+		Limit:       int(cost.ThrottleStatus.MaximumAvailable),
+		Remaining:   int(cost.ThrottleStatus.CurrentlyAvailable),
+		Cost:        cost.ActualQueryCost,
+		RestoreRate: cost.ThrottleStatus.RestoreRate,
+	}, true
+}
+
+// Throttler delays requests once the observed rate limit runs low. It is safe for concurrent use.
+// Call Observe with every response, and Wait before every request.
+type Throttler struct {
+	MinRemaining int                  // Wait blocks while fewer points than this remain.
+	Extractors   []RateLimitExtractor // Tried in order by Observe.
+	OnRateLimit  func(RateLimit)      // Optional hook called with every extracted rate limit.
+
+	mu       sync.Mutex
+	last     RateLimit
+	observed time.Time
+	now      func() time.Time
+}
+
+// Observe extracts the rate limit from a response body with the first extractor which finds one.
+func (t *Throttler) Observe(response json.RawMessage) {
+	for _, extract := range t.Extractors {
+		if rl, ok := extract(response); ok {
+			t.mu.Lock()
+			t.last = rl
+			t.observed = t.clock()
+			t.mu.Unlock()
+			if t.OnRateLimit != nil {
+				t.OnRateLimit(rl)
+			}
+			return
+		}
+	}
+}
+
+// Last returns the last observed rate limit.
+func (t *Throttler) Last() RateLimit {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.last
+}
+
+// Wait blocks until enough points are expected to be available again, or until ctx is done.
+func (t *Throttler) Wait(ctx context.Context) error {
+	timer := time.NewTimer(t.delay())
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// delay computes how long to wait before the next request.
+func (t *Throttler) delay() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.observed.IsZero() || t.last.Remaining >= t.MinRemaining {
+		return 0
+	}
+	now := t.clock()
+	if !t.last.ResetAt.IsZero() {
+		if d := t.last.ResetAt.Sub(now); d > 0 {
+			return d
+		}
+		return 0
+	}
+	if t.last.RestoreRate > 0 {
+		missing := float64(t.MinRemaining - t.last.Remaining)
+		restored := t.observed.Add(time.Duration(missing / t.last.RestoreRate * float64(time.Second)))
+		if d := restored.Sub(now); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+func (t *Throttler) clock() time.Time {
+	if t.now != nil {
+		return t.now()
+	}
+	return time.Now()
+}
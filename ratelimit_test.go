@@ -0,0 +1,60 @@
+package graphb
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitHubRateLimit(t *testing.T) {
+	rl, ok := GitHubRateLimit(json.RawMessage(`{"data":{"rateLimit":{"limit":5000,"cost":1,"remaining":4999,"resetAt":"2018-01-01T00:00:00Z"}}}`))
+	assert.True(t, ok)
+	assert.Equal(t, RateLimit{Limit: 5000, Cost: 1, Remaining: 4999, ResetAt: time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)}, rl)
+
+	_, ok = GitHubRateLimit(json.RawMessage(`{"data":{"viewer":{}}}`))
+	assert.False(t, ok)
+	_, ok = GitHubRateLimit(json.RawMessage(`[`))
+	assert.False(t, ok)
+}
+
+func TestCostExtensionRateLimit(t *testing.T) {
+	rl, ok := CostExtensionRateLimit(json.RawMessage(`{"data":{},"extensions":{"cost":{"actualQueryCost":2,"throttleStatus":{"maximumAvailable":1000.0,"currentlyAvailable":998,"restoreRate":50.0}}}}`))
+	assert.True(t, ok)
+	assert.Equal(t, RateLimit{Limit: 1000, Remaining: 998, Cost: 2, RestoreRate: 50}, rl)
+
+	_, ok = CostExtensionRateLimit(json.RawMessage(`{"data":{}}`))
+	assert.False(t, ok)
+}
+
+func TestThrottler(t *testing.T) {
+	now := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	var hooked []RateLimit
+	th := &Throttler{
+		MinRemaining: 100,
+		Extractors:   []RateLimitExtractor{GitHubRateLimit, CostExtensionRateLimit},
+		OnRateLimit:  func(rl RateLimit) { hooked = append(hooked, rl) },
+		now:          func() time.Time { return now },
+	}
+	assert.Equal(t, time.Duration(0), th.delay())
+
+	th.Observe(json.RawMessage(`{"data":{"rateLimit":{"remaining":10,"resetAt":"2018-01-01T00:00:30Z"}}}`))
+	assert.Equal(t, 30*time.Second, th.delay())
+	assert.Equal(t, 10, th.Last().Remaining)
+
+	th.Observe(json.RawMessage(`{"extensions":{"cost":{"throttleStatus":{"currentlyAvailable":50,"restoreRate":25}}}}`))
+	assert.Equal(t, 2*time.Second, th.delay())
+
+	th.Observe(json.RawMessage(`{"data":{"rateLimit":{"remaining":4000}}}`))
+	assert.Equal(t, time.Duration(0), th.delay())
+	assert.Len(t, hooked, 3)
+
+	assert.Nil(t, th.Wait(context.Background()))
+
+	th.Observe(json.RawMessage(`{"data":{"rateLimit":{"remaining":0,"resetAt":"2018-01-01T01:00:00Z"}}}`))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, th.Wait(ctx))
+}
@@ -0,0 +1,143 @@
+package graphb
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// RequestKey returns the key under which RecordingTransport records req and ReplayTransport looks it up:
+// the hex encoded SHA-256 hash of its minified query, its operation name and its variables encoded as JSON.
+// Requests differing only in the formatting of their query, or in their headers and extensions, share a key.
+func RequestKey(req Request) (string, error) {
+	query, err := Minify(req.Query)
+	if err != nil {
+		// not a document Minify understands, hashed as it is
+		query = req.Query
+	}
+	// keys sorted by encoding/json
+	variables, err := json.Marshal(req.Variables)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	h := sha256.New()
+	for _, part := range []string{query, req.OperationName, string(variables)} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// recording is the content of a file written by RecordingTransport.
+// The request is kept for the reader of the file, ReplayTransport only reads the response.
+type recording struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// recordingPath returns the path of the file recording req in dir.
+func recordingPath(dir string, req Request) (string, error) {
+	key, err := RequestKey(req)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return filepath.Join(dir, key+".json"), nil
+}
+
+// RecordingTransport executes requests through Transport and records every response into Dir,
+// in a JSON file named by the RequestKey of its request, to be served back by a ReplayTransport in hermetic tests.
+// A request executed again overwrites its recording. Failed requests and headers, which may carry credentials, are not recorded.
+type RecordingTransport struct {
+	Transport Transport
+	Dir       string
+}
+
+// Execute implements Transport.
+func (t *RecordingTransport) Execute(ctx context.Context, req Request) (Response, error) {
+	resp, err := t.Transport.Execute(ctx, req)
+	if err != nil {
+		return resp, errors.WithStack(err)
+	}
+	path, err := recordingPath(t.Dir, req)
+	if err != nil {
+		return resp, errors.WithStack(err)
+	}
+	b, err := json.MarshalIndent(recording{req, resp}, "", "  ")
+	if err != nil {
+		return resp, errors.WithStack(err)
+	}
+	if err := writeFileAtomically(path, append(b, '\n')); err != nil {
+		return resp, errors.WithStack(err)
+	}
+	return resp, nil
+}
+
+// writeFileAtomically writes b into the file at path through a temporary file,
+// so that a concurrent reader never sees a partial file.
+func writeFileAtomically(path string, b []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return errors.WithStack(err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return errors.WithStack(err)
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		os.Remove(f.Name())
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// ReplayTransport serves the responses recorded into Dir by a RecordingTransport, without any network.
+// The JSON of a response is served compacted, the recordings being indented to be reviewed.
+// A request which was not recorded returns a NotRecordedErr.
+type ReplayTransport struct {
+	Dir string
+}
+
+// Execute implements Transport.
+func (t *ReplayTransport) Execute(ctx context.Context, req Request) (Response, error) {
+	if err := ctx.Err(); err != nil {
+		return Response{}, errors.WithStack(err)
+	}
+	path, err := recordingPath(t.Dir, req)
+	if err != nil {
+		return Response{}, errors.WithStack(err)
+	}
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Response{}, errors.WithStack(NotRecordedErr{OperationName: req.OperationName, Path: path})
+	}
+	if err != nil {
+		return Response{}, errors.WithStack(err)
+	}
+	var r recording
+	if err := json.Unmarshal(b, &r); err != nil {
+		return Response{}, errors.Wrapf(err, "%s", path)
+	}
+	resp := r.Response
+	for _, m := range []*json.RawMessage{&resp.Data, &resp.Errors, &resp.Extensions} {
+		if len(*m) == 0 {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, *m); err != nil {
+			return Response{}, errors.Wrapf(err, "%s", path)
+		}
+		*m = buf.Bytes()
+	}
+	return resp, nil
+}
@@ -0,0 +1,120 @@
+package graphb
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestKey(t *testing.T) {
+	key, err := RequestKey(Request{Query: "query Me($id:ID){me(id:$id){name}}", OperationName: "Me", Variables: map[string]interface{}{"id": "1"}})
+	assert.Nil(t, err)
+	assert.Len(t, key, 64)
+
+	// formatting, headers and extensions do not matter
+	same, err := RequestKey(Request{
+		Query:         "query Me($id: ID) {\n  me(id: $id) {\n    name\n  }\n}",
+		OperationName: "Me",
+		Variables:     map[string]interface{}{"id": "1"},
+		Extensions:    map[string]interface{}{"trace": true},
+		Headers:       map[string]string{"Authorization": "Bearer q"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, key, same)
+
+	for _, req := range []Request{
+		{Query: "query Me($id:ID){me(id:$id){name}}", OperationName: "Me", Variables: map[string]interface{}{"id": "2"}},
+		{Query: "query Me($id:ID){me(id:$id){name}}", Variables: map[string]interface{}{"id": "1"}},
+		{Query: "query Me($id:ID){me(id:$id){id}}", OperationName: "Me", Variables: map[string]interface{}{"id": "1"}},
+	} {
+		other, err := RequestKey(req)
+		assert.Nil(t, err)
+		assert.NotEqual(t, key, other)
+	}
+
+	// not a document, hashed as it is
+	_, err = RequestKey(Request{Query: "{"})
+	assert.Nil(t, err)
+
+	_, err = RequestKey(Request{Query: "{a}", Variables: map[string]interface{}{"c": make(chan int)}})
+	assert.NotNil(t, err)
+}
+
+func TestRecordingTransport(t *testing.T) {
+	dir := t.TempDir()
+	calls := 0
+	live := TransportFunc(func(ctx context.Context, req Request) (Response, error) {
+		calls++
+		return Response{Data: json.RawMessage(`{"me":{"name":"` + req.Headers["Authorization"] + `"}}`)}, nil
+	})
+	recorder := &RecordingTransport{Transport: live, Dir: dir}
+
+	data, err := ExecuteWith(recorder)(context.Background(), meQuery())
+	assert.Nil(t, err)
+	assert.Equal(t, `{"me":{"name":"Bearer q"}}`, string(data))
+	assert.Equal(t, 1, calls)
+
+	req, err := meQuery().Request()
+	assert.Nil(t, err)
+	key, err := RequestKey(req)
+	assert.Nil(t, err)
+	b, err := os.ReadFile(filepath.Join(dir, key+".json"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(b), `"operationName": "Me"`)
+	// credentials are not recorded
+	assert.NotContains(t, string(b), "Authorization")
+
+	replay := ExecuteWith(&ReplayTransport{Dir: dir})
+	data, err = replay(context.Background(), meQuery())
+	assert.Nil(t, err)
+	assert.Equal(t, `{"me":{"name":"Bearer q"}}`, string(data))
+	assert.Equal(t, 1, calls)
+
+	// the variables are part of the key
+	other := meQuery()
+	other.Variables[0].Value = "2"
+	_, err = replay(context.Background(), other)
+	assert.IsType(t, NotRecordedErr{}, errors.Cause(err))
+	assert.Contains(t, err.Error(), "operation 'Me'")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = replay(ctx, meQuery())
+	assert.Equal(t, context.Canceled, errors.Cause(err))
+}
+
+func TestRecordingTransport_Errors(t *testing.T) {
+	dir := t.TempDir()
+	failing := TransportFunc(func(ctx context.Context, req Request) (Response, error) {
+		return Response{}, errors.New("connection refused")
+	})
+	_, err := (&RecordingTransport{Transport: failing, Dir: dir}).Execute(context.Background(), Request{Query: "{a}"})
+	assert.EqualError(t, errors.Cause(err), "connection refused")
+	entries, err := os.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.Empty(t, entries)
+
+	// GraphQL errors are recorded and replayed
+	withErrors := TransportFunc(func(ctx context.Context, req Request) (Response, error) {
+		return Response{Errors: json.RawMessage(`[{"message":"denied"}]`)}, nil
+	})
+	_, err = ExecuteWith(&RecordingTransport{Transport: withErrors, Dir: dir})(context.Background(), meQuery())
+	assert.IsType(t, GraphQLErrorsErr{}, errors.Cause(err))
+	_, err = ExecuteWith(&ReplayTransport{Dir: dir})(context.Background(), meQuery())
+	assert.IsType(t, GraphQLErrorsErr{}, errors.Cause(err))
+
+	_, err = (&RecordingTransport{Transport: withErrors, Dir: filepath.Join(dir, "missing")}).Execute(context.Background(), Request{Query: "{a}"})
+	assert.NotNil(t, err)
+
+	key, err := RequestKey(Request{Query: "{a}"})
+	assert.Nil(t, err)
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, key+".json"), []byte("{"), 0o600))
+	_, err = (&ReplayTransport{Dir: dir}).Execute(context.Background(), Request{Query: "{a}"})
+	assert.True(t, strings.HasPrefix(err.Error(), filepath.Join(dir, key+".json")))
+}
@@ -0,0 +1,100 @@
+package graphb
+
+import (
+	"github.com/pkg/errors"
+)
+
+// redactedValue replaces the values of redacted arguments.
+const redactedValue = argString("<redacted>")
+
+// StringRedacted serializes this Query like StringChan, but the values of the arguments named by redactArgs
+// are replaced by "<redacted>", at any depth including input object fields and directive arguments, so that the query can be logged safely.
+// A value which is a variable, e.g. password:$pw, is kept, the variable carrying no secret in the query itself.
+// The Query itself is not modified.
+func (q *Query) StringRedacted(redactArgs ...string) (string, error) {
+	// check first, copying a cyclic tree never ends
+	if err := q.checkAll(); err != nil {
+		return "", errors.WithStack(err)
+	}
+	names := make(map[string]bool, len(redactArgs))
+	for _, name := range redactArgs {
+		names[name] = true
+	}
+	redacted := *q
+	redacted.Directives = redactDirectives(q.Directives, names)
+	redacted.Fields = redactFields(q.Fields, names)
+	strCh, err := redacted.StringChan()
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return StringFromChan(strCh), nil
+}
+
+func redactFields(fs []*Field, names map[string]bool) []*Field {
+	if fs == nil {
+		return nil
+	}
+	redacted := make([]*Field, len(fs))
+	for i, f := range fs {
+		if f == nil {
+			continue
+		}
+		copied := *f
+		copied.compiled = nil
+		copied.Arguments = redactArguments(f.Arguments, names)
+		copied.Directives = redactDirectives(f.Directives, names)
+		copied.Fields = redactFields(f.Fields, names)
+		redacted[i] = &copied
+	}
+	return redacted
+}
+
+func redactArguments(args []Argument, names map[string]bool) []Argument {
+	if args == nil {
+		return nil
+	}
+	redacted := make([]Argument, len(args))
+	for i, arg := range args {
+		redacted[i] = Argument{arg.Name, redactValue(arg.Value, names)}
+		if _, ok := arg.Value.(argVariable); names[arg.Name] && !ok {
+			redacted[i].Value = redactedValue
+		}
+	}
+	return redacted
+}
+
+func redactDirectives(directives []Directive, names map[string]bool) []Directive {
+	if directives == nil {
+		return nil
+	}
+	redacted := make([]Directive, len(directives))
+	for i, d := range directives {
+		redacted[i] = d
+		redacted[i].Arguments = redactArguments(d.Arguments, names)
+	}
+	return redacted
+}
+
+// redactValue looks into input objects and lists of input objects for arguments to redact.
+func redactValue(value argumentValue, names map[string]bool) argumentValue {
+	switch v := value.(type) {
+	case argumentCustom:
+		return argumentCustom(redactArguments(v, names))
+	case argArgSlice:
+		redacted := make(argArgSlice, len(v))
+		for i, args := range v {
+			redacted[i] = redactArguments(args, names)
+		}
+		return redacted
+	case argList:
+		redacted := make(argList, len(v))
+		for i, value := range v {
+			redacted[i] = redactValue(value, names)
+		}
+		return redacted
+	case argDefaulted:
+		return argDefaulted{redactValue(v.value, names), redactValue(v.defaultValue, names)}
+	default:
+		return value
+	}
+}
@@ -0,0 +1,56 @@
+package graphb
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery_StringRedacted(t *testing.T) {
+	q := MakeQuery(TypeMutation).SetFields(
+		MakeField("login").
+			SetArguments(
+				ArgumentString("user", "bob"),
+				ArgumentString("password", "hunter2"),
+				ArgumentCustomType("device", ArgumentString("token", "abc"), ArgumentInt("id", 1)),
+				ArgumentSlice("sessions", []Argument{ArgumentString("token", "def")}),
+			).
+			SetFields(MakeField("token")),
+	)
+
+	s, err := q.StringRedacted("password", "token")
+	assert.Nil(t, err)
+	assert.Equal(t, `mutation{login(user:"bob",password:"<redacted>",device:{token:"<redacted>",id:1},sessions:[{token:"<redacted>"}]){token}}`, s)
+
+	// the query itself keeps its values
+	strCh, err := q.StringChan()
+	assert.Nil(t, err)
+	assert.Equal(t, `mutation{login(user:"bob",password:"hunter2",device:{token:"abc",id:1},sessions:[{token:"def"}]){token}}`, StringFromChan(strCh))
+
+	f := MakeField("a")
+	f.SetFields(MakeField("b").SetFields(f))
+	_, err = MakeQuery(TypeQuery).SetFields(f).StringRedacted()
+	assert.IsType(t, CyclicFieldErr{}, errors.Cause(err))
+
+	_, err = MakeQuery(TypeQuery).SetName("1").StringRedacted()
+	assert.IsType(t, InvalidNameErr{}, errors.Cause(err))
+}
+
+func TestQuery_StringRedacted_variablesAndDirectives(t *testing.T) {
+	q := MakeQuery(TypeMutation).
+		AddVariables(Variable{Name: "pw", Type: "String!", Value: "hunter2"}).
+		AddDirectives(MakeDirective("audit", ArgumentString("token", "op"))).
+		SetFields(MakeField("login").
+			SetArguments(ArgumentVariable("password", "pw")).
+			AddDirectives(MakeDirective("auth", ArgumentString("token", "abc"))).
+			SetFields(MakeField("ok")))
+
+	s, err := q.StringRedacted("password", "token")
+	assert.Nil(t, err)
+	assert.Equal(t, `mutation($pw:String!)@audit(token:"<redacted>"){login(password:$pw)@auth(token:"<redacted>"){ok}}`, s)
+
+	strCh, err := q.StringChan()
+	assert.Nil(t, err)
+	assert.Equal(t, `mutation($pw:String!)@audit(token:"op"){login(password:$pw)@auth(token:"abc"){ok}}`, StringFromChan(strCh))
+}
@@ -0,0 +1,119 @@
+package graphb
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// FieldMapping maps field names to other names, e.g. the readable names used by internal code
+// to the obfuscated names a gateway exposes to third parties.
+type FieldMapping map[string]string
+
+// Reverse returns the mapping from the mapped names back to the original names.
+func (m FieldMapping) Reverse() FieldMapping {
+	reversed := make(FieldMapping, len(m))
+	for name, mapped := range m {
+		reversed[mapped] = name
+	}
+	return reversed
+}
+
+// RenameFields returns a copy of this Query whose fields are renamed by m at any depth. Aliases are left as they are.
+// Fragment spreads are copied as inline fragments, so that no fragment definition keeps the original names.
+// The original Query is left untouched, see DecodeRenamed to read the response of the copy.
+// If this Query contains a cycle, the copy is not renamed and its E reports the cycle.
+func (q *Query) RenameFields(m FieldMapping) *Query {
+	renamed := withCopiedHeaders(q)
+	if err := q.checkCycles(); err != nil {
+		renamed.E = errors.WithStack(err)
+		return renamed
+	}
+	renamed.Fields = renameFields(q.Fields, m)
+	return renamed
+}
+
+func renameFields(fs []*Field, m FieldMapping) []*Field {
+	if fs == nil {
+		return nil
+	}
+	renamed := make([]*Field, len(fs))
+	for i, f := range fs {
+		if f == nil {
+			continue
+		}
+		copied := *f
+		copied.compiled = nil
+		copied.fragment = nil
+		if mapped, ok := m[f.Name]; ok && !f.isInlineFragment() {
+			copied.Name = mapped
+		}
+		copied.Fields = renameFields(f.Fields, m)
+		renamed[i] = &copied
+	}
+	return renamed
+}
+
+// DecodeRenamed renames the keys of data, the response to the copy of this Query made by RenameFields with m,
+// back to the response keys of this Query. Only the keys of the renamed fields are changed, at any depth.
+func (q *Query) DecodeRenamed(data json.RawMessage, m FieldMapping) (json.RawMessage, error) {
+	decoded, err := decodeRenamed(data, q.Fields, m)
+	return decoded, errors.WithStack(err)
+}
+
+func decodeRenamed(data json.RawMessage, fs []*Field, m FieldMapping) (json.RawMessage, error) {
+	if len(fs) == 0 {
+		return data, nil
+	}
+	var list []json.RawMessage
+	if err := json.Unmarshal(data, &list); err == nil {
+		for i := range list {
+			element, err := decodeRenamed(list[i], fs, m)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			list[i] = element
+		}
+		b, err := json.Marshal(list)
+		return b, errors.WithStack(err)
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil || object == nil {
+		return data, nil // a scalar or null
+	}
+	if err := decodeRenamedObject(object, fs, m); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	b, err := json.Marshal(object)
+	return b, errors.WithStack(err)
+}
+
+// decodeRenamedObject renames the keys of object selected by fs, looking into inline fragments.
+func decodeRenamedObject(object map[string]json.RawMessage, fs []*Field, m FieldMapping) error {
+	for _, f := range fs {
+		if f == nil {
+			continue
+		}
+		if f.isInlineFragment() {
+			if err := decodeRenamedObject(object, f.Fields, m); err != nil {
+				return errors.WithStack(err)
+			}
+			continue
+		}
+		key := f.responseKey()
+		if mapped, ok := m[f.Name]; ok && f.Alias == "" {
+			if value, ok := object[mapped]; ok {
+				delete(object, mapped)
+				object[key] = value
+			}
+		}
+		if value, ok := object[key]; ok {
+			decoded, err := decodeRenamed(value, f.Fields, m)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			object[key] = decoded
+		}
+	}
+	return nil
+}
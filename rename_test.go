@@ -0,0 +1,42 @@
+package graphb
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery_RenameFields(t *testing.T) {
+	r := NewFragmentRegistry()
+	assert.Nil(t, r.RegisterFragment("Contact", "User", MakeField("email")))
+	spread, err := r.Spread("Contact")
+	assert.Nil(t, err)
+
+	q := MakeQuery(TypeQuery).SetFields(
+		MakeField("users").SetFields(
+			MakeField("name"),
+			MakeField("name").SetAlias("display"),
+			spread,
+		),
+	)
+	q.FragmentDefinitions = true
+	m := FieldMapping{"users": "u", "name": "f1", "email": "f2"}
+
+	renamed := q.RenameFields(m)
+	s, err := renamed.StringChan()
+	assert.Nil(t, err)
+	assert.Equal(t, "query{u{f1,display:f1,... on User{f2}}}", StringFromChan(s))
+	s, _ = q.StringChan()
+	assert.Equal(t, "query{users{name,display:name,...Contact}}fragment Contact on User{email}", StringFromChan(s))
+
+	decoded, err := q.DecodeRenamed(json.RawMessage(`{"u":[{"f1":"Ada","display":"Ada L.","f2":"ada@example.com"},null]}`), m)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"users":[{"name":"Ada","display":"Ada L.","email":"ada@example.com"},null]}`, string(decoded))
+
+	assert.Equal(t, FieldMapping{"u": "users", "f1": "name", "f2": "email"}, m.Reverse())
+
+	cyclic := MakeField("node")
+	cyclic.SetFields(cyclic)
+	assert.NotNil(t, MakeQuery(TypeQuery).SetFields(cyclic).RenameFields(m).E)
+}
@@ -0,0 +1,184 @@
+package graphb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// The media types of GraphQL over HTTP requests and responses.
+const (
+	ContentTypeJSON            = "application/json"
+	ContentTypeGraphQLResponse = "application/graphql-response+json"
+	ContentTypeGraphQL         = "application/graphql"
+)
+
+// RequestOption sets an option of the HTTP request built by Query.NewRequest.
+type RequestOption func(o *requestOptions)
+
+type requestOptions struct {
+	header     http.Header
+	gzip       bool
+	document   bool
+	persisted  map[string]bool // The IDs of the operations which may be sent, if not nil, see WithManifest.
+	encryption []EncryptionRule
+}
+
+func newRequestOptions(options []RequestOption) *requestOptions {
+	o := &requestOptions{header: make(http.Header)}
+	for _, option := range options {
+		option(o)
+	}
+	return o
+}
+
+// WithRequestHeader sets a header of the request, replacing the header of the same key set by the Query.
+func WithRequestHeader(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		o.header.Set(key, value)
+	}
+}
+
+// WithGzipBody compresses the JSON body of a POST request, see Query.GzipJSONBody.
+func WithGzipBody() RequestOption {
+	return func(o *requestOptions) {
+		o.gzip = true
+	}
+}
+
+// WithDocumentBody sends the query document itself as the body of a POST request, with the content type application/graphql,
+// instead of a JSON envelope. Some servers and CDNs cache such requests better.
+// The operation name and the variables, which the document can not carry, are sent in the URL as for a GET request.
+func WithDocumentBody() RequestOption {
+	return func(o *requestOptions) {
+		o.document = true
+	}
+}
+
+// NewRequest returns an HTTP request sending this Query to the endpoint, to be sent with one's own http.Client.
+// A GET request carries the query and its variables in the URL, any other method in a JSON body.
+// The body is streamed while the request is sent if a variable is a StreamedValue, and sent as a multipart request if it is an Upload.
+// The request accepts graphql-response+json and json responses, and has the headers of this Query.
+func (q *Query) NewRequest(ctx context.Context, method, endpoint string, options ...RequestOption) (*http.Request, error) {
+	o := newRequestOptions(options)
+	if len(o.encryption) > 0 {
+		encrypted, err := q.Encrypted(o.encryption...)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		q = encrypted
+	}
+	if o.persisted != nil {
+		if err := checkPersisted(q, o.persisted); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	var body io.Reader
+	switch {
+	case method == http.MethodGet:
+		params, err := q.urlParams(true)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		endpoint = withParams(endpoint, params)
+	case o.document:
+		params, err := q.urlParams(false)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if params != "" {
+			endpoint = withParams(endpoint, params)
+		}
+		strCh, err := q.StringChan()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		body = strings.NewReader(StringFromChan(strCh))
+		o.header.Set("Content-Type", ContentTypeGraphQL)
+	case q.streamsBody():
+		strCh, err := q.StringChan()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		var contentType string
+		body, contentType = q.streamedBody(StringFromChan(strCh), o.gzip)
+		if o.gzip {
+			o.header.Set("Content-Encoding", "gzip")
+		}
+		o.header.Set("Content-Type", contentType)
+	default:
+		var b []byte
+		var err error
+		if o.gzip {
+			b, err = q.GzipJSONBody()
+			o.header.Set("Content-Encoding", "gzip")
+		} else {
+			var s string
+			s, err = q.JSON()
+			b = []byte(s)
+		}
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		body = bytes.NewReader(b)
+		o.header.Set("Content-Type", ContentTypeJSON)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	req.Header.Set("Accept", ContentTypeGraphQLResponse+", "+ContentTypeJSON+";q=0.9")
+	for k, v := range q.Headers {
+		req.Header.Set(k, v)
+	}
+	for k, v := range o.header {
+		req.Header[k] = v
+	}
+	return req, nil
+}
+
+// urlParams returns the encoded URL parameters of a request sending this Query, with the query document if document.
+func (q *Query) urlParams(document bool) (string, error) {
+	params := url.Values{}
+	if document {
+		strCh, err := q.StringChan()
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		params.Set("query", StringFromChan(strCh))
+	}
+	if q.Name != "" {
+		params.Set("operationName", q.Name)
+	}
+	if values := q.variableValues(); len(values) > 0 {
+		b, err := json.Marshal(values)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		params.Set("variables", string(b))
+	}
+	if len(q.Extensions) > 0 {
+		b, err := json.Marshal(q.Extensions)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		params.Set("extensions", string(b))
+	}
+	return params.Encode(), nil
+}
+
+// withParams appends encoded URL parameters to endpoint.
+func withParams(endpoint, params string) string {
+	if strings.Contains(endpoint, "?") {
+		return endpoint + "&" + params
+	}
+	return endpoint + "?" + params
+}
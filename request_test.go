@@ -0,0 +1,72 @@
+package graphb
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery_NewRequest(t *testing.T) {
+	q := MakeQuery(TypeQuery).
+		SetName("GetUser").
+		AddVariables(Variable{Name: "id", Type: "ID!", Value: "1"}).
+		SetFields(MakeField("user").SetArguments(ArgumentVariable("id", "id")).SetFields(MakeField("name"))).
+		AddHeader("X-Tenant", "a")
+
+	t.Run("POST", func(t *testing.T) {
+		req, err := q.NewRequest(context.Background(), http.MethodPost, "https://example.com/graphql", WithRequestHeader("X-Tenant", "b"))
+		assert.Nil(t, err)
+		assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+		assert.Equal(t, "application/graphql-response+json, application/json;q=0.9", req.Header.Get("Accept"))
+		assert.Equal(t, "b", req.Header.Get("X-Tenant"))
+		body, _ := io.ReadAll(req.Body)
+		assert.Equal(t, `{"query":"query GetUser($id:ID!){user(id:$id){name}}","variables":{"id":"1"}}`, string(body))
+	})
+
+	t.Run("gzip", func(t *testing.T) {
+		req, err := q.NewRequest(context.Background(), http.MethodPost, "https://example.com/graphql", WithGzipBody())
+		assert.Nil(t, err)
+		assert.Equal(t, "gzip", req.Header.Get("Content-Encoding"))
+		r, err := gzip.NewReader(req.Body)
+		assert.Nil(t, err)
+		body, _ := io.ReadAll(r)
+		assert.Equal(t, `{"query":"query GetUser($id:ID!){user(id:$id){name}}","variables":{"id":"1"}}`, string(body))
+	})
+
+	t.Run("GET", func(t *testing.T) {
+		req, err := q.NewRequest(context.Background(), http.MethodGet, "https://example.com/graphql?v=2")
+		assert.Nil(t, err)
+		assert.Nil(t, req.Body)
+		assert.Equal(t, "", req.Header.Get("Content-Type"))
+		assert.Equal(t, "a", req.Header.Get("X-Tenant"))
+		assert.Equal(t, url.Values{
+			"v":             {"2"},
+			"query":         {"query GetUser($id:ID!){user(id:$id){name}}"},
+			"operationName": {"GetUser"},
+			"variables":     {`{"id":"1"}`},
+		}, req.URL.Query())
+	})
+
+	t.Run("document body", func(t *testing.T) {
+		req, err := q.NewRequest(context.Background(), http.MethodPost, "https://example.com/graphql", WithDocumentBody())
+		assert.Nil(t, err)
+		assert.Equal(t, "application/graphql", req.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(req.Body)
+		assert.Equal(t, "query GetUser($id:ID!){user(id:$id){name}}", string(body))
+		assert.Equal(t, url.Values{"operationName": {"GetUser"}, "variables": {`{"id":"1"}`}}, req.URL.Query())
+
+		req, err = MakeQuery(TypeQuery).SetFields(MakeField("me")).NewRequest(context.Background(), http.MethodPost, "https://example.com/graphql", WithDocumentBody())
+		assert.Nil(t, err)
+		assert.Equal(t, "https://example.com/graphql", req.URL.String())
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		_, err := MakeQuery(TypeQuery).SetFields(MakeField("1")).NewRequest(context.Background(), http.MethodPost, "https://example.com/graphql")
+		assert.NotNil(t, err)
+	})
+}
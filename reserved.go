@@ -0,0 +1,40 @@
+package graphb
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// metaFields are the introspection fields a query may select, see: http://facebook.github.io/graphql/October2016/#sec-Reserved-Names
+var metaFields = map[string]bool{
+	"__typename": true,
+	"__schema":   true,
+	"__type":     true,
+}
+
+// reservedWords are the names which read as values in GraphQL.
+var reservedWords = map[string]bool{
+	"true":  true,
+	"false": true,
+	"null":  true,
+}
+
+// checkReservedNames checks that this Field does not use names reserved for introspection, unless c allows them.
+func (f *Field) checkReservedNames(c *Config) error {
+	if c != nil && c.AllowReservedNames {
+		return nil
+	}
+	if strings.HasPrefix(f.Name, "__") && !metaFields[f.Name] {
+		return errors.WithStack(ReservedNameErr{fieldName, f.Name})
+	}
+	if strings.HasPrefix(f.Alias, "__") && !metaFields[f.Alias] {
+		return errors.WithStack(ReservedNameErr{aliasName, f.Alias})
+	}
+	for _, arg := range f.Arguments {
+		if strings.HasPrefix(arg.Name, "__") || reservedWords[arg.Name] {
+			return errors.WithStack(ReservedNameErr{argumentName, arg.Name})
+		}
+	}
+	return nil
+}
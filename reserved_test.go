@@ -0,0 +1,44 @@
+package graphb
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestField_checkReservedNames(t *testing.T) {
+	for _, f := range []*Field{
+		MakeField("__typename"),
+		MakeField("__type").SetArguments(ArgumentString("name", "User")),
+		MakeField("_entities"),
+		MakeField("user").SetAlias("__typename"),
+	} {
+		assert.Nil(t, f.check())
+	}
+
+	cases := []struct {
+		field *Field
+		err   ReservedNameErr
+	}{
+		{MakeField("__secret"), ReservedNameErr{fieldName, "__secret"}},
+		{MakeField("user").SetAlias("__user"), ReservedNameErr{aliasName, "__user"}},
+		{MakeField("user").SetArguments(ArgumentInt("__id", 1)), ReservedNameErr{argumentName, "__id"}},
+		{MakeField("user").SetArguments(ArgumentBool("null", true)), ReservedNameErr{argumentName, "null"}},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.err, errors.Cause(c.field.check()))
+	}
+}
+
+func TestWithReservedNames(t *testing.T) {
+	build := func(config ...*Config) *Query {
+		return MakeQuery(TypeQuery, config...).SetFields(MakeField("__internal").SetArguments(ArgumentBool("true", true)))
+	}
+	s, err := build(NewConfig(WithReservedNames())).JSON()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"query":"query{__internal(true:true)}"}`, s)
+
+	_, err = build().JSON()
+	assert.Equal(t, ReservedNameErr{fieldName, "__internal"}, errors.Cause(err))
+}
@@ -0,0 +1,64 @@
+package graphb
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ResumeTokenStore stores the id of the last event received by each subscription, its resume token,
+// so that an SSEClient resumes a stream after a disconnect, or a restart if the store is persistent,
+// without missing events. Servers resume a stream from the Last-Event-ID header.
+type ResumeTokenStore interface {
+	// Token returns the resume token of the subscription of the given key, or "" if there is none.
+	Token(key string) (string, error)
+	// SaveToken saves the resume token of the subscription of the given key. An empty token forgets the subscription.
+	SaveToken(key, token string) error
+}
+
+// MemoryResumeTokenStore is a ResumeTokenStore keeping tokens in memory, which resumes streams after disconnects only.
+type MemoryResumeTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+// NewMemoryResumeTokenStore returns an empty MemoryResumeTokenStore.
+func NewMemoryResumeTokenStore() *MemoryResumeTokenStore {
+	return &MemoryResumeTokenStore{tokens: make(map[string]string)}
+}
+
+// Token implements ResumeTokenStore.
+func (s *MemoryResumeTokenStore) Token(key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tokens[key], nil
+}
+
+// SaveToken implements ResumeTokenStore.
+func (s *MemoryResumeTokenStore) SaveToken(key, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if token == "" {
+		delete(s.tokens, key)
+	} else {
+		s.tokens[key] = token
+	}
+	return nil
+}
+
+// subscriptionKey identifies the subscription q in a ResumeTokenStore: the Hash of q followed by the values of its variables, if any.
+func subscriptionKey(q *Query) (string, error) {
+	key, err := q.Hash()
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	if values := q.variableValues(); len(values) > 0 {
+		b, err := json.Marshal(values)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		key += string(b)
+	}
+	return key, nil
+}
@@ -0,0 +1,39 @@
+package graphb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryResumeTokenStore(t *testing.T) {
+	s := NewMemoryResumeTokenStore()
+	token, err := s.Token("a")
+	assert.Nil(t, err)
+	assert.Equal(t, "", token)
+
+	assert.Nil(t, s.SaveToken("a", "1"))
+	token, _ = s.Token("a")
+	assert.Equal(t, "1", token)
+
+	assert.Nil(t, s.SaveToken("a", ""))
+	assert.Empty(t, s.tokens)
+}
+
+func Test_subscriptionKey(t *testing.T) {
+	build := func(id interface{}) *Query {
+		return MakeQuery(TypeSubscription).
+			AddVariables(Variable{Name: "id", Type: "ID!", Value: id}).
+			SetFields(MakeField("likes").SetArguments(ArgumentVariable("post", "id")))
+	}
+	a, err := subscriptionKey(build("1"))
+	assert.Nil(t, err)
+	b, err := subscriptionKey(build("2"))
+	assert.Nil(t, err)
+	assert.NotEqual(t, a, b, "the values of variables are part of the key")
+
+	hash, _ := build(nil).Hash()
+	c, err := subscriptionKey(build(nil))
+	assert.Nil(t, err)
+	assert.Equal(t, hash, c)
+}
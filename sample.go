@@ -0,0 +1,87 @@
+package graphb
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+
+	"github.com/pkg/errors"
+)
+
+// Sample returns a copy of this Query keeping about fraction of its leaf fields, e.g. 0.1 for a tenth,
+// to canary a large new query gradually. Which leaves are kept is decided by seed and the path of each leaf,
+// so that a sample is the same every time, and raising fraction keeps the leaves of a lower one.
+// __typename is always kept. The leaves of a registered fragment are sampled alike wherever it is spread.
+// A field whose sub fields are all dropped is dropped as well, since a selection set can not be empty,
+// and variable definitions which are no longer referenced are dropped.
+// If this Query contains a cycle, the copy is not sampled and its E reports the cycle.
+func (q *Query) Sample(fraction float64, seed int64) *Query {
+	if err := q.checkCycles(); err != nil {
+		sampled := *q
+		sampled.E = errors.WithStack(err)
+		return &sampled
+	}
+
+	sampled := *q
+	sampled.Fields = sampleFields(q.Fields, "", fraction, seed)
+	sampled.Headers = make(map[string]string, len(q.Headers))
+	for k, v := range q.Headers {
+		sampled.Headers[k] = v
+	}
+
+	used := sampled.referencedVariables()
+	sampled.Variables = nil
+	for _, v := range q.Variables {
+		if used[v.Name] {
+			sampled.Variables = append(sampled.Variables, v)
+		}
+	}
+	return &sampled
+}
+
+// sampleFields copies the sampled fields of fs, at path, recursively.
+func sampleFields(fs []*Field, path string, fraction float64, seed int64) []*Field {
+	var sampled []*Field
+	for _, f := range fs {
+		if f == nil {
+			// keep nil fields so that validation still reports them
+			sampled = append(sampled, f)
+			continue
+		}
+		fieldPath := path + "." + f.Name
+		if f.Alias != "" {
+			fieldPath = path + "." + f.Alias
+		}
+		if f.fragment != nil {
+			fieldPath = "..." + f.fragment.Name
+		}
+		if len(f.Fields) == 0 || f.compiled != nil {
+			if f.Name == "__typename" || sampledIn(fieldPath, fraction, seed) {
+				sampled = append(sampled, f)
+			}
+			continue
+		}
+		copied := *f
+		copied.Fields = sampleFields(f.Fields, fieldPath, fraction, seed)
+		if len(copied.Fields) == 0 {
+			continue
+		}
+		sampled = append(sampled, &copied)
+	}
+	return sampled
+}
+
+// sampledIn reports whether the leaf at path is in the sample of fraction drawn with seed.
+func sampledIn(path string, fraction float64, seed int64) bool {
+	h := fnv.New64a()
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], uint64(seed))
+	h.Write(b[:])
+	h.Write([]byte(path))
+	// FNV barely changes the high bits for paths differing by their last bytes, mix them as splitmix64 does
+	x := h.Sum64()
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	x ^= x >> 31
+	// the 53 high bits as a uniform float in [0, 1)
+	return float64(x>>11)/(1<<53) < fraction
+}
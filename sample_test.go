@@ -0,0 +1,101 @@
+package graphb
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// leafCount returns the number of leaf fields of fs.
+func leafCount(fs []*Field) int {
+	n := 0
+	for _, f := range fs {
+		if len(f.Fields) == 0 {
+			n++
+		}
+		n += leafCount(f.Fields)
+	}
+	return n
+}
+
+func TestQuery_Sample(t *testing.T) {
+	leaves := make([]string, 200)
+	for i := range leaves {
+		leaves[i] = fmt.Sprintf("f%d", i)
+	}
+	q := MakeQuery(TypeQuery).
+		AddVariables(Variable{Name: "id", Type: "ID!", Value: "1"}).
+		SetFields(
+			MakeField("user").SetArguments(ArgumentVariable("id", "id")).SetFields(Fields("a", "b")...),
+			MakeField("report").SetFields(append(Fields(leaves...), MakeField("__typename"))...),
+		)
+
+	all := q.Sample(1, 42)
+	assert.Nil(t, all.E)
+	assert.Equal(t, 203, leafCount(all.Fields))
+	assert.Len(t, all.Variables, 1)
+
+	tenth := q.Sample(0.1, 42)
+	n := leafCount(tenth.Fields)
+	assert.True(t, n > 5 && n < 45, "%d leaves", n)
+	assert.Equal(t, tenth, q.Sample(0.1, 42), "deterministic")
+	assert.NotEqual(t, StringFromChan(tenth.stringChan()), StringFromChan(q.Sample(0.1, 7).stringChan()))
+
+	// raising the fraction keeps the leaves of a lower one
+	half := q.Sample(0.5, 42)
+	kept := make(map[string]bool)
+	for _, f := range half.GetField("report").Fields {
+		kept[f.Name] = true
+	}
+	for _, f := range tenth.GetField("report").Fields {
+		assert.True(t, kept[f.Name], "%s", f.Name)
+	}
+
+	// emptied fields and their variables are dropped, __typename is kept
+	none := q.Sample(0, 42)
+	assert.Nil(t, none.GetField("user"))
+	assert.Empty(t, none.Variables)
+	s, err := none.StringChan()
+	assert.Nil(t, err)
+	assert.Equal(t, "query{report{__typename}}", StringFromChan(s))
+	// the original is left untouched
+	assert.Equal(t, 203, leafCount(q.Fields))
+
+	cyclic := MakeField("a")
+	cyclic.Fields = []*Field{cyclic}
+	_, ok := errors.Cause(MakeQuery(TypeQuery).SetFields(cyclic).Sample(0.5, 1).E).(CyclicFieldErr)
+	assert.True(t, ok)
+}
+
+func TestQuery_Sample_fragments(t *testing.T) {
+	r := NewFragmentRegistry()
+	leaves := make([]string, 50)
+	for i := range leaves {
+		leaves[i] = fmt.Sprintf("f%d", i)
+	}
+	assert.Nil(t, r.RegisterFragment("UserCard", "User", Fields(leaves...)...))
+	q := NewQuery(TypeQuery, OfFragmentDefinitions(),
+		OfField("me", OfSpread(r, "UserCard")),
+		OfField("author", OfSpread(r, "UserCard")),
+	)
+	assert.Nil(t, q.E)
+
+	sampled := q.Sample(0.3, 1)
+	me, author := sampled.GetField("me").Fields[0], sampled.GetField("author").Fields[0]
+	assert.Equal(t, me.Fields, author.Fields)
+	assert.True(t, len(me.Fields) < 50)
+	_, err := sampled.StringChan()
+	assert.Nil(t, err)
+}
+
+func TestQuery_Sample_directiveVariables(t *testing.T) {
+	q := MakeQuery(TypeQuery).
+		AddVariables(Variable{Name: "live", Type: "Boolean!"}).
+		AddDirectives(MakeDirective("live", ArgumentVariable("if", "live"))).
+		SetFields(MakeField("user").SetFields(MakeField("name"), MakeField("__typename")))
+	s, err := q.Sample(0, 1).String()
+	assert.Nil(t, err)
+	assert.Equal(t, "query($live:Boolean!)@live(if:$live){user{__typename}}", s)
+}
@@ -0,0 +1,89 @@
+package graphb
+
+import (
+	"github.com/pkg/errors"
+)
+
+// RequireScope tags this Field with authorization scopes, all of which a caller must hold to request the Field.
+// See Query.StripUnauthorized.
+func (f *Field) RequireScope(scopes ...string) *Field {
+	f.Scopes = append(f.Scopes, scopes...)
+	return f
+}
+
+// OfRequiredScopes returns a FieldOption which tags the targeting field with authorization scopes.
+func OfRequiredScopes(scopes ...string) FieldOption {
+	return func(f *Field) error {
+		f.Scopes = append(f.Scopes, scopes...)
+		return nil
+	}
+}
+
+// StripUnauthorized returns a copy of this Query without the fields whose required scopes are not all held.
+// A field whose sub fields are all stripped is stripped as well, since a selection set can not be empty.
+// Variable definitions which are no longer referenced are dropped.
+// The original Query is left untouched, so one definition can serve callers of different privileges.
+// If this Query contains a cycle, the copy is not stripped and its E reports the cycle.
+func (q *Query) StripUnauthorized(scopes []string) *Query {
+	if err := q.checkCycles(); err != nil {
+		stripped := *q
+		stripped.E = errors.WithStack(err)
+		return &stripped
+	}
+
+	held := make(map[string]bool, len(scopes))
+	for _, s := range scopes {
+		held[s] = true
+	}
+
+	stripped := *q
+	stripped.Fields = stripFields(q.Fields, held)
+	stripped.Headers = make(map[string]string, len(q.Headers))
+	for k, v := range q.Headers {
+		stripped.Headers[k] = v
+	}
+
+	used := stripped.referencedVariables()
+	stripped.Variables = nil
+	for _, v := range q.Variables {
+		if used[v.Name] {
+			stripped.Variables = append(stripped.Variables, v)
+		}
+	}
+	return &stripped
+}
+
+// authorized reports whether all the scopes required by this Field are held.
+func (f *Field) authorized(held map[string]bool) bool {
+	for _, s := range f.Scopes {
+		if !held[s] {
+			return false
+		}
+	}
+	return true
+}
+
+// stripFields copies the authorized fields of fs recursively.
+func stripFields(fs []*Field, held map[string]bool) []*Field {
+	var stripped []*Field
+	for _, f := range fs {
+		if f == nil {
+			// keep nil fields so that validation still reports them
+			stripped = append(stripped, f)
+			continue
+		}
+		if !f.authorized(held) {
+			continue
+		}
+		copied := *f
+		copied.compiled = nil
+		copied.Arguments = append([]Argument(nil), f.Arguments...)
+		copied.Scopes = append([]string(nil), f.Scopes...)
+		copied.Fields = stripFields(f.Fields, held)
+		if len(f.Fields) > 0 && len(copied.Fields) == 0 {
+			continue
+		}
+		stripped = append(stripped, &copied)
+	}
+	return stripped
+}
@@ -0,0 +1,73 @@
+package graphb
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestField_RequireScope(t *testing.T) {
+	f := MakeField("billing").RequireScope("read:billing").RequireScope("read:user")
+	assert.Equal(t, []string{"read:billing", "read:user"}, f.Scopes)
+
+	f = NewField("billing", OfRequiredScopes("read:billing"))
+	assert.Nil(t, f.E)
+	assert.Equal(t, []string{"read:billing"}, f.Scopes)
+}
+
+func TestQuery_StripUnauthorized(t *testing.T) {
+	q := MakeQuery(TypeQuery).
+		AddVariables(Variable{Name: "since", Type: "String"}).
+		SetFields(
+			MakeField("user").SetFields(
+				MakeField("name"),
+				MakeField("invoices").
+					RequireScope("read:billing").
+					SetArguments(ArgumentVariable("since", "since")).
+					SetFields(MakeField("amount")),
+			),
+			MakeField("audit").SetFields(
+				MakeField("entries").RequireScope("read:audit"),
+			),
+		).
+		AddHeader("Authorization", "token")
+
+	full, err := q.StripUnauthorized([]string{"read:billing", "read:audit"}).JSON()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"query":"query($since:String){user{name,invoices(since:$since){amount}},audit{entries}}"}`, full)
+
+	stripped := q.StripUnauthorized(nil)
+	s, err := stripped.JSON()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"query":"query{user{name}}"}`, s)
+	assert.Equal(t, map[string]string{"Authorization": "token"}, stripped.GetHeaders())
+
+	// the original query is untouched
+	s, err = q.JSON()
+	assert.Nil(t, err)
+	assert.Equal(t, full, s)
+}
+
+func TestQuery_StripUnauthorized_cycle(t *testing.T) {
+	f := MakeField("a")
+	f.SetFields(MakeField("b").SetFields(f))
+	q := MakeQuery(TypeQuery).SetFields(f)
+
+	stripped := q.StripUnauthorized(nil)
+	assert.IsType(t, CyclicFieldErr{}, errors.Cause(stripped.E))
+	assert.Nil(t, q.E)
+}
+
+func TestQuery_StripUnauthorized_directiveVariables(t *testing.T) {
+	q := MakeQuery(TypeQuery).
+		AddVariables(Variable{Name: "live", Type: "Boolean!"}, Variable{Name: "secret", Type: "ID"}).
+		AddDirectives(MakeDirective("live", ArgumentVariable("if", "live"))).
+		SetFields(
+			MakeField("user").SetFields(MakeField("name")),
+			MakeField("admin").RequireScope("admin").SetArguments(ArgumentVariable("id", "secret")).SetFields(MakeField("id")),
+		)
+	s, err := q.StripUnauthorized(nil).String()
+	assert.Nil(t, err)
+	assert.Equal(t, "query($live:Boolean!)@live(if:$live){user{name}}", s)
+}
@@ -0,0 +1,275 @@
+package graphb
+
+import (
+	"container/list"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ShapeCache is an LRU cache of serialized queries keyed by their shape, which is everything serialized in the query text:
+// operation, fields, arguments and directives, but not the values of variables, which are sent separately.
+// Hot paths sending the same shape with different variable values skip validation and serialization on a hit.
+// It is safe for concurrent use.
+type ShapeCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // of *shapeEntry, the most recently used first
+	entries  map[string]*list.Element
+}
+
+type shapeEntry struct {
+	key   string
+	query string
+}
+
+// NewShapeCache returns a ShapeCache holding up to capacity serialized queries.
+func NewShapeCache(capacity int) *ShapeCache {
+	return &ShapeCache{capacity: capacity, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// String returns the serialized q, from the cache if a query of the same shape was serialized before.
+func (c *ShapeCache) String(q *Query) (string, error) {
+	key, err := q.shapeKey()
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	if s, ok := c.get(key); ok {
+		return s, nil
+	}
+	strCh, err := q.StringChan()
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	s := StringFromChan(strCh)
+	c.add(key, s)
+	return s, nil
+}
+
+// JSON returns the same as q.JSON(), with the query text from the cache if possible.
+func (c *ShapeCache) JSON(q *Query) (string, error) {
+	s, err := c.String(q)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return q.jsonOf(s)
+}
+
+// Len returns the number of cached queries.
+func (c *ShapeCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *ShapeCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*shapeEntry).query, true
+}
+
+func (c *ShapeCache) add(key, query string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&shapeEntry{key, query})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*shapeEntry).key)
+	}
+}
+
+// shapeKey walks this Query directly, which is much cheaper than serializing it through channels.
+// Strings are length prefixed so that no two different shapes share a key, even invalid ones.
+// It returns a CyclicFieldErr instead of walking a cycle forever.
+func (q *Query) shapeKey() (string, error) {
+	var b strings.Builder
+	writeKey(&b, string(q.Type))
+	writeKey(&b, q.Name)
+	if q.FragmentDefinitions {
+		b.WriteByte('F')
+	}
+	if c := q.Config; c != nil {
+		b.WriteByte('C')
+		writeKey(&b, c.timeFormat())
+		if c.TimeLocation != nil {
+			writeKey(&b, c.TimeLocation.String())
+		}
+		writeKey(&b, boolLiteral(c.StrictEscaping))
+		writeKey(&b, boolLiteral(c.TypenameInjection))
+		writeKey(&b, boolLiteral(c.NormalizeEnumValues))
+		writeKey(&b, boolLiteral(c.AllowReservedNames))
+		writeKey(&b, boolLiteral(c.CheckIntRange))
+		writeKey(&b, boolLiteral(c.CheckSelections))
+		writeKey(&b, boolLiteral(c.SkipNilFields))
+		writeKey(&b, boolLiteral(c.CheckSharedFields))
+		writeKey(&b, intLiteral(int(c.Style)))
+	}
+	for _, d := range q.Directives {
+		writeDirectiveKey(&b, d)
+	}
+	for _, v := range q.Variables {
+		b.WriteByte('$')
+		writeKey(&b, v.Name)
+		writeKey(&b, v.Type)
+		if v.defaultValue != nil {
+			b.WriteByte('=')
+			writeValueKey(&b, v.defaultValue)
+		}
+	}
+	for _, op := range q.OperationOptions {
+		b.WriteByte('O')
+		writeTokensKey(&b, op.Prefix())
+		writeTokensKey(&b, op.Suffix())
+	}
+	onPath := make(map[*Field]bool)
+	for _, f := range q.Fields {
+		if err := f.writeShapeKey(&b, onPath); err != nil {
+			return "", errors.WithStack(err)
+		}
+	}
+	return b.String(), nil
+}
+
+func (f *Field) writeShapeKey(b *strings.Builder, onPath map[*Field]bool) error {
+	if f == nil {
+		b.WriteByte('-')
+		return nil
+	}
+	if onPath[f] {
+		return errors.WithStack(CyclicFieldErr{*f})
+	}
+	onPath[f] = true
+	defer delete(onPath, f)
+
+	b.WriteByte('{')
+	if f.compiled != nil {
+		writeTokensKey(b, f.compiled)
+		b.WriteByte('}')
+		return nil
+	}
+	writeKey(b, f.Alias)
+	writeKey(b, f.Name)
+	if f.fragment != nil {
+		writeKey(b, f.fragment.Name)
+	}
+	for _, arg := range f.Arguments {
+		b.WriteByte('(')
+		writeArgumentKey(b, arg)
+	}
+	for _, d := range f.Directives {
+		writeDirectiveKey(b, d)
+	}
+	for _, subF := range f.Fields {
+		if err := subF.writeShapeKey(b, onPath); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	b.WriteByte('}')
+	return nil
+}
+
+func writeDirectiveKey(b *strings.Builder, d Directive) {
+	b.WriteByte('@')
+	if d.impliesVariables {
+		b.WriteByte('!')
+	}
+	writeKey(b, d.Name)
+	for _, arg := range d.Arguments {
+		b.WriteByte('(')
+		writeArgumentKey(b, arg)
+	}
+}
+
+func writeArgumentKey(b *strings.Builder, arg Argument) {
+	writeKey(b, arg.Name)
+	writeValueKey(b, arg.Value)
+}
+
+func writeValueKey(b *strings.Builder, value argumentValue) {
+	switch v := value.(type) {
+	case argBool:
+		writeKey(b, boolLiteral(bool(v)))
+	case argInt:
+		writeKey(b, intLiteral(int(v)))
+	case argString:
+		b.WriteByte('s')
+		writeKey(b, string(v))
+	case argEnum:
+		b.WriteByte('e')
+		writeKey(b, string(v))
+	case argVariable:
+		b.WriteByte('$')
+		writeKey(b, string(v))
+	case argIntSlice:
+		b.WriteByte('[')
+		for _, i := range v {
+			writeKey(b, intLiteral(i))
+		}
+		b.WriteByte(']')
+	case argStringSlice:
+		b.WriteString("s[")
+		for _, s := range v {
+			writeKey(b, s)
+		}
+		b.WriteByte(']')
+	case argumentCustom:
+		b.WriteByte('{')
+		for _, arg := range v {
+			writeArgumentKey(b, arg)
+		}
+		b.WriteByte('}')
+	case argTime:
+		// exact, whatever the time format of the Config
+		b.WriteByte('T')
+		writeKey(b, time.Time(v).Format(time.RFC3339Nano))
+		writeKey(b, time.Time(v).Location().String())
+	case argArgSlice:
+		b.WriteByte('[')
+		for _, args := range v {
+			writeValueKey(b, argumentCustom(args))
+		}
+		b.WriteByte(']')
+	case argList:
+		b.WriteString("l[")
+		for _, value := range v {
+			writeValueKey(b, value)
+		}
+		b.WriteByte(']')
+	case argDefaulted:
+		b.WriteByte('d')
+		writeValueKey(b, v.value)
+		writeValueKey(b, v.defaultValue)
+	default:
+		// the serialized value, which is exact but slower
+		b.WriteByte('t')
+		for tok := range value.tokenChan() {
+			writeKey(b, tok.Literal)
+		}
+	}
+}
+
+func writeTokensKey(b *strings.Builder, tokens []Token) {
+	b.WriteString(strconv.Itoa(len(tokens)))
+	for _, tok := range tokens {
+		writeKey(b, tok.Literal)
+	}
+}
+
+func writeKey(b *strings.Builder, s string) {
+	b.WriteString(strconv.Itoa(len(s)))
+	b.WriteByte(':')
+	b.WriteString(s)
+}
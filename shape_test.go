@@ -0,0 +1,89 @@
+package graphb
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func userQuery(id string, fields ...string) *Query {
+	return MakeQuery(TypeQuery).
+		AddVariables(Variable{Name: "id", Type: "ID!", Value: id}).
+		SetFields(MakeField("user").SetArguments(ArgumentVariable("id", "id")).SetFields(Fields(fields...)...))
+}
+
+func TestShapeCache(t *testing.T) {
+	c := NewShapeCache(2)
+
+	s, err := c.JSON(userQuery("1", "name"))
+	assert.Nil(t, err)
+	assert.Equal(t, `{"query":"query($id:ID!){user(id:$id){name}}","variables":{"id":"1"}}`, s)
+	s, err = c.JSON(userQuery("2", "name"))
+	assert.Nil(t, err)
+	assert.Equal(t, `{"query":"query($id:ID!){user(id:$id){name}}","variables":{"id":"2"}}`, s)
+	assert.Equal(t, 1, c.Len())
+
+	s, err = c.String(userQuery("1", "email"))
+	assert.Nil(t, err)
+	assert.Equal(t, "query($id:ID!){user(id:$id){email}}", s)
+	assert.Equal(t, 2, c.Len())
+
+	// touch "name" so that "email" is evicted
+	_, err = c.String(userQuery("3", "name"))
+	assert.Nil(t, err)
+	_, err = c.String(userQuery("1", "age"))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, c.Len())
+	nameKey, _ := userQuery("", "name").shapeKey()
+	emailKey, _ := userQuery("", "email").shapeKey()
+	_, ok := c.get(nameKey)
+	assert.True(t, ok)
+	_, ok = c.get(emailKey)
+	assert.False(t, ok)
+}
+
+func TestShapeCache_errors(t *testing.T) {
+	c := NewShapeCache(2)
+	_, err := c.String(userQuery("1", "bad name"))
+	assert.Equal(t, InvalidNameErr{fieldName, "bad name"}, errors.Cause(err))
+	assert.Equal(t, 0, c.Len())
+
+	f := MakeField("a")
+	f.SetFields(MakeField("b").SetFields(f))
+	_, err = c.String(MakeQuery(TypeQuery).SetFields(MakeField("root").SetFields(f)))
+	assert.IsType(t, CyclicFieldErr{}, errors.Cause(err))
+}
+
+func TestQuery_shapeKey(t *testing.T) {
+	key := func(q *Query) string {
+		k, err := q.shapeKey()
+		assert.Nil(t, err)
+		return k
+	}
+	withArg := func(arg Argument) *Query {
+		return MakeQuery(TypeQuery).SetFields(MakeField("a").SetArguments(arg))
+	}
+	assert.Equal(t, key(userQuery("1", "a")), key(userQuery("2", "a")))
+	assert.NotEqual(t, key(withArg(ArgumentString("x", "1"))), key(withArg(ArgumentInt("x", 1))))
+	assert.NotEqual(t, key(withArg(ArgumentString("x", "E"))), key(withArg(ArgumentEnum("x", "E"))))
+	assert.NotEqual(t, key(withArg(ArgumentIntSlice("x", 1, 2))), key(withArg(ArgumentIntSlice("x", 12))))
+	assert.NotEqual(t, key(MakeQuery(TypeQuery).SetFields(Fields("ab")...)), key(MakeQuery(TypeQuery).SetFields(Fields("a", "b")...)))
+	assert.NotEqual(t, key(MakeQuery(TypeQuery).SetFields(Fields("a")...)), key(MakeQuery(TypeMutation).SetFields(Fields("a")...)))
+}
+
+func TestQuery_shapeKey_inputObjects(t *testing.T) {
+	key := func(arg Argument) string {
+		k, err := MakeQuery(TypeQuery).SetFields(MakeField("a").SetArguments(arg)).shapeKey()
+		assert.Nil(t, err)
+		return k
+	}
+	assert.NotEqual(t,
+		key(ArgumentCustomType("x", ArgumentCustomType("y", ArgumentInt("z", 1)))),
+		key(ArgumentCustomType("x", ArgumentCustomType("y"), ArgumentInt("z", 1))))
+	assert.NotEqual(t,
+		key(ArgumentSlice("x", []Argument{ArgumentInt("y", 1)}, []Argument{ArgumentInt("z", 1)})),
+		key(ArgumentSlice("x", []Argument{ArgumentInt("y", 1), ArgumentInt("z", 1)})))
+	assert.NotEqual(t, key(ArgumentStringSlice("x", "a", "b")), key(ArgumentEnumSlice("x", "a", "b")))
+	assert.NotEqual(t, key(ArgumentStringSlice("x")), key(ArgumentIntSlice("x")))
+}
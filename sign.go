@@ -0,0 +1,58 @@
+package graphb
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// SignatureHeaders names the headers Query.Sign writes into Query.Headers.
+type SignatureHeaders struct {
+	Signature string
+	Timestamp string
+}
+
+// DefaultSignatureHeaders is used by Query.Sign when no header names are given.
+var DefaultSignatureHeaders = SignatureHeaders{
+	Signature: "X-Graphql-Signature",
+	Timestamp: "X-Graphql-Timestamp",
+}
+
+// Hash returns the hex encoded SHA-256 hash of the serialized query, which identifies a query canonically.
+func (q *Query) Hash() (string, error) {
+	strCh, err := q.StringChan()
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	sum := sha256.Sum256([]byte(StringFromChan(strCh)))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Sign signs this Query for gateways which require request signing.
+// The signature is the hex encoded HMAC-SHA256, keyed by key, of the query Hash and the unix timestamp joined by a new line.
+// The signature and the timestamp are added as headers, named by headers or DefaultSignatureHeaders if omitted.
+func (q *Query) Sign(key []byte, timestamp time.Time, headers ...SignatureHeaders) error {
+	names := DefaultSignatureHeaders
+	if len(headers) > 0 {
+		names = headers[0]
+	}
+	hash, err := q.Hash()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(hash + "\n" + ts))
+
+	if q.Headers == nil {
+		q.Headers = make(map[string]string)
+	}
+	q.AddHeader(names.Signature, hex.EncodeToString(mac.Sum(nil)))
+	q.AddHeader(names.Timestamp, ts)
+	return nil
+}
@@ -0,0 +1,54 @@
+package graphb
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery_Hash(t *testing.T) {
+	q := MakeQuery(TypeQuery).SetFields(MakeField("me"))
+	hash, err := q.Hash()
+	assert.Nil(t, err)
+	sum := sha256.Sum256([]byte("query{me}"))
+	assert.Equal(t, hex.EncodeToString(sum[:]), hash)
+
+	_, err = MakeQuery(TypeQuery).SetName("1").Hash()
+	assert.IsType(t, InvalidNameErr{}, errors.Cause(err))
+}
+
+func TestQuery_Sign(t *testing.T) {
+	key := []byte("secret")
+	ts := time.Unix(1500000000, 0)
+
+	t.Run("default headers", func(t *testing.T) {
+		q := MakeQuery(TypeQuery).SetFields(MakeField("me"))
+		assert.Nil(t, q.Sign(key, ts))
+
+		hash, _ := q.Hash()
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(hash + "\n1500000000"))
+		assert.Equal(t, map[string]string{
+			"X-Graphql-Signature": hex.EncodeToString(mac.Sum(nil)),
+			"X-Graphql-Timestamp": "1500000000",
+		}, q.GetHeaders())
+	})
+
+	t.Run("custom headers", func(t *testing.T) {
+		q := &Query{Type: TypeQuery, Fields: Fields("me")}
+		assert.Nil(t, q.Sign(key, ts, SignatureHeaders{"X-Sig", "X-Ts"}))
+		assert.Len(t, q.Headers["X-Sig"], 64)
+		assert.Equal(t, "1500000000", q.Headers["X-Ts"])
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		q := MakeQuery("muTatio")
+		assert.IsType(t, InvalidOperationTypeErr{}, errors.Cause(q.Sign(key, ts)))
+		assert.Empty(t, q.GetHeaders())
+	})
+}
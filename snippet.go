@@ -0,0 +1,62 @@
+package graphb
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// CurlCommand returns a curl command sending this Query to endpoint, e.g. to share the reproduction of an issue.
+// The request has the headers of this Query and headers, which replace the ones of the same key.
+// Every argument is single quoted for POSIX shells.
+func (q *Query) CurlCommand(endpoint string, headers map[string]string) (string, error) {
+	body, err := q.JSON()
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	lines := []string{"curl -X POST " + shellQuote(endpoint)}
+	for _, h := range q.snippetHeaders(headers) {
+		lines = append(lines, "-H "+shellQuote(h[0]+": "+h[1]))
+	}
+	lines = append(lines, "--data-raw "+shellQuote(body))
+	return strings.Join(lines, " \\\n  "), nil
+}
+
+// HTTPieCommand returns an HTTPie command sending this Query to endpoint, like CurlCommand.
+func (q *Query) HTTPieCommand(endpoint string, headers map[string]string) (string, error) {
+	body, err := q.JSON()
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	lines := []string{"http POST " + shellQuote(endpoint)}
+	for _, h := range q.snippetHeaders(headers) {
+		lines = append(lines, shellQuote(h[0]+":"+h[1]))
+	}
+	lines = append(lines, "--raw "+shellQuote(body))
+	return strings.Join(lines, " \\\n  "), nil
+}
+
+// snippetHeaders returns the headers of a request sending this Query with headers, sorted by key.
+func (q *Query) snippetHeaders(headers map[string]string) [][2]string {
+	merged := map[string]string{"Content-Type": ContentTypeJSON}
+	for k, v := range q.Headers {
+		merged[k] = v
+	}
+	for k, v := range headers {
+		merged[k] = v
+	}
+	sorted := make([][2]string, 0, len(merged))
+	for k, v := range merged {
+		sorted = append(sorted, [2]string{k, v})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i][0] < sorted[j][0]
+	})
+	return sorted
+}
+
+// shellQuote quotes s for POSIX shells: in single quotes, each of its own single quotes being closed, escaped and reopened.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
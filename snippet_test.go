@@ -0,0 +1,46 @@
+package graphb
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery_CurlCommand(t *testing.T) {
+	q := MakeQuery(TypeQuery).
+		AddVariables(Variable{Name: "name", Type: "String!", Value: "O'Brien"}).
+		SetFields(MakeField("users").SetArguments(ArgumentVariable("name", "name")).SetFields(MakeField("id"))).
+		AddHeader("Authorization", "Bearer a")
+
+	s, err := q.CurlCommand("https://example.com/graphql", map[string]string{"Authorization": "Bearer b", "X-Tenant": "t"})
+	assert.Nil(t, err)
+	assert.Equal(t, `curl -X POST 'https://example.com/graphql' \
+  -H 'Authorization: Bearer b' \
+  -H 'Content-Type: application/json' \
+  -H 'X-Tenant: t' \
+  --data-raw '{"query":"query($name:String!){users(name:$name){id}}","variables":{"name":"O'\''Brien"}}'`, s)
+
+	s, err = q.HTTPieCommand("https://example.com/graphql", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, `http POST 'https://example.com/graphql' \
+  'Authorization:Bearer a' \
+  'Content-Type:application/json' \
+  --raw '{"query":"query($name:String!){users(name:$name){id}}","variables":{"name":"O'\''Brien"}}'`, s)
+
+	_, err = MakeQuery(TypeQuery).SetFields(nil).CurlCommand("https://example.com/graphql", nil)
+	assert.Equal(t, NilFieldErr{}, errors.Cause(err))
+}
+
+func TestShellQuote(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no shell")
+	}
+	for _, s := range []string{"", "plain", "it's", `"double" $HOME \n`, "''", "a\nb"} {
+		out, err := exec.Command(sh, "-c", "printf '%s' "+shellQuote(s)).Output()
+		assert.Nil(t, err)
+		assert.Equal(t, s, string(out))
+	}
+}
@@ -0,0 +1,492 @@
+package graphb
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ContentTypeEventStream is the media type of Server-Sent Events.
+const ContentTypeEventStream = "text/event-stream"
+
+// sseTokenHeader carries the reservation token of a single connection, see SSEClient.SingleConnection.
+const sseTokenHeader = "X-GraphQL-Event-Stream-Token"
+
+// SubscriptionPayload is an execution result of a subscription.
+// A payload whose Err is not nil reports a transport or decoding failure and is the last one.
+type SubscriptionPayload struct {
+	Data       json.RawMessage  `json:"data,omitempty"`
+	Errors     json.RawMessage  `json:"errors,omitempty"`
+	Extensions json.RawMessage  `json:"extensions,omitempty"`
+	Err        error            `json:"-"`
+	Changes    []PatchOperation `json:"-"` // The changes from the previous result sent by Poll, see DiffResults.
+}
+
+// SSEClient executes subscriptions with the GraphQL over Server-Sent Events protocol,
+// which passes firewalls and proxies more easily than WebSockets.
+//
+// In the distinct connections mode, the default, each subscription is a POST request whose response streams its results.
+// In the single connection mode, the client reserves a stream shared by all its subscriptions,
+// for servers or browsers limiting the number of connections.
+type SSEClient struct {
+	Endpoint         string
+	Client           *http.Client    // http.DefaultClient if nil.
+	Options          []RequestOption // Options of every request, e.g. authorization headers.
+	SingleConnection bool
+
+	// Resume, if not nil, stores the resume tokens of the subscriptions of the distinct connections mode,
+	// which are sent as the Last-Event-ID header. Streams keep their last event id in memory anyway.
+	Resume ResumeTokenStore
+	// MaxReconnects is how many times a stream of the distinct connections mode which ends before its completion is reopened,
+	// resuming after its last event. Streams are not reopened by default.
+	MaxReconnects int
+	// Buffer is the number of results a subscription buffers for a slow consumer, and Overflow what it does once they are buffered.
+	// By default, no result is buffered and a slow consumer holds back the stream.
+	Buffer   int
+	Overflow OverflowPolicy
+
+	mu     sync.Mutex
+	stream *sseStream // The stream of the single connection mode, nil until the first subscription.
+	nextID int
+}
+
+// Subscribe executes the subscription q and returns the channel of its results,
+// which is closed when the server completes the subscription, the stream fails, or ctx is done.
+func (c *SSEClient) Subscribe(ctx context.Context, q *Query) (<-chan SubscriptionPayload, error) {
+	if c.SingleConnection {
+		return c.subscribeSingle(ctx, q)
+	}
+	var key, token string
+	if c.Resume != nil {
+		var err error
+		if key, err = subscriptionKey(q); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if token, err = c.Resume.Token(key); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	body, err := c.openDistinct(ctx, q, token)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	payloads := newPayloads(c.Buffer, c.Overflow)
+	go func() {
+		defer close(payloads)
+		for reconnects := 0; ; reconnects++ {
+			completed, resumable, err := c.readDistinct(ctx, body, payloads, key, &token)
+			body.Close()
+			if completed || ctx.Err() != nil {
+				return
+			}
+			if resumable && reconnects < c.MaxReconnects {
+				if body, err = c.openDistinct(ctx, q, token); err == nil {
+					continue
+				}
+			}
+			if ctx.Err() == nil {
+				select {
+				case payloads <- SubscriptionPayload{Err: errors.WithStack(err)}:
+				case <-ctx.Done():
+				}
+			}
+			return
+		}
+	}()
+	return payloads, nil
+}
+
+// openDistinct opens the stream of the subscription q in the distinct connections mode, resuming after the event token, if any.
+func (c *SSEClient) openDistinct(ctx context.Context, q *Query, token string) (io.ReadCloser, error) {
+	options := append(append([]RequestOption(nil), c.Options...), WithRequestHeader("Accept", ContentTypeEventStream))
+	if token != "" {
+		options = append(options, WithRequestHeader("Last-Event-ID", token))
+	}
+	req, err := q.NewRequest(ctx, http.MethodPost, c.Endpoint, options...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	body, err := c.openStream(req)
+	return body, errors.WithStack(err)
+}
+
+// readDistinct delivers the results of a stream of the distinct connections mode until it ends,
+// keeping token, and the resume token of key if c resumes streams, to the id of the last event.
+// It reports whether the server completed the subscription, and otherwise whether the stream can be resumed and why it ended.
+func (c *SSEClient) readDistinct(ctx context.Context, body io.Reader, payloads chan SubscriptionPayload, key string, token *string) (bool, bool, error) {
+	completed := false
+	var failure error
+	err := readEvents(body, func(e sseEvent) bool {
+		switch e.event {
+		case "next":
+			var payload SubscriptionPayload
+			if err := json.Unmarshal([]byte(e.data), &payload); err != nil {
+				failure = errors.WithStack(err)
+				return false
+			}
+			if err := deliverPayload(ctx, payloads, payload, c.Overflow); err != nil {
+				failure = err
+				return false
+			}
+			if e.id != "" && e.id != *token {
+				*token = e.id
+				if c.Resume != nil {
+					if err := c.Resume.SaveToken(key, e.id); err != nil {
+						failure = errors.WithStack(err)
+						return false
+					}
+				}
+			}
+		case "complete":
+			completed = true
+			if c.Resume != nil {
+				failure = errors.WithStack(c.Resume.SaveToken(key, ""))
+			}
+			return false
+		}
+		return true
+	})
+	if failure != nil {
+		return false, false, failure
+	}
+	if completed {
+		return true, false, nil
+	}
+	if err == nil {
+		err = io.ErrUnexpectedEOF
+	}
+	return false, true, errors.WithStack(err)
+}
+
+func (c *SSEClient) httpClient() *http.Client {
+	if c.Client != nil {
+		return c.Client
+	}
+	return http.DefaultClient
+}
+
+// openStream sends req and returns the body of its event stream.
+func (c *SSEClient) openStream(req *http.Request) (io.ReadCloser, error) {
+	req.Header.Set("Accept", ContentTypeEventStream)
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, errors.WithStack(UnexpectedStatusErr{resp.StatusCode})
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != ContentTypeEventStream {
+		resp.Body.Close()
+		return nil, errors.WithStack(UnexpectedContentTypeErr{resp.Header.Get("Content-Type")})
+	}
+	return resp.Body, nil
+}
+
+// send sends a request of the single connection mode, expecting status.
+func (c *SSEClient) send(ctx context.Context, method, endpoint string, body io.Reader, token string, status int) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for k, v := range newRequestOptions(c.Options).header {
+		req.Header[k] = v
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", ContentTypeJSON)
+	}
+	if token != "" {
+		req.Header.Set(sseTokenHeader, token)
+	}
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if resp.StatusCode != status {
+		return nil, errors.WithStack(UnexpectedStatusErr{resp.StatusCode})
+	}
+	return b, nil
+}
+
+// sseStream is the stream shared by the subscriptions of the single connection mode.
+// It is closed when its last operation ends, or by SSEClient.Close.
+type sseStream struct {
+	token      string
+	cancel     context.CancelFunc // Cancels the GET request of the stream.
+	mu         sync.Mutex
+	operations map[string]*sseOperation
+	closing    bool // Whether the stream is being closed, so that no operation joins it anymore.
+}
+
+// sseOperation is a subscription of the single connection mode.
+type sseOperation struct {
+	ctx        context.Context
+	mu         sync.Mutex
+	payloads   chan SubscriptionPayload
+	overflow   OverflowPolicy
+	overflowed bool // Whether the operation ended because its consumer was too slow.
+	closed     bool
+	done       chan struct{} // closed with payloads
+}
+
+// deliver sends payload to the subscriber following policy unless the subscription is over, see deliverPayload.
+func (op *sseOperation) deliver(payload SubscriptionPayload, policy OverflowPolicy) error {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	if op.closed {
+		return nil
+	}
+	return deliverPayload(op.ctx, op.payloads, payload, policy)
+}
+
+func (op *sseOperation) close() {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	if !op.closed {
+		op.closed = true
+		close(op.payloads)
+		close(op.done)
+	}
+}
+
+func (c *SSEClient) subscribeSingle(ctx context.Context, q *Query) (<-chan SubscriptionPayload, error) {
+	strCh, err := q.StringChan()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	op := &sseOperation{ctx: ctx, payloads: newPayloads(c.Buffer, c.Overflow), overflow: c.Overflow, done: make(chan struct{})}
+	stream, id, err := c.join(op)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	body, err := json.Marshal(struct {
+		Query         string                 `json:"query"`
+		OperationName string                 `json:"operationName,omitempty"`
+		Variables     map[string]interface{} `json:"variables,omitempty"`
+		Extensions    map[string]string      `json:"extensions"`
+	}{StringFromChan(strCh), q.Name, q.variableValues(), map[string]string{"operationId": id}})
+	if err == nil {
+		_, err = c.send(ctx, http.MethodPost, c.Endpoint, strings.NewReader(string(body)), stream.token, http.StatusAccepted)
+	}
+	if err != nil {
+		stream.remove(id)
+		return nil, errors.WithStack(err)
+	}
+
+	go func() {
+		stop := false
+		select {
+		case <-ctx.Done():
+			stop = stream.remove(id)
+		case <-op.done:
+			op.mu.Lock()
+			stop = op.overflowed
+			op.mu.Unlock()
+		}
+		if stop {
+			// stop the operation on the server, whatever ctx
+			c.send(context.Background(), http.MethodDelete, c.Endpoint+"?operationId="+url.QueryEscape(id), nil, stream.token, http.StatusOK)
+		}
+	}()
+	return op.payloads, nil
+}
+
+// Close closes the stream of the single connection mode, ending its running subscriptions.
+// The next subscription reserves a new stream.
+func (c *SSEClient) Close() error {
+	c.mu.Lock()
+	stream := c.stream
+	c.stream = nil
+	c.mu.Unlock()
+	if stream != nil {
+		stream.close()
+	}
+	return nil
+}
+
+// join adds op to the stream of the single connection mode, reserving and opening a stream if there is none,
+// and returns the stream and the id of op.
+func (c *SSEClient) join(op *sseOperation) (*sseStream, string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nextID++
+	id := strconv.Itoa(c.nextID)
+	if c.stream != nil && c.stream.add(id, op) {
+		return c.stream, id, nil
+	}
+
+	token, err := c.send(context.Background(), http.MethodPut, c.Endpoint, nil, "", http.StatusCreated)
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.Endpoint, nil)
+	if err != nil {
+		cancel()
+		return nil, "", errors.WithStack(err)
+	}
+	for k, v := range newRequestOptions(c.Options).header {
+		req.Header[k] = v
+	}
+	req.Header.Set(sseTokenHeader, string(token))
+	body, err := c.openStream(req)
+	if err != nil {
+		cancel()
+		return nil, "", errors.WithStack(err)
+	}
+
+	stream := &sseStream{
+		token:      string(token),
+		cancel:     cancel,
+		operations: map[string]*sseOperation{id: op},
+	}
+	go stream.dispatch(body)
+	c.stream = stream
+	return stream, id, nil
+}
+
+// add adds the operation id to the stream, reporting false if the stream is closing.
+func (s *sseStream) add(id string, op *sseOperation) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closing {
+		return false
+	}
+	s.operations[id] = op
+	return true
+}
+
+// remove removes and closes the operation id, reporting whether it was running.
+// The stream is closed once it has no operation left.
+func (s *sseStream) remove(id string) bool {
+	s.mu.Lock()
+	op, ok := s.operations[id]
+	delete(s.operations, id)
+	last := len(s.operations) == 0
+	s.mu.Unlock()
+	if ok {
+		op.close()
+	}
+	if last {
+		s.close()
+	}
+	return ok
+}
+
+// close cancels the stream. Its dispatch ends its operations.
+func (s *sseStream) close() {
+	s.mu.Lock()
+	s.closing = true
+	s.mu.Unlock()
+	s.cancel()
+}
+
+// dispatch delivers the events of the stream to the operations they belong to, until the stream ends.
+func (s *sseStream) dispatch(body io.ReadCloser) {
+	defer body.Close()
+	err := readEvents(body, func(e sseEvent) bool {
+		var message struct {
+			ID      string              `json:"id"`
+			Payload SubscriptionPayload `json:"payload"`
+		}
+		if e.event != "next" && e.event != "complete" {
+			return true
+		}
+		if err := json.Unmarshal([]byte(e.data), &message); err != nil {
+			return true // a malformed event can not be attributed to any operation
+		}
+		if e.event == "complete" {
+			s.remove(message.ID)
+			return true
+		}
+		s.mu.Lock()
+		op := s.operations[message.ID]
+		s.mu.Unlock()
+		if op == nil {
+			return true
+		}
+		if err := op.deliver(message.Payload, op.overflow); err != nil && op.ctx.Err() == nil {
+			op.deliver(SubscriptionPayload{Err: err}, OverflowBlock)
+			op.mu.Lock()
+			op.overflowed = true
+			op.mu.Unlock()
+			s.remove(message.ID)
+		}
+		return true
+	})
+	if err == nil {
+		err = io.ErrUnexpectedEOF
+	}
+
+	s.mu.Lock()
+	s.closing = true
+	operations := s.operations
+	s.operations = make(map[string]*sseOperation)
+	s.mu.Unlock()
+	s.cancel()
+	for _, op := range operations {
+		op.deliver(SubscriptionPayload{Err: errors.WithStack(err)}, OverflowBlock)
+		op.close()
+	}
+}
+
+// sseEvent is an event of an event stream.
+type sseEvent struct {
+	event string // "message" if the event has no type.
+	data  string
+	id    string
+}
+
+// readEvents reads the events of an event stream and calls fn with each of them, until fn returns false or the stream ends.
+// It follows https://html.spec.whatwg.org/multipage/server-sent-events.html#event-stream-interpretation
+func readEvents(r io.Reader, fn func(sseEvent) bool) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	var e sseEvent
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if data != nil {
+				e.data = strings.Join(data, "\n")
+				if e.event == "" {
+					e.event = "message"
+				}
+				if !fn(e) {
+					return nil
+				}
+			}
+			e, data = sseEvent{id: e.id}, nil
+			continue
+		}
+		field, value := line, ""
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+		switch field {
+		case "event":
+			e.event = value
+		case "data":
+			data = append(data, value)
+		case "id":
+			e.id = value
+		}
+	}
+	return errors.WithStack(scanner.Err())
+}
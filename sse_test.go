@@ -0,0 +1,221 @@
+package graphb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func collectPayloads(payloads <-chan SubscriptionPayload) []SubscriptionPayload {
+	var all []SubscriptionPayload
+	for p := range payloads {
+		all = append(all, p)
+	}
+	return all
+}
+
+func TestReadEvents(t *testing.T) {
+	var events []sseEvent
+	err := readEvents(strings.NewReader(": comment\nevent: next\nid: 1\ndata: {\"a\":\ndata:1}\n\ndata: x\n\nevent: complete\ndata:\n\n"), func(e sseEvent) bool {
+		events = append(events, e)
+		return true
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []sseEvent{{"next", "{\"a\":\n1}", "1"}, {"message", "x", "1"}, {"complete", "", "1"}}, events)
+}
+
+func TestSSEClient_distinctConnections(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, ContentTypeEventStream, r.Header.Get("Accept"))
+		assert.Equal(t, "Bearer t", r.Header.Get("Authorization"))
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, `{"query":"subscription{likes}"}`, string(body))
+		w.Header().Set("Content-Type", ContentTypeEventStream)
+		fmt.Fprint(w, "event: next\ndata: {\"data\":{\"likes\":1}}\n\n")
+		fmt.Fprint(w, "event: next\ndata: {\"data\":{\"likes\":2}}\n\n")
+		fmt.Fprint(w, "event: complete\ndata:\n\n")
+	}))
+	defer server.Close()
+
+	c := &SSEClient{Endpoint: server.URL, Options: []RequestOption{WithRequestHeader("Authorization", "Bearer t")}}
+	payloads, err := c.Subscribe(context.Background(), MakeQuery(TypeSubscription).SetFields(MakeField("likes")))
+	assert.Nil(t, err)
+	assert.Equal(t, []SubscriptionPayload{
+		{Data: json.RawMessage(`{"likes":1}`)},
+		{Data: json.RawMessage(`{"likes":2}`)},
+	}, collectPayloads(payloads))
+}
+
+func TestSSEClient_errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json":
+			w.Header().Set("Content-Type", ContentTypeJSON)
+		case "/cut":
+			w.Header().Set("Content-Type", ContentTypeEventStream)
+			fmt.Fprint(w, "event: next\ndata: {\"data\":{\"likes\":1}}\n\n")
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+	q := MakeQuery(TypeSubscription).SetFields(MakeField("likes"))
+
+	_, err := (&SSEClient{Endpoint: server.URL}).Subscribe(context.Background(), q)
+	assert.Equal(t, UnexpectedStatusErr{http.StatusBadRequest}, errors.Cause(err))
+	_, err = (&SSEClient{Endpoint: server.URL + "/json"}).Subscribe(context.Background(), q)
+	assert.Equal(t, UnexpectedContentTypeErr{ContentTypeJSON}, errors.Cause(err))
+
+	payloads, err := (&SSEClient{Endpoint: server.URL + "/cut"}).Subscribe(context.Background(), q)
+	assert.Nil(t, err)
+	all := collectPayloads(payloads)
+	assert.Len(t, all, 2)
+	assert.Equal(t, io.ErrUnexpectedEOF, errors.Cause(all[1].Err))
+}
+
+func TestSSEClient_resume(t *testing.T) {
+	var lastEventIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastEventIDs = append(lastEventIDs, r.Header.Get("Last-Event-ID"))
+		w.Header().Set("Content-Type", ContentTypeEventStream)
+		switch r.Header.Get("Last-Event-ID") {
+		case "":
+			fmt.Fprint(w, "event: next\nid: 1\ndata: {\"data\":{\"likes\":1}}\n\n")
+			fmt.Fprint(w, "event: next\nid: 2\ndata: {\"data\":{\"likes\":2}}\n\n")
+		case "2":
+			fmt.Fprint(w, "event: next\nid: 3\ndata: {\"data\":{\"likes\":3}}\n\n")
+		case "3":
+			fmt.Fprint(w, "event: complete\ndata:\n\n")
+		}
+	}))
+	defer server.Close()
+	q := MakeQuery(TypeSubscription).SetFields(MakeField("likes"))
+	key, err := subscriptionKey(q)
+	assert.Nil(t, err)
+
+	store := NewMemoryResumeTokenStore()
+	c := &SSEClient{Endpoint: server.URL, Resume: store, MaxReconnects: 1}
+	payloads, err := c.Subscribe(context.Background(), q)
+	assert.Nil(t, err)
+	all := collectPayloads(payloads)
+	assert.Len(t, all, 4)
+	assert.Equal(t, json.RawMessage(`{"likes":3}`), all[2].Data)
+	assert.Equal(t, io.ErrUnexpectedEOF, errors.Cause(all[3].Err), "reconnected once only")
+	assert.Equal(t, []string{"", "2"}, lastEventIDs)
+
+	// a new subscription resumes from the store, which forgets completed subscriptions
+	token, _ := store.Token(key)
+	assert.Equal(t, "3", token)
+	payloads, err = c.Subscribe(context.Background(), q)
+	assert.Nil(t, err)
+	assert.Empty(t, collectPayloads(payloads))
+	assert.Equal(t, []string{"", "2", "3"}, lastEventIDs)
+	token, _ = store.Token(key)
+	assert.Equal(t, "", token)
+}
+
+func TestSSEClient_overflow(t *testing.T) {
+	next, dropped := make(chan struct{}), make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentTypeEventStream)
+		fmt.Fprint(w, "event: next\ndata: {\"data\":{\"likes\":1}}\n\n")
+		w.(http.Flusher).Flush()
+		<-next
+		fmt.Fprint(w, "event: next\ndata: {\"data\":{\"likes\":2}}\n\n")
+		w.(http.Flusher).Flush()
+		// the client drops the stream instead of buffering the second result
+		<-r.Context().Done()
+		close(dropped)
+	}))
+	defer server.Close()
+
+	c := &SSEClient{Endpoint: server.URL, Buffer: 1, Overflow: OverflowError}
+	payloads, err := c.Subscribe(context.Background(), MakeQuery(TypeSubscription).SetFields(MakeField("likes")))
+	assert.Nil(t, err)
+	for len(payloads) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	close(next)
+	<-dropped
+	all := collectPayloads(payloads)
+	assert.Len(t, all, 2)
+	assert.Equal(t, json.RawMessage(`{"likes":1}`), all[0].Data)
+	assert.Equal(t, SubscriptionOverflowErr{1}, errors.Cause(all[1].Err))
+}
+
+// singleConnectionServer implements the single connection mode of GraphQL over SSE for one reservation.
+type singleConnectionServer struct {
+	events  chan string
+	deleted chan string
+}
+
+func (s *singleConnectionServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPut:
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, "token")
+	case http.MethodGet:
+		if r.Header.Get(sseTokenHeader) != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", ContentTypeEventStream)
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case e := <-s.events:
+				fmt.Fprint(w, e)
+				w.(http.Flusher).Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	case http.MethodPost:
+		var body struct {
+			Query      string            `json:"query"`
+			Extensions map[string]string `json:"extensions"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		id := body.Extensions["operationId"]
+		w.WriteHeader(http.StatusAccepted)
+		go func() {
+			if body.Query == "subscription{likes}" {
+				s.events <- fmt.Sprintf("event: next\ndata: {\"id\":%q,\"payload\":{\"data\":{\"likes\":1}}}\n\n", id)
+				s.events <- fmt.Sprintf("event: complete\ndata: {\"id\":%q}\n\n", id)
+			}
+		}()
+	case http.MethodDelete:
+		w.WriteHeader(http.StatusOK)
+		s.deleted <- r.URL.Query().Get("operationId")
+	}
+}
+
+func TestSSEClient_singleConnection(t *testing.T) {
+	s := &singleConnectionServer{events: make(chan string), deleted: make(chan string, 1)}
+	server := httptest.NewServer(s)
+	defer server.Close()
+	c := &SSEClient{Endpoint: server.URL, SingleConnection: true}
+	defer c.Close()
+
+	payloads, err := c.Subscribe(context.Background(), MakeQuery(TypeSubscription).SetFields(MakeField("likes")))
+	assert.Nil(t, err)
+	assert.Equal(t, []SubscriptionPayload{{Data: json.RawMessage(`{"likes":1}`)}}, collectPayloads(payloads))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	payloads, err = c.Subscribe(ctx, MakeQuery(TypeSubscription).SetFields(MakeField("comments")))
+	assert.Nil(t, err)
+	cancel()
+	assert.Equal(t, "2", <-s.deleted)
+	assert.Empty(t, collectPayloads(payloads))
+}
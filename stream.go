@@ -0,0 +1,237 @@
+package graphb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// StreamedValue is the value of a variable written into the body of a request while it is sent by Query.NewRequest,
+// so that a very large value, e.g. a base64 encoded file, never resides in memory.
+type StreamedValue interface {
+	WriteJSON(w io.Writer) error // Writes the JSON encoded value.
+}
+
+// Base64Value returns a StreamedValue writing the content of r as a base64 encoded string.
+// r is read once, when the request is sent. Encoded by encoding/json, e.g. by Query.JSON, it is read into memory.
+func Base64Value(r io.Reader) StreamedValue {
+	return base64Value{r}
+}
+
+type base64Value struct {
+	r io.Reader
+}
+
+func (v base64Value) WriteJSON(w io.Writer) error {
+	// base64 needs no escaping in a JSON string
+	if _, err := io.WriteString(w, `"`); err != nil {
+		return errors.WithStack(err)
+	}
+	enc := base64.NewEncoder(base64.StdEncoding, w)
+	if _, err := io.Copy(enc, v.r); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := enc.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+	_, err := io.WriteString(w, `"`)
+	return errors.WithStack(err)
+}
+
+func (v base64Value) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := v.WriteJSON(&buf); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Upload is the value of a variable of the Upload scalar, a file sent with the GraphQL multipart request protocol,
+// see https://github.com/jaydenseric/graphql-multipart-request-spec.
+// A Query whose variables are Upload(s), or lists of them, is sent by Query.NewRequest as multipart/form-data,
+// the content of the files being streamed. Other transports encode an Upload as null.
+type Upload struct {
+	Filename    string
+	ContentType string // application/octet-stream if empty.
+	Content     io.Reader
+}
+
+// MarshalJSON encodes an Upload as null, its placeholder in the operations of a multipart request.
+func (Upload) MarshalJSON() ([]byte, error) {
+	return []byte("null"), nil
+}
+
+// upload is an Upload at a path of the operations of a multipart request, e.g. "variables.files.0".
+type upload struct {
+	path string
+	Upload
+}
+
+// uploads returns the Upload(s) of the variables of this Query, in the order of its variables.
+func (q *Query) uploads() []upload {
+	var uploads []upload
+	for _, v := range q.Variables {
+		path := "variables." + v.Name
+		switch value := v.Value.(type) {
+		case Upload:
+			uploads = append(uploads, upload{path, value})
+		case *Upload:
+			if value != nil {
+				uploads = append(uploads, upload{path, *value})
+			}
+		case []Upload:
+			for i, u := range value {
+				uploads = append(uploads, upload{path + "." + strconv.Itoa(i), u})
+			}
+		case []*Upload:
+			for i, u := range value {
+				if u != nil {
+					uploads = append(uploads, upload{path + "." + strconv.Itoa(i), *u})
+				}
+			}
+		}
+	}
+	return uploads
+}
+
+// streamsBody reports whether the body of a request sending this Query is streamed, see StreamedValue and Upload.
+func (q *Query) streamsBody() bool {
+	for _, v := range q.Variables {
+		switch v.Value.(type) {
+		case StreamedValue, Upload, *Upload, []Upload, []*Upload:
+			return true
+		}
+	}
+	return false
+}
+
+// streamedBody returns the body of a POST request sending this Query serialized as query, and its content type.
+// The body is written by a goroutine while it is read, and compressed if gzipped.
+func (q *Query) streamedBody(query string, gzipped bool) (io.ReadCloser, string) {
+	pr, pw := io.Pipe()
+	var w io.Writer = pw
+	var gw *gzip.Writer
+	if gzipped {
+		gw = gzip.NewWriter(pw)
+		w = gw
+	}
+	uploads := q.uploads()
+	contentType := ContentTypeJSON
+	var mw *multipart.Writer
+	if len(uploads) > 0 {
+		mw = multipart.NewWriter(w)
+		contentType = mw.FormDataContentType()
+	}
+	go func() {
+		var err error
+		if mw != nil {
+			err = q.writeMultipartBody(mw, query, uploads)
+		} else {
+			err = q.writeJSONBody(w, query)
+		}
+		if err == nil && gw != nil {
+			err = gw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr, contentType
+}
+
+// writeJSONBody writes the same as Query.JSON to w, with the StreamedValue(s) of its variables written in place.
+func (q *Query) writeJSONBody(w io.Writer, query string) error {
+	extensions, err := q.extensionsMember()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	values := q.variableValues()
+	if len(values) == 0 {
+		_, err := fmt.Fprintf(w, `{"query":%s%s}`, jsonString(query), extensions)
+		return errors.WithStack(err)
+	}
+	if _, err := fmt.Fprintf(w, `{"query":%s,"variables":{`, jsonString(query)); err != nil {
+		return errors.WithStack(err)
+	}
+	// sorted, as encoding/json sorts the keys of maps
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		if i != 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		if _, err := io.WriteString(w, jsonString(name)+":"); err != nil {
+			return errors.WithStack(err)
+		}
+		if v, ok := values[name].(StreamedValue); ok {
+			if err := v.WriteJSON(w); err != nil {
+				return errors.WithStack(err)
+			}
+			continue
+		}
+		b, err := json.Marshal(values[name])
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if _, err := w.Write(b); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	_, err = io.WriteString(w, "}"+extensions+"}")
+	return errors.WithStack(err)
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// writeMultipartBody writes the parts of a multipart request: the operations, the map of the files to their paths,
+// then the files named by their index.
+func (q *Query) writeMultipartBody(mw *multipart.Writer, query string, uploads []upload) error {
+	operations, err := mw.CreateFormField("operations")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := q.writeJSONBody(operations, query); err != nil {
+		return errors.WithStack(err)
+	}
+	paths := make(map[string][]string, len(uploads))
+	for i, u := range uploads {
+		paths[strconv.Itoa(i)] = []string{u.path}
+	}
+	b, err := json.Marshal(paths)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := mw.WriteField("map", string(b)); err != nil {
+		return errors.WithStack(err)
+	}
+	for i, u := range uploads {
+		contentType := u.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%d"; filename="%s"`, i, quoteEscaper.Replace(u.Filename)))
+		h.Set("Content-Type", contentType)
+		part, err := mw.CreatePart(h)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if _, err := io.Copy(part, u.Content); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return errors.WithStack(mw.Close())
+}
@@ -0,0 +1,91 @@
+package graphb
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func uploadQuery(variables ...Variable) *Query {
+	q := MakeQuery(TypeMutation).AddVariables(variables...)
+	args := make([]Argument, len(variables))
+	for i, v := range variables {
+		args[i] = ArgumentVariable(v.Name, v.Name)
+	}
+	return q.SetFields(MakeField("upload").SetArguments(args...))
+}
+
+func TestBase64Value(t *testing.T) {
+	q := uploadQuery(Variable{Name: "n", Type: "Int", Value: 1}, Variable{Name: "blob", Type: "String!", Value: Base64Value(strings.NewReader("hello"))})
+	expected := `{"query":"mutation($n:Int,$blob:String!){upload(n:$n,blob:$blob)}","variables":{"blob":"aGVsbG8=","n":1}}`
+
+	req, err := q.NewRequest(context.Background(), http.MethodPost, "https://example.com/graphql")
+	assert.Nil(t, err)
+	assert.Equal(t, ContentTypeJSON, req.Header.Get("Content-Type"))
+	assert.Equal(t, int64(0), req.ContentLength)
+	body, err := io.ReadAll(req.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, string(body))
+
+	// encoding/json reads it into memory
+	q = uploadQuery(Variable{Name: "n", Type: "Int", Value: 1}, Variable{Name: "blob", Type: "String!", Value: Base64Value(strings.NewReader("hello"))})
+	s, err := q.JSON()
+	assert.Nil(t, err)
+	assert.Equal(t, expected, s)
+
+	q = uploadQuery(Variable{Name: "blob", Type: "String!", Value: Base64Value(strings.NewReader("hello"))})
+	req, err = q.NewRequest(context.Background(), http.MethodPost, "https://example.com/graphql", WithGzipBody())
+	assert.Nil(t, err)
+	assert.Equal(t, "gzip", req.Header.Get("Content-Encoding"))
+	r, err := gzip.NewReader(req.Body)
+	assert.Nil(t, err)
+	body, err = io.ReadAll(r)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"query":"mutation($blob:String!){upload(blob:$blob)}","variables":{"blob":"aGVsbG8="}}`, string(body))
+
+	// a failing reader fails the body
+	failure := errors.New("disk failure")
+	q = uploadQuery(Variable{Name: "blob", Type: "String!", Value: Base64Value(iotest.ErrReader(failure))})
+	req, err = q.NewRequest(context.Background(), http.MethodPost, "https://example.com/graphql")
+	assert.Nil(t, err)
+	_, err = io.ReadAll(req.Body)
+	assert.Equal(t, failure, errors.Cause(err))
+}
+
+func TestUpload(t *testing.T) {
+	q := uploadQuery(
+		Variable{Name: "file", Type: "Upload!", Value: Upload{Filename: "a.txt", ContentType: "text/plain", Content: strings.NewReader("a")}},
+		Variable{Name: "files", Type: "[Upload!]!", Value: []*Upload{{Filename: `"b".bin`, Content: strings.NewReader("b")}}},
+	)
+	req, err := q.NewRequest(context.Background(), http.MethodPost, "https://example.com/graphql")
+	assert.Nil(t, err)
+	mediaType, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	assert.Nil(t, err)
+	assert.Equal(t, "multipart/form-data", mediaType)
+
+	form, err := multipart.NewReader(req.Body, params["boundary"]).ReadForm(1 << 20)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{`{"query":"mutation($file:Upload!,$files:[Upload!]!){upload(file:$file,files:$files)}","variables":{"file":null,"files":[null]}}`}, form.Value["operations"])
+	var paths map[string][]string
+	assert.Nil(t, json.Unmarshal([]byte(form.Value["map"][0]), &paths))
+	assert.Equal(t, map[string][]string{"0": {"variables.file"}, "1": {"variables.files.0"}}, paths)
+
+	files := map[string]string{"0": "a.txt:text/plain:a", "1": `"b".bin:application/octet-stream:b`}
+	for name, expected := range files {
+		fh := form.File[name][0]
+		f, err := fh.Open()
+		assert.Nil(t, err)
+		content, _ := io.ReadAll(f)
+		assert.Equal(t, expected, fh.Filename+":"+fh.Header.Get("Content-Type")+":"+string(content))
+	}
+}
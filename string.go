@@ -0,0 +1,209 @@
+package graphb
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// serialization is the string cached by Query.String.
+type serialization struct {
+	query *Query // The Query serialized, so that a copy of it does not use the cache.
+	at    uint64 // The last generation of a change to a Field when serialized.
+	key   queryKey
+	s     string
+}
+
+// queryKey is what the serialization of a Query depends on besides its fields,
+// so that the struct fields of the Query changed without its methods, e.g. Name or Config, are seen by String.
+type queryKey struct {
+	configured bool // Whether the Query has a Config.
+	config     Config
+	fragments  bool   // See Query.FragmentDefinitions.
+	header     string // The tokens around the fields, their type, name, variables, directives and operation options.
+	footer     string
+}
+
+// key returns the queryKey of this Query.
+func (q *Query) key() queryKey {
+	k := queryKey{
+		configured: q.Config != nil,
+		fragments:  q.FragmentDefinitions,
+		header:     collect(q.emitHeader),
+		footer:     collect(q.emitFooter),
+	}
+	if q.Config != nil {
+		k.config = *q.Config
+	}
+	return k
+}
+
+// segment is the cached serialization of a Field, spliced into the serialization of the queries selecting it.
+type segment struct {
+	field      *Field // The Field serialized, so that a copy of it does not use the cache.
+	generation uint64 // The generation of the Field when serialized.
+	configured bool   // Whether the Field was serialized with config.
+	config     Config
+	s          string
+}
+
+// serializationMu guards the caches of all queries and fields, held only to read or write a cache.
+var serializationMu sync.Mutex
+
+// generations counts the changes made to fields through their setters, see Field.touch.
+var generations uint64
+
+// touch records a change of this Field, so that the queries selecting it serialize it again.
+func (f *Field) touch() {
+	f.generation = atomic.AddUint64(&generations, 1)
+}
+
+// String returns the serialization of this Query, the strings of StringChan concatenated, and caches it:
+// String returns the cached string until this Query or a Field of its tree is changed, so that a query logged then sent is serialized once.
+// Serialized with StyleCompact, the fields left unchanged since the previous call are not serialized again
+// but spliced, so that a pagination loop changing one argument only serializes the field of the argument and its parents.
+// The changes of the Query itself are always seen, e.g. setting its Name, Config, Variables or Directives.
+// The changes of its Fields are seen when made through their methods, e.g. Field.SetArguments,
+// those made otherwise, to the struct fields of a Field, are not seen until Invalidate is called.
+// A copy of this Query or of a Field does not share the cache. An error is not cached.
+func (q *Query) String() (string, error) {
+	key := q.key()
+	serializationMu.Lock()
+	cached := q.serialized
+	serializationMu.Unlock()
+	if cached != nil && cached.query == q && cached.key == key && !changedSince(q.Fields, cached.at, make(map[*Field]bool)) {
+		return cached.s, nil
+	}
+
+	at := atomic.LoadUint64(&generations)
+	if err := q.checkAll(); err != nil {
+		return "", errors.WithStack(err)
+	}
+	var s string
+	if (q.Config == nil || q.Config.Style == StyleCompact) && !q.FragmentDefinitions {
+		s = q.splicedString()
+	} else {
+		// the white space of a style depends on the tokens preceding each field
+		s = StringFromChan(q.stringChan())
+	}
+	serializationMu.Lock()
+	q.serialized = &serialization{q, at, key, s}
+	serializationMu.Unlock()
+	return s, nil
+}
+
+// Invalidate drops the strings cached by String for this Query and the Fields of its tree,
+// to be called after changing them other than through their methods, e.g. after setting an element of Field.Arguments.
+func (q *Query) Invalidate() *Query {
+	touchAll(q.Fields, make(map[*Field]bool))
+	return q.invalidate()
+}
+
+// invalidate drops the string cached by String, after a change of this Query itself.
+func (q *Query) invalidate() *Query {
+	serializationMu.Lock()
+	q.serialized = nil
+	serializationMu.Unlock()
+	return q
+}
+
+// touchAll touches fs and their sub fields, each once even if the tree contains cycles.
+func touchAll(fs []*Field, visited map[*Field]bool) {
+	for _, f := range fs {
+		if f != nil && !visited[f] {
+			visited[f] = true
+			f.touch()
+			touchAll(f.Fields, visited)
+		}
+	}
+}
+
+// changedSince reports whether fs or their sub fields were changed after the generation at.
+func changedSince(fs []*Field, at uint64, visited map[*Field]bool) bool {
+	for _, f := range fs {
+		if f == nil || visited[f] {
+			continue
+		}
+		visited[f] = true
+		if f.generation > at || changedSince(f.Fields, at, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// splicedString serializes this valid Query like StringParallel, splicing the cached segments of its unchanged fields.
+// The Config of this Query must be nil or of StyleCompact.
+func (q *Query) splicedString() string {
+	parts := make([]string, 0, len(q.Fields))
+	for _, f := range q.Fields {
+		if f != nil {
+			s, _ := f.spliced(q.Config)
+			parts = append(parts, s)
+		}
+	}
+	return collect(q.emitHeader) + strings.Join(parts, tokenComma) + collect(q.emitFooter)
+}
+
+// spliced returns the compact serialization of this valid Field configured by c, which may be nil,
+// splicing the cached segments of the unchanged fields of its tree, and whether its own segment was spliced.
+// Nil fields are left out, as c.SkipNilFields does, validation rejecting them otherwise.
+func (f *Field) spliced(c *Config) (string, bool) {
+	// frozen without a Config, see Config.configure
+	frozen := c == nil && f.compiled != nil
+	var parts []string
+	unchanged := true
+	if !frozen {
+		hasTypename := false
+		for _, subF := range f.Fields {
+			if subF == nil {
+				continue
+			}
+			hasTypename = hasTypename || (subF.Name == "__typename" && subF.Alias == "")
+			s, ok := subF.spliced(c)
+			parts = append(parts, s)
+			unchanged = unchanged && ok
+		}
+		if c != nil && c.TypenameInjection && f.Fields != nil && !hasTypename {
+			parts = append(parts, "__typename")
+		}
+	}
+
+	serializationMu.Lock()
+	cached := f.segment
+	serializationMu.Unlock()
+	if unchanged && cached != nil && cached.field == f && cached.generation == f.generation &&
+		cached.configured == (c != nil) && (c == nil || cached.config == *c) {
+		return cached.s, true
+	}
+
+	var s string
+	if frozen {
+		s = collect(func(tokenChan chan<- Token) {
+			for _, tok := range f.compiled {
+				tokenChan <- tok
+			}
+		})
+	} else {
+		args := f.Arguments
+		if c != nil {
+			args = c.configureArguments(args)
+		}
+		s = collect(func(tokenChan chan<- Token) {
+			f.emitHead(tokenChan, args)
+		})
+		if len(parts) > 0 {
+			s += tokenLB + strings.Join(parts, tokenComma) + tokenRB
+		}
+	}
+	seg := &segment{field: f, generation: f.generation, configured: c != nil, s: s}
+	if c != nil {
+		seg.config = *c
+	}
+	serializationMu.Lock()
+	f.segment = seg
+	serializationMu.Unlock()
+	return s, false
+}
@@ -0,0 +1,204 @@
+package graphb
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery_String(t *testing.T) {
+	q := MakeQuery(TypeQuery).SetFields(MakeField("a"))
+	s, err := q.String()
+	assert.Nil(t, err)
+	assert.Equal(t, "query{a}", s)
+	assert.NotNil(t, q.serialized)
+
+	// changed through its methods
+	for _, c := range []struct {
+		change   func()
+		expected string
+	}{
+		{func() { q.SetName("A") }, "query A{a}"},
+		{func() { q.AddFields(MakeField("b")) }, "query A{a,b}"},
+		{func() { q.SortFields(func(a, b *Field) bool { return a.Name > b.Name }) }, "query A{b,a}"},
+		{func() { q.AddDirectives(MakeDirective("live")) }, "query A@live{b,a}"},
+		{func() { q.SetFields(MakeField("c").SetArguments(ArgumentInt("v", 1))) }, "query A@live{c(v:1)}"},
+		{func() {
+			q.Fields[0].SetArguments(ArgumentVariable("v", "v"))
+			q.AddVariables(Variable{Name: "v", Type: "Int"})
+		}, "query A($v:Int)@live{c(v:$v)}"},
+	} {
+		c.change()
+		s, err := q.String()
+		assert.Nil(t, err)
+		assert.Equal(t, c.expected, s)
+	}
+
+	// struct fields of the Query changed otherwise
+	for _, c := range []struct {
+		change   func()
+		expected string
+	}{
+		{func() { q.Name = "B" }, "query B($v:Int)@live{c(v:$v)}"},
+		{func() { q.Variables[0].Type = "Int!" }, "query B($v:Int!)@live{c(v:$v)}"},
+		{func() { q.Directives = nil }, "query B($v:Int!){c(v:$v)}"},
+		{func() { q.Config = NewConfig(WithTypenameInjection()) }, "query B($v:Int!){c(v:$v)}"},
+		{func() { q.Config.Style = StylePretty }, "query B($v: Int!) {\n  c(v: $v)\n}"},
+		{func() { q.Config, q.Name, q.Directives = nil, "A", []Directive{MakeDirective("live")} }, "query A($v:Int!)@live{c(v:$v)}"},
+	} {
+		c.change()
+		s, err := q.String()
+		assert.Nil(t, err)
+		assert.Equal(t, c.expected, s)
+	}
+
+	// changed otherwise, seen after Invalidate
+	q.Fields[0].Alias = "d"
+	s, err = q.String()
+	assert.Nil(t, err)
+	assert.Equal(t, "query A($v:Int!)@live{c(v:$v)}", s)
+	s, err = q.Invalidate().String()
+	assert.Nil(t, err)
+	assert.Equal(t, "query A($v:Int!)@live{d:c(v:$v)}", s)
+
+	// a copy does not use the cache
+	copied := *q
+	copied.Name = "B"
+	s, err = copied.String()
+	assert.Nil(t, err)
+	assert.Equal(t, "query B($v:Int!)@live{d:c(v:$v)}", s)
+	s, err = q.String()
+	assert.Nil(t, err)
+	assert.Equal(t, "query A($v:Int!)@live{d:c(v:$v)}", s)
+
+	// errors are not cached
+	q = MakeQuery(TypeQuery).SetFields(MakeField("bad name"))
+	_, err = q.String()
+	assert.IsType(t, InvalidNameErr{}, errors.Cause(err))
+	assert.Nil(t, q.serialized)
+	q.Fields[0].Name = "good"
+	s, err = q.String()
+	assert.Nil(t, err)
+	assert.Equal(t, "query{good}", s)
+}
+
+func TestQuery_String_Concurrent(t *testing.T) {
+	q := MakeQuery(TypeQuery).SetFields(MakeField("a").SetFields(MakeField("b")))
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s, err := q.String()
+			assert.Nil(t, err)
+			assert.Equal(t, "query{a{b}}", s)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestQuery_String_Spliced(t *testing.T) {
+	page := MakeField("posts").SetArguments(ArgumentInt("first", 10)).SetFields(MakeField("id"), MakeField("title"))
+	profile := MakeField("profile").SetFields(MakeField("name"))
+	q := MakeQuery(TypeQuery).SetFields(MakeField("user").SetFields(profile, page), MakeField("viewer").SetFields(MakeField("id")))
+	s, err := q.String()
+	assert.Nil(t, err)
+	assert.Equal(t, "query{user{profile{name},posts(first:10){id,title}},viewer{id}}", s)
+	profileSegment, pageSegment := profile.segment, page.segment
+
+	// a field changed through its setters is serialized again, with its parents only
+	page.ReplaceArgument(ArgumentString("after", "abc"))
+	s, err = q.String()
+	assert.Nil(t, err)
+	assert.Equal(t, `query{user{profile{name},posts(first:10,after:"abc"){id,title}},viewer{id}}`, s)
+	assert.True(t, profileSegment == profile.segment)
+	assert.False(t, pageSegment == page.segment)
+
+	// a sub field added
+	page.Fields[0].SetFields(MakeField("a"))
+	s, err = q.String()
+	assert.Nil(t, err)
+	assert.Equal(t, `query{user{profile{name},posts(first:10,after:"abc"){id{a},title}},viewer{id}}`, s)
+
+	// a field made invalid
+	profile.SetAlias("bad alias")
+	_, err = q.String()
+	assert.IsType(t, InvalidNameErr{}, errors.Cause(err))
+}
+
+func TestQuery_String_SameAsStringChan(t *testing.T) {
+	registry := NewFragmentRegistry()
+	assert.Nil(t, registry.RegisterFragment("UserCard", "User", MakeField("name")))
+	spread, err := registry.Spread("UserCard")
+	assert.Nil(t, err)
+	compiled, err := MakeField("compiled").SetArguments(ArgumentString("s", `"`)).SetFields(MakeField("a")).Compile()
+	assert.Nil(t, err)
+	shared := MakeField("shared").SetArguments(ArgumentString("s", `a"b`), ArgumentEnum("e", "inProgress")).
+		SetFields(MakeField("a").AddDirectives(MakeDirective("include", ArgumentBool("if", true))))
+	fields := func() []*Field {
+		return []*Field{
+			MakeField("user").SetAlias("u").SetFields(spread, MakeField("__typename"), MakeField("empty").SetFields()),
+			compiled,
+			shared,
+			MakeField("when").SetArguments(ArgumentTime("at", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))),
+		}
+	}
+
+	for _, c := range []*Config{
+		nil,
+		NewConfig(),
+		NewConfig(WithStrictEscaping(), WithEnumNormalization(), WithTypenameInjection(), WithDefaultTimeFormat(time.RFC1123)),
+		NewConfig(WithNilFieldsSkipped(), WithTypenameInjection()),
+		NewConfig(WithSerializerStyle(StylePretty)),
+	} {
+		for _, definitions := range []bool{false, true} {
+			q := MakeQuery(TypeQuery).SetName("Q").SetFields(fields()...)
+			q.Config = c
+			q.FragmentDefinitions = definitions
+			if c != nil && c.SkipNilFields {
+				q.AddFields(nil)
+				q.Fields[0].Fields = append(q.Fields[0].Fields, nil)
+			}
+			strCh, err := q.StringChan()
+			assert.Nil(t, err)
+			expected := StringFromChan(strCh)
+			// twice, spliced the second time
+			for i := 0; i < 2; i++ {
+				s, err := q.String()
+				assert.Nil(t, err)
+				assert.Equal(t, expected, s)
+				q.invalidate()
+			}
+		}
+	}
+}
+
+func TestQuery_Invalidate(t *testing.T) {
+	f := MakeField("a").SetArguments(ArgumentInt("n", 1))
+	q := MakeQuery(TypeQuery).SetFields(MakeField("root").SetFields(f))
+	s, err := q.String()
+	assert.Nil(t, err)
+	assert.Equal(t, "query{root{a(n:1)}}", s)
+
+	f.Arguments[0] = ArgumentInt("n", 2)
+	s, err = q.String()
+	assert.Nil(t, err)
+	assert.Equal(t, "query{root{a(n:1)}}", s)
+	s, err = q.Invalidate().String()
+	assert.Nil(t, err)
+	assert.Equal(t, "query{root{a(n:2)}}", s)
+
+	// a field shared by another query is serialized again by it too
+	other := MakeQuery(TypeQuery).SetFields(f)
+	s, err = other.String()
+	assert.Nil(t, err)
+	assert.Equal(t, "query{a(n:2)}", s)
+	f.Arguments[0] = ArgumentInt("n", 3)
+	q.Invalidate()
+	s, err = other.String()
+	assert.Nil(t, err)
+	assert.Equal(t, "query{a(n:3)}", s)
+}
@@ -0,0 +1,239 @@
+package graphb
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// ArgumentsOf returns the arguments of a field built from the exported fields of a struct, or a pointer to a struct,
+// e.g. a search filter. Each struct field is configured by a tag of the form `graphql:"name,omitempty,enum"`:
+//
+//	name       the argument name, defaults to the field name with a lower case first letter, "-" skips the field
+//	omitempty  skips the field if it holds the zero value of its type
+//	nullempty  emits null if the field holds the zero value of its type
+//	emitempty  emits the zero value of the field like any other value
+//	enum       serializes a string or a slice of strings as enum values
+//
+// Fields without any of the empty options follow zero, ZeroEmit if omitted, at any depth.
+// Nested structs and maps become input objects, nil pointers become null and other values are converted like ArgumentAny.
+func ArgumentsOf(v interface{}, zero ...ZeroBehavior) ([]Argument, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		e := argumentTypeNotSupported("", v)
+		e.Suggestion = "pass a struct or a pointer to a struct"
+		return nil, errors.WithStack(e)
+	}
+	behavior := ZeroEmit
+	if len(zero) > 0 {
+		behavior = zero[0]
+	}
+	return argumentsOfStruct(rv, behavior)
+}
+
+// structTag is a parsed `graphql` struct tag.
+type structTag struct {
+	name string
+	zero *ZeroBehavior // The ZeroBehavior of the field, if set by the tag.
+	enum bool
+}
+
+func parseStructTag(f reflect.StructField) structTag {
+	parts := strings.Split(f.Tag.Get("graphql"), ",")
+	tag := structTag{name: parts[0]}
+	if tag.name == "" {
+		r, size := utf8.DecodeRuneInString(f.Name)
+		tag.name = string(unicode.ToLower(r)) + f.Name[size:]
+	}
+	for _, option := range parts[1:] {
+		switch option {
+		case "omitempty":
+			tag.zero = zeroBehavior(ZeroOmit)
+		case "nullempty":
+			tag.zero = zeroBehavior(ZeroNull)
+		case "emitempty":
+			tag.zero = zeroBehavior(ZeroEmit)
+		case "enum":
+			tag.enum = true
+		}
+	}
+	return tag
+}
+
+func zeroBehavior(b ZeroBehavior) *ZeroBehavior {
+	return &b
+}
+
+func argumentsOfStruct(rv reflect.Value, zero ZeroBehavior) ([]Argument, error) {
+	t := rv.Type()
+	var args []Argument
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" { // unexported
+			continue
+		}
+		tag := parseStructTag(f)
+		if tag.name == "-" {
+			continue
+		}
+		behavior := zero
+		if tag.zero != nil {
+			behavior = *tag.zero
+		}
+		fv := rv.Field(i)
+		if behavior == ZeroOmit && isZero(fv) {
+			continue
+		}
+		arg, err := argumentOfValue(tag.name, fv, tag.enum, zero)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		args = append(args, withZeroBehavior(arg, isZero(fv), behavior))
+	}
+	return args, nil
+}
+
+func argumentOfValue(name string, v reflect.Value, enum bool, zero ZeroBehavior) (Argument, error) {
+	if v.CanInterface() && !(v.Kind() == reflect.Ptr && v.IsNil()) {
+		switch x := v.Interface().(type) {
+		case Valuer:
+			converted, err := x.GraphQLValue()
+			if err != nil {
+				return Argument{}, errors.WithStack(err)
+			}
+			if converted == nil {
+				return Argument{name, argTokens{{TokenNull, "null"}}}, nil
+			}
+			return argumentOfValue(name, reflect.ValueOf(converted), enum, zero)
+		case TokenProvider:
+			return ArgumentTokens(name, x)
+		}
+	}
+	if v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return Argument{name, argTokens{{TokenNull, "null"}}}, nil
+		}
+		return argumentOfValue(name, v.Elem(), enum, zero)
+	}
+
+	switch x := v.Interface().(type) {
+	case time.Time:
+		return ArgumentTime(name, x), nil
+	case json.RawMessage:
+		return ArgumentJSON(name, x)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		if enum {
+			return ArgumentEnum(name, v.String()), nil
+		}
+		return ArgumentString(name, v.String()), nil
+	case reflect.Bool:
+		return ArgumentBool(name, v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return ArgumentInt(name, int(v.Int())), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Uint() > math.MaxInt {
+			return Argument{}, errors.WithStack(UintOverflowErr{name, v.Uint()})
+		}
+		return ArgumentInt(name, int(v.Uint())), nil
+	case reflect.Float32, reflect.Float64:
+		return ArgumentFloat(name, v.Float())
+	case reflect.Struct:
+		args, err := argumentsOfStruct(v, zero)
+		if err != nil {
+			return Argument{}, errors.WithStack(err)
+		}
+		return ArgumentCustomType(name, args...), nil
+	case reflect.Slice, reflect.Array:
+		return argumentOfList(name, v, enum, zero)
+	case reflect.Map:
+		return argumentOfMap(name, v, enum, zero)
+	}
+	return Argument{}, errors.WithStack(argumentTypeNotSupported(name, v.Interface()))
+}
+
+// argumentOfList converts a list by converting its elements one by one.
+func argumentOfList(name string, v reflect.Value, enum bool, zero ZeroBehavior) (Argument, error) {
+	list := make(argList, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		elem, err := argumentOfValue(name, v.Index(i), enum, zero)
+		if err != nil {
+			return Argument{}, errors.WithStack(err)
+		}
+		list[i] = elem.Value
+	}
+	return Argument{name, list}, nil
+}
+
+// argumentOfMap converts a map into an input object whose fields are named by its keys, in the order of the names.
+// A key is named by its String method if it has one, e.g. an enum, or by itself if it is a string.
+// Other keys, e.g. integers, can not name fields.
+func argumentOfMap(name string, v reflect.Value, enum bool, zero ZeroBehavior) (Argument, error) {
+	type entry struct {
+		name  string
+		value reflect.Value
+	}
+	entries := make([]entry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := mapKeyName(name, iter.Key())
+		if err != nil {
+			return Argument{}, errors.WithStack(err)
+		}
+		if !validName.MatchString(key) {
+			return Argument{}, errors.WithStack(InvalidNameErr{argumentName, key})
+		}
+		entries = append(entries, entry{key, iter.Value()})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].name < entries[j].name
+	})
+	fields := make([]Argument, len(entries))
+	for i, e := range entries {
+		arg, err := argumentOfValue(e.name, e.value, enum, zero)
+		if err != nil {
+			return Argument{}, errors.WithStack(err)
+		}
+		fields[i] = arg
+	}
+	return ArgumentCustomType(name, fields...), nil
+}
+
+// mapKeyName returns the input object field name of a key of the map of argument.
+func mapKeyName(argument string, key reflect.Value) (string, error) {
+	if key.Kind() == reflect.Interface && !key.IsNil() {
+		key = key.Elem()
+	}
+	if key.CanInterface() {
+		if s, ok := key.Interface().(fmt.Stringer); ok {
+			return s.String(), nil
+		}
+	}
+	if key.Kind() == reflect.String {
+		return key.String(), nil
+	}
+	return "", errors.WithStack(MapKeyNotSupportedErr{argument, key.Interface()})
+}
+
+// isZero reports whether v holds the zero value of its type.
+func isZero(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
@@ -0,0 +1,106 @@
+package graphb
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type priceRange struct {
+	Min int `graphql:"gte"`
+	Max int `graphql:"lte,omitempty"`
+}
+
+type searchFilter struct {
+	Text     string
+	Status   string   `graphql:"status,enum"`
+	Tags     []string `graphql:",omitempty"`
+	Sort     []string `graphql:"sort,enum,omitempty"`
+	Price    *priceRange
+	Ranges   []priceRange `graphql:"ranges,omitempty"`
+	Since    time.Time    `graphql:"since,omitempty"`
+	Archived *bool
+	Internal string `graphql:"-"`
+	secret   string
+}
+
+func TestArgumentsOf(t *testing.T) {
+	args, err := ArgumentsOf(searchFilter{
+		Text:   "graphql",
+		Status: "OPEN",
+		Sort:   []string{"NEWEST", "TOP"},
+		Price:  &priceRange{Min: 10},
+		Ranges: []priceRange{{1, 2}, {3, 0}},
+		secret: "s",
+	})
+	assert.Nil(t, err)
+	f := MakeField("search").SetArguments(args...)
+	assert.Equal(t, `search(text:"graphql",status:OPEN,sort:[NEWEST,TOP],price:{gte:10},ranges:[{gte:1,lte:2},{gte:3}],archived:null)`,
+		StringFromChan(f.stringChan()))
+
+	archived := false
+	since := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	args, err = ArgumentsOf(&searchFilter{Status: "CLOSED", Tags: []string{"a"}, Since: since, Archived: &archived})
+	assert.Nil(t, err)
+	f = MakeField("search").SetArguments(args...)
+	assert.Equal(t, `search(text:"",status:CLOSED,tags:["a"],price:null,since:"2018-01-02T03:04:05Z",archived:false)`,
+		StringFromChan(f.stringChan()))
+}
+
+func TestArgumentsOf_errors(t *testing.T) {
+	_, err := ArgumentsOf("not a struct")
+	assert.Equal(t, ArgumentTypeNotSupportedErr{"", "not a struct", reflect.TypeOf(""), reflect.String, "pass a struct or a pointer to a struct"}, errors.Cause(err))
+
+	_, err = ArgumentsOf(struct{ Ratio complex128 }{1})
+	assert.Equal(t, argumentTypeNotSupported("ratio", complex128(1)), errors.Cause(err))
+
+	_, err = ArgumentsOf(struct{ Ratio float64 }{math.NaN()})
+	assert.IsType(t, InvalidFloatErr{}, errors.Cause(err))
+
+	_, err = ArgumentsOf(struct{ ID uint64 }{math.MaxUint64})
+	assert.Equal(t, UintOverflowErr{"iD", math.MaxUint64}, errors.Cause(err))
+}
+
+func TestArgumentsOf_numbers(t *testing.T) {
+	args, err := ArgumentsOf(struct {
+		Ratio  float64
+		Weight float32
+		Count  uint
+		ID     uint64 `graphql:"id"`
+	}{1.5, 2, 3, 4})
+	assert.Nil(t, err)
+	assert.Equal(t, `stats(ratio:1.5,weight:2.0,count:3,id:4)`, StringFromChan(MakeField("stats").SetArguments(args...).stringChan()))
+}
+
+func TestArgumentsOf_zeroBehavior(t *testing.T) {
+	type page struct {
+		First  int
+		After  string
+		Skip   int    `graphql:"skip,emitempty"`
+		Cursor string `graphql:"cursor,nullempty"`
+		Range  priceRange
+	}
+	build := func(zero ...ZeroBehavior) string {
+		args, err := ArgumentsOf(page{Range: priceRange{Max: 1}}, zero...)
+		assert.Nil(t, err)
+		return StringFromChan(MakeField("posts").SetArguments(args...).stringChan())
+	}
+	assert.Equal(t, `posts(first:0,after:"",skip:0,cursor:null,range:{gte:0,lte:1})`, build())
+	assert.Equal(t, `posts(skip:0,cursor:null,range:{lte:1})`, build(ZeroOmit))
+	assert.Equal(t, `posts(first:null,after:null,skip:0,cursor:null,range:{gte:null,lte:1})`, build(ZeroNull))
+}
+
+func TestArgumentsOf_listValuesAreVisible(t *testing.T) {
+	type user struct {
+		Password string `graphql:"password"`
+	}
+	args, err := ArgumentsOf(struct{ Users []user }{[]user{{"hunter2"}}})
+	assert.Nil(t, err)
+	s, err := MakeQuery(TypeQuery).SetFields(MakeField("import").SetArguments(args...)).StringRedacted("password")
+	assert.Nil(t, err)
+	assert.Equal(t, `query{import(users:[{password:"<redacted>"}])}`, s)
+}
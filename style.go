@@ -0,0 +1,131 @@
+package graphb
+
+import (
+	"strings"
+)
+
+// SerializerStyle is the white space a query is serialized with, see WithSerializerStyle.
+type SerializerStyle int
+
+const (
+	StyleCompact SerializerStyle = iota // No insignificant white space, e.g. `query{user(id:1){name,email}}`.
+	StyleSpaced                         // Spaces after commas and colons and around selection sets, e.g. `query { user(id: 1) { name, email } }`.
+	StylePretty                         // One field per line, indented by two spaces per level.
+)
+
+// styleTokens returns the tokens of tokens with the white space of style added as TokenSpace tokens.
+// The returned channel is closed once tokens is closed.
+func styleTokens(tokens <-chan Token, style SerializerStyle) <-chan Token {
+	if style == StyleCompact {
+		return tokens
+	}
+	tokenChan := make(chan Token)
+	go func() {
+		s := &styler{style: style, tokenChan: tokenChan}
+		for tok := range tokens {
+			s.emit(tok)
+		}
+		close(tokenChan)
+	}()
+	return tokenChan
+}
+
+// styler adds white space to a stream of tokens.
+type styler struct {
+	style     SerializerStyle
+	tokenChan chan<- Token
+	brackets  []string // The open brackets, innermost last.
+	depth     int      // The number of open selection sets.
+	closed    bool     // Whether the last token closed an operation or a fragment definition.
+}
+
+// inValue reports whether the next token is within arguments or variable definitions, whose braces are object values.
+func (s *styler) inValue() bool {
+	for _, b := range s.brackets {
+		if b == tokenLP || b == tokenLSB {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *styler) space(literal string) {
+	s.tokenChan <- Token{TokenSpace, literal}
+}
+
+func (s *styler) newLine() {
+	s.space("\n" + strings.Repeat("  ", s.depth))
+}
+
+func (s *styler) emit(tok Token) {
+	if s.closed {
+		s.closed = false
+		if tok.Kind == TokenKeyword {
+			if s.style == StylePretty {
+				s.space("\n\n")
+			} else {
+				s.space(tokenSpace)
+			}
+		}
+	}
+	if tok.Kind == TokenDirective {
+		s.space(tokenSpace)
+	}
+	if tok.Kind != TokenPunctuator {
+		s.tokenChan <- tok
+		return
+	}
+
+	selection := !s.inValue()
+	switch tok.Literal {
+	case tokenLP, tokenLSB:
+		s.brackets = append(s.brackets, tok.Literal)
+	case tokenRP, tokenRSB:
+		s.brackets = s.brackets[:len(s.brackets)-1]
+	case tokenLB:
+		s.brackets = append(s.brackets, tok.Literal)
+		if selection {
+			s.depth++
+			s.space(tokenSpace)
+			s.tokenChan <- tok
+			if s.style == StylePretty {
+				s.newLine()
+			} else {
+				s.space(tokenSpace)
+			}
+			return
+		}
+	case tokenRB:
+		s.brackets = s.brackets[:len(s.brackets)-1]
+		if selection = !s.inValue(); selection {
+			s.depth--
+			if s.style == StylePretty {
+				s.newLine()
+			} else {
+				s.space(tokenSpace)
+			}
+			s.tokenChan <- tok
+			s.closed = s.depth == 0
+			return
+		}
+	case tokenComma:
+		if selection && s.style == StylePretty {
+			// commas are insignificant between fields on their own lines
+			s.newLine()
+			return
+		}
+		s.tokenChan <- tok
+		s.space(tokenSpace)
+		return
+	case tokenColumn:
+		s.tokenChan <- tok
+		s.space(tokenSpace)
+		return
+	case tokenEquals:
+		s.space(tokenSpace)
+		s.tokenChan <- tok
+		s.space(tokenSpace)
+		return
+	}
+	s.tokenChan <- tok
+}
@@ -0,0 +1,83 @@
+package graphb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSerializerStyle(t *testing.T) {
+	build := func(style SerializerStyle) *Query {
+		return MakeQuery(TypeQuery, NewConfig(WithSerializerStyle(style))).
+			SetName("getUser").
+			AddVariables(Variable{Name: "first", Type: "Int"}.WithDefault(ArgumentInt("first", 10))).
+			SetFields(
+				MakeField("user").SetArguments(ArgumentInt("id", 1)).SetFields(
+					MakeField("name").SetAlias("handle"),
+					MakeField("posts").
+						SetArguments(ArgumentVariable("first", "first"), ArgumentCustomType("orderBy", ArgumentEnum("field", "CREATED_AT")), ArgumentIntSlice("ids", 1, 2)).
+						AddDirectives(MakeDirective("include", ArgumentBool("if", true))).
+						SetFields(MakeField("title")),
+				),
+			)
+	}
+
+	s, err := build(StyleCompact).StringParallel(2)
+	assert.Nil(t, err)
+	assert.Equal(t, `query getUser($first:Int=10){user(id:1){handle:name,posts(first:$first,orderBy:{field:CREATED_AT},ids:[1,2])@include(if:true){title}}}`, s)
+
+	s, err = build(StyleSpaced).StringParallel(2)
+	assert.Nil(t, err)
+	assert.Equal(t, `query getUser($first: Int = 10) { user(id: 1) { handle: name, posts(first: $first, orderBy: {field: CREATED_AT}, ids: [1, 2]) @include(if: true) { title } } }`, s)
+
+	strCh, err := build(StylePretty).StringChan()
+	assert.Nil(t, err)
+	assert.Equal(t, `query getUser($first: Int = 10) {
+  user(id: 1) {
+    handle: name
+    posts(first: $first, orderBy: {field: CREATED_AT}, ids: [1, 2]) @include(if: true) {
+      title
+    }
+  }
+}`, StringFromChan(strCh))
+
+	// the white space is made of space tokens
+	tokens, err := build(StylePretty).Tokens()
+	assert.Nil(t, err)
+	for tok := range tokens {
+		if tok.Kind != TokenSpace && tok.Kind != TokenString {
+			assert.NotContains(t, tok.Literal, " ")
+		}
+	}
+}
+
+func TestSerializerStyle_fragments(t *testing.T) {
+	r := NewFragmentRegistry()
+	assert.Nil(t, r.RegisterFragment("Contact", "User", MakeField("email"), MakeField("phone")))
+	contact, err := r.Spread("Contact")
+	assert.Nil(t, err)
+	q := MakeQuery(TypeQuery, NewConfig(WithSerializerStyle(StylePretty))).SetFields(MakeField("me").SetFields(contact))
+	q.FragmentDefinitions = true
+	strCh, err := q.StringChan()
+	assert.Nil(t, err)
+	assert.Equal(t, `query {
+  me {
+    ...Contact
+  }
+}
+
+fragment Contact on User {
+  email
+  phone
+}`, StringFromChan(strCh))
+
+	c := NewShapeCache(4)
+	q.FragmentDefinitions = false
+	s, err := c.String(q)
+	assert.Nil(t, err)
+	q.Config = NewConfig(WithSerializerStyle(StyleSpaced))
+	spaced, err := c.String(q)
+	assert.Nil(t, err)
+	assert.NotEqual(t, s, spaced)
+	assert.Equal(t, `query { me { ... on User { email, phone } } }`, spaced)
+}
@@ -0,0 +1,96 @@
+package graphb
+
+// TokenKind classifies a Token so that consumers of Query.Tokens() don't have to re-lex the serialized query.
+type TokenKind int
+
+// Kinds of tokens emitted by the serializer.
+const (
+	TokenPunctuator TokenKind = iota // { } ( ) [ ] : ,
+	TokenSpace                       // white space between tokens
+	TokenKeyword                     // operation type: query, mutation or subscription
+	TokenName                        // operation, alias, field and argument names
+	TokenInt                         // integer values
+	TokenString                      // string, block string and time values, quotes included
+	TokenBoolean                     // true or false
+	TokenEnum                        // enum values
+	TokenVariable                    // variable definitions and references, '$' included
+	TokenType                        // type references of variable definitions
+	TokenDirective                   // directive names, '@' included
+	TokenFloat                       // float values
+	TokenNull                        // null
+)
+
+var tokenKindNames = map[TokenKind]string{
+	TokenPunctuator: "Punctuator",
+	TokenSpace:      "Space",
+	TokenKeyword:    "Keyword",
+	TokenName:       "Name",
+	TokenInt:        "Int",
+	TokenString:     "String",
+	TokenBoolean:    "Boolean",
+	TokenEnum:       "Enum",
+	TokenVariable:   "Variable",
+	TokenType:       "Type",
+	TokenDirective:  "Directive",
+	TokenFloat:      "Float",
+	TokenNull:       "Null",
+}
+
+func (k TokenKind) String() string {
+	if name, ok := tokenKindNames[k]; ok {
+		return name
+	}
+	return "Unknown"
+}
+
+// Token is a single lexical unit of a serialized query.
+// Concatenating the Literal of every token of a query gives the query string.
+type Token struct {
+	Kind    TokenKind
+	Literal string
+}
+
+// Punctuator is the literal of a TokenPunctuator, for the tokens of OperationOption(s) and TokenProvider(s).
+type Punctuator string
+
+// Punctuators emitted by the serializer.
+const (
+	PunctuatorLeftBrace        Punctuator = "{"
+	PunctuatorRightBrace       Punctuator = "}"
+	PunctuatorLeftParenthesis  Punctuator = "("
+	PunctuatorRightParenthesis Punctuator = ")"
+	PunctuatorLeftBracket      Punctuator = "["
+	PunctuatorRightBracket     Punctuator = "]"
+	PunctuatorColon            Punctuator = ":"
+	PunctuatorComma            Punctuator = ","
+	PunctuatorEquals           Punctuator = "="
+)
+
+// Token returns the TokenPunctuator of p.
+func (p Punctuator) Token() Token {
+	return Token{TokenPunctuator, string(p)}
+}
+
+// Keywords emitted by the serializer besides the OperationType(s).
+const (
+	KeywordFragment = "fragment"
+	KeywordOn       = "on"
+)
+
+// TokenProvider is a custom argument value which serializes itself into tokens, see ArgumentTokens.
+type TokenProvider interface {
+	Tokens() []Token
+}
+
+// literals turns a token channel into a channel of their literals.
+// The returned channel is closed once tokens is closed.
+func literals(tokens <-chan Token) <-chan string {
+	strChan := make(chan string)
+	go func() {
+		for tok := range tokens {
+			strChan <- tok.Literal
+		}
+		close(strChan)
+	}()
+	return strChan
+}
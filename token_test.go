@@ -0,0 +1,65 @@
+package graphb
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenKind_String(t *testing.T) {
+	assert.Equal(t, "Punctuator", TokenPunctuator.String())
+	assert.Equal(t, "Enum", TokenEnum.String())
+	assert.Equal(t, "Unknown", TokenKind(-1).String())
+}
+
+func Test_literals(t *testing.T) {
+	tokens := make(chan Token)
+	go func() {
+		tokens <- Token{TokenName, "a"}
+		tokens <- Token{TokenPunctuator, tokenColumn}
+		tokens <- Token{TokenInt, "1"}
+		close(tokens)
+	}()
+	assert.Equal(t, "a:1", StringFromChan(literals(tokens)))
+}
+
+// point is a custom scalar serialized as an input object.
+type point struct{ x, y int }
+
+func (p point) Tokens() []Token {
+	return []Token{
+		PunctuatorLeftBrace.Token(),
+		{TokenName, "x"}, PunctuatorColon.Token(), {TokenInt, strconv.Itoa(p.x)}, PunctuatorComma.Token(),
+		{TokenName, "y"}, PunctuatorColon.Token(), {TokenVariable, "$y"},
+		PunctuatorRightBrace.Token(),
+	}
+}
+
+type tokens []Token
+
+func (t tokens) Tokens() []Token { return t }
+
+func TestArgumentTokens(t *testing.T) {
+	arg, err := ArgumentTokens("at", point{1, 2})
+	assert.Nil(t, err)
+	q := MakeQuery(TypeQuery).AddVariables(Variable{Name: "y", Type: "Int!"}).SetFields(MakeField("near").SetArguments(arg))
+	s, err := q.JSON()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"query":"query($y:Int!){near(at:{x:1,y:$y})}"}`, s, "the variable is seen as used")
+
+	_, err = ArgumentTokens("at", tokens{{TokenInt, "1"}, {TokenInt, "2"}})
+	assert.Equal(t, InvalidTokensErr{"at", "unexpected '2', expected the end of the value"}, errors.Cause(err))
+	_, err = ArgumentTokens("at", tokens{PunctuatorLeftBracket.Token()})
+	assert.Equal(t, InvalidTokensErr{"at", "unexpected end of document, expected a value"}, errors.Cause(err))
+	_, err = ArgumentTokens("at", tokens{})
+	assert.Equal(t, InvalidTokensErr{"at", "no value"}, errors.Cause(err))
+}
+
+func TestOperationType(t *testing.T) {
+	var op OperationType = TypeMutation
+	s, err := MakeQuery(op).SetFields(MakeField("like")).JSON()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"query":"mutation{like}"}`, s)
+}
@@ -0,0 +1,267 @@
+package graphb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Request is an operation to execute through a Transport, see Query.Request.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	Extensions    map[string]interface{} `json:"extensions,omitempty"`
+	Headers       map[string]string      `json:"-"` // Sent by the transports having headers.
+}
+
+// Response is the execution result of a Request.
+type Response struct {
+	Data       json.RawMessage `json:"data,omitempty"`
+	Errors     json.RawMessage `json:"errors,omitempty"`
+	Extensions json.RawMessage `json:"extensions,omitempty"`
+}
+
+// Transport executes requests, over the network or in process.
+// A Response carrying GraphQL errors is not an error of the transport.
+type Transport interface {
+	Execute(ctx context.Context, req Request) (Response, error)
+}
+
+// TransportFunc is a function executing requests, e.g. calling an in-process server or a test double.
+type TransportFunc func(ctx context.Context, req Request) (Response, error)
+
+// Execute implements Transport.
+func (f TransportFunc) Execute(ctx context.Context, req Request) (Response, error) {
+	return f(ctx, req)
+}
+
+// Request returns the Request executing this Query, with its headers.
+func (q *Query) Request() (Request, error) {
+	strCh, err := q.StringChan()
+	if err != nil {
+		return Request{}, errors.WithStack(err)
+	}
+	req := Request{Query: StringFromChan(strCh), OperationName: q.Name, Extensions: q.Extensions, Headers: q.Headers}
+	if values := q.variableValues(); len(values) > 0 {
+		req.Variables = values
+	}
+	return req, nil
+}
+
+// ExecuteWith returns an ExecuteFunc executing queries through t.
+// A response carrying GraphQL errors returns its data along with a GraphQLErrorsErr.
+func ExecuteWith(t Transport) ExecuteFunc {
+	return func(ctx context.Context, q *Query) (json.RawMessage, error) {
+		req, err := q.Request()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		resp, err := t.Execute(ctx, req)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if len(resp.Errors) > 0 && string(resp.Errors) != "null" {
+			return resp.Data, errors.WithStack(GraphQLErrorsErr{resp.Errors})
+		}
+		return resp.Data, nil
+	}
+}
+
+// HTTPTransport executes requests as POST requests with a JSON body.
+type HTTPTransport struct {
+	Endpoint string
+	Client   *http.Client    // http.DefaultClient if nil.
+	Options  []RequestOption // Options of every request, of which headers and WithGzipBody apply.
+}
+
+// NewHandlerTransport returns an HTTPTransport calling handler in process, without network,
+// e.g. the http.Handler of an embedded GraphQL server. An executable schema of gqlgen or graphql-go is served by its handler:
+//
+//	transport := NewHandlerTransport(handler.NewDefaultServer(generated.NewExecutableSchema(config)))
+func NewHandlerTransport(handler http.Handler) *HTTPTransport {
+	return &HTTPTransport{Endpoint: "http://in-process/", Client: &http.Client{Transport: handlerRoundTripper{handler}}}
+}
+
+// Execute implements Transport.
+// The response is decoded if it is JSON, whatever its status, since GraphQL over HTTP reports request errors with 4xx statuses.
+func (t *HTTPTransport) Execute(ctx context.Context, req Request) (Response, error) {
+	o := newRequestOptions(t.Options)
+	b, err := json.Marshal(req)
+	if err != nil {
+		return Response{}, errors.WithStack(err)
+	}
+	if o.gzip {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(b); err != nil {
+			return Response{}, errors.WithStack(err)
+		}
+		if err := w.Close(); err != nil {
+			return Response{}, errors.WithStack(err)
+		}
+		b = buf.Bytes()
+		o.header.Set("Content-Encoding", "gzip")
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, t.Endpoint, bytes.NewReader(b))
+	if err != nil {
+		return Response{}, errors.WithStack(err)
+	}
+	httpReq.Header.Set("Content-Type", ContentTypeJSON)
+	httpReq.Header.Set("Accept", ContentTypeGraphQLResponse+", "+ContentTypeJSON+";q=0.9")
+	for k, v := range req.Headers {
+		httpReq.Header.Set(k, v)
+	}
+	for k, v := range o.header {
+		httpReq.Header[k] = v
+	}
+
+	client := t.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	httpResp, err := client.Do(httpReq)
+	if err != nil {
+		return Response{}, errors.WithStack(err)
+	}
+	defer httpResp.Body.Close()
+	switch mediaType, _, _ := mime.ParseMediaType(httpResp.Header.Get("Content-Type")); {
+	case mediaType == ContentTypeJSON || mediaType == ContentTypeGraphQLResponse:
+		var resp Response
+		if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+			return Response{}, errors.WithStack(err)
+		}
+		return resp, nil
+	case httpResp.StatusCode != http.StatusOK:
+		return Response{}, errors.WithStack(UnexpectedStatusErr{httpResp.StatusCode})
+	default:
+		return Response{}, errors.WithStack(UnexpectedContentTypeErr{httpResp.Header.Get("Content-Type")})
+	}
+}
+
+// handlerRoundTripper serves requests with an http.Handler in process.
+type handlerRoundTripper struct {
+	handler http.Handler
+}
+
+func (rt handlerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorder := httptest.NewRecorder()
+	rt.handler.ServeHTTP(recorder, req)
+	return recorder.Result(), nil
+}
+
+// MessageConn is a connection exchanging JSON messages, e.g. the *websocket.Conn of gorilla/websocket.
+type MessageConn interface {
+	WriteJSON(v interface{}) error
+	ReadJSON(v interface{}) error
+	Close() error
+}
+
+// WebSocketTransport executes requests with the graphql-transport-ws protocol, over a connection opened by Dial for each request.
+// Only the first result of an operation is returned, subscriptions are executed with a subscription client.
+type WebSocketTransport struct {
+	Dial        func(ctx context.Context) (MessageConn, error)
+	InitPayload map[string]interface{} // The payload of the connection_init message, e.g. authorization.
+}
+
+// wsMessage is a message of the graphql-transport-ws protocol.
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Execute implements Transport.
+func (t *WebSocketTransport) Execute(ctx context.Context, req Request) (Response, error) {
+	conn, err := t.Dial(ctx)
+	if err != nil {
+		return Response{}, errors.WithStack(err)
+	}
+	// closing conn unblocks a pending read once ctx is done
+	var closeOnce sync.Once
+	closeConn := func() { closeOnce.Do(func() { conn.Close() }) }
+	done := make(chan struct{})
+	defer close(done)
+	defer closeConn()
+	go func() {
+		select {
+		case <-ctx.Done():
+			closeConn()
+		case <-done:
+		}
+	}()
+
+	resp, err := t.exchange(conn, req)
+	if err != nil && ctx.Err() != nil {
+		return Response{}, errors.WithStack(ctx.Err())
+	}
+	return resp, errors.WithStack(err)
+}
+
+// exchange initializes conn and executes req as the operation "1".
+func (t *WebSocketTransport) exchange(conn MessageConn, req Request) (Response, error) {
+	init := wsMessage{Type: "connection_init"}
+	if t.InitPayload != nil {
+		b, err := json.Marshal(t.InitPayload)
+		if err != nil {
+			return Response{}, errors.WithStack(err)
+		}
+		init.Payload = b
+	}
+	if err := conn.WriteJSON(init); err != nil {
+		return Response{}, errors.WithStack(err)
+	}
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return Response{}, errors.WithStack(err)
+	}
+	const id = "1"
+	acknowledged := false
+	for {
+		var msg wsMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			return Response{}, errors.WithStack(err)
+		}
+		switch msg.Type {
+		case "connection_ack":
+			if acknowledged {
+				continue
+			}
+			acknowledged = true
+			if err := conn.WriteJSON(wsMessage{ID: id, Type: "subscribe", Payload: payload}); err != nil {
+				return Response{}, errors.WithStack(err)
+			}
+		case "ping":
+			if err := conn.WriteJSON(wsMessage{Type: "pong"}); err != nil {
+				return Response{}, errors.WithStack(err)
+			}
+		case "next":
+			if msg.ID != id {
+				continue
+			}
+			var resp Response
+			if err := json.Unmarshal(msg.Payload, &resp); err != nil {
+				return Response{}, errors.WithStack(err)
+			}
+			conn.WriteJSON(wsMessage{ID: id, Type: "complete"})
+			return resp, nil
+		case "error":
+			if msg.ID != id {
+				continue
+			}
+			return Response{Errors: msg.Payload}, nil
+		case "complete":
+			if msg.ID == id {
+				return Response{}, errors.WithStack(io.ErrUnexpectedEOF)
+			}
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package graphb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// graphQLHandler answers the query {me{name}} and rejects any other one with a 400 status.
+var graphQLHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	var req Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", ContentTypeGraphQLResponse)
+	if req.Query != "query Me($id:ID){me(id:$id){name}}" {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"errors":[{"message":"unknown query"}]}`)
+		return
+	}
+	fmt.Fprintf(w, `{"data":{"me":{"name":%q}},"extensions":{"operationName":%q}}`, r.Header.Get("Authorization"), req.OperationName)
+})
+
+func meQuery() *Query {
+	return MakeQuery(TypeQuery).SetName("Me").AddVariables(Variable{Name: "id", Type: "ID", Value: "1"}).
+		SetFields(MakeField("me").SetArguments(ArgumentVariable("id", "id")).SetFields(MakeField("name"))).
+		AddHeader("Authorization", "Bearer q")
+}
+
+func TestQuery_Request(t *testing.T) {
+	req, err := meQuery().Request()
+	assert.Nil(t, err)
+	assert.Equal(t, Request{
+		Query:         "query Me($id:ID){me(id:$id){name}}",
+		OperationName: "Me",
+		Variables:     map[string]interface{}{"id": "1"},
+		Headers:       map[string]string{"Authorization": "Bearer q"},
+	}, req)
+
+	_, err = MakeQuery(TypeQuery).SetFields(MakeField("bad name")).Request()
+	assert.IsType(t, InvalidNameErr{}, errors.Cause(err))
+}
+
+func TestHTTPTransport(t *testing.T) {
+	server := httptest.NewServer(graphQLHandler)
+	defer server.Close()
+
+	data, err := ExecuteWith(&HTTPTransport{Endpoint: server.URL})(context.Background(), meQuery())
+	assert.Nil(t, err)
+	assert.Equal(t, `{"me":{"name":"Bearer q"}}`, string(data))
+
+	transport := &HTTPTransport{Endpoint: server.URL, Options: []RequestOption{WithRequestHeader("Authorization", "Bearer o")}}
+	resp, err := transport.Execute(context.Background(), Request{Query: "query Me($id:ID){me(id:$id){name}}", OperationName: "Me"})
+	assert.Nil(t, err)
+	assert.Equal(t, Response{
+		Data:       json.RawMessage(`{"me":{"name":"Bearer o"}}`),
+		Extensions: json.RawMessage(`{"operationName":"Me"}`),
+	}, resp)
+
+	// a request error is a response, not an error of the transport
+	resp, err = transport.Execute(context.Background(), Request{Query: "{a}"})
+	assert.Nil(t, err)
+	assert.Equal(t, Response{Errors: json.RawMessage(`[{"message":"unknown query"}]`)}, resp)
+
+	data, err = ExecuteWith(transport)(context.Background(), MakeQuery(TypeQuery).SetFields(MakeField("a")))
+	assert.Nil(t, data)
+	assert.Equal(t, GraphQLErrorsErr{json.RawMessage(`[{"message":"unknown query"}]`)}, errors.Cause(err))
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	_, err = (&HTTPTransport{Endpoint: failing.URL}).Execute(context.Background(), Request{Query: "{a}"})
+	assert.Equal(t, UnexpectedStatusErr{http.StatusServiceUnavailable}, errors.Cause(err))
+}
+
+func TestNewHandlerTransport(t *testing.T) {
+	data, err := ExecuteWith(NewHandlerTransport(graphQLHandler))(context.Background(), meQuery())
+	assert.Nil(t, err)
+	assert.Equal(t, `{"me":{"name":"Bearer q"}}`, string(data))
+}
+
+func TestTransportFunc(t *testing.T) {
+	var transport Transport = TransportFunc(func(ctx context.Context, req Request) (Response, error) {
+		return Response{Data: json.RawMessage(`{"me":null}`)}, nil
+	})
+	data, err := ExecuteWith(transport)(context.Background(), meQuery())
+	assert.Nil(t, err)
+	assert.Equal(t, `{"me":null}`, string(data))
+}
+
+// fakeConn is a MessageConn whose messages are exchanged with a test over channels.
+type fakeConn struct {
+	sent     chan wsMessage
+	received chan wsMessage
+	closed   chan struct{}
+}
+
+func newFakeConn() *fakeConn {
+	return &fakeConn{sent: make(chan wsMessage, 10), received: make(chan wsMessage, 10), closed: make(chan struct{})}
+}
+
+func (c *fakeConn) WriteJSON(v interface{}) error {
+	c.sent <- v.(wsMessage)
+	return nil
+}
+
+func (c *fakeConn) ReadJSON(v interface{}) error {
+	select {
+	case msg := <-c.received:
+		*v.(*wsMessage) = msg
+		return nil
+	case <-c.closed:
+		return io.EOF
+	}
+}
+
+func (c *fakeConn) Close() error {
+	close(c.closed)
+	return nil
+}
+
+func TestWebSocketTransport(t *testing.T) {
+	conn := newFakeConn()
+	transport := &WebSocketTransport{
+		Dial:        func(ctx context.Context) (MessageConn, error) { return conn, nil },
+		InitPayload: map[string]interface{}{"token": "t"},
+	}
+	conn.received <- wsMessage{Type: "connection_ack"}
+	conn.received <- wsMessage{Type: "ping"}
+	conn.received <- wsMessage{ID: "1", Type: "next", Payload: json.RawMessage(`{"data":{"me":{"name":"Ada"}}}`)}
+	data, err := ExecuteWith(transport)(context.Background(), meQuery())
+	assert.Nil(t, err)
+	assert.Equal(t, `{"me":{"name":"Ada"}}`, string(data))
+	assert.Equal(t, wsMessage{Type: "connection_init", Payload: json.RawMessage(`{"token":"t"}`)}, <-conn.sent)
+	assert.Equal(t, wsMessage{ID: "1", Type: "subscribe", Payload: json.RawMessage(`{"query":"query Me($id:ID){me(id:$id){name}}","operationName":"Me","variables":{"id":"1"}}`)}, <-conn.sent)
+	assert.Equal(t, wsMessage{Type: "pong"}, <-conn.sent)
+	assert.Equal(t, wsMessage{ID: "1", Type: "complete"}, <-conn.sent)
+
+	conn = newFakeConn()
+	conn.received <- wsMessage{Type: "connection_ack"}
+	conn.received <- wsMessage{ID: "1", Type: "error", Payload: json.RawMessage(`[{"message":"invalid"}]`)}
+	resp, err := transport.Execute(context.Background(), Request{Query: "{a}"})
+	assert.Nil(t, err)
+	assert.Equal(t, Response{Errors: json.RawMessage(`[{"message":"invalid"}]`)}, resp)
+
+	conn = newFakeConn()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = transport.Execute(ctx, Request{Query: "{a}"})
+	assert.Equal(t, context.Canceled, errors.Cause(err))
+}
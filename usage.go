@@ -0,0 +1,161 @@
+package graphb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// OperationUsage is the usage of an operation reported by a UsageReporter.
+type OperationUsage struct {
+	Hash          string   `json:"hash"` // See Query.Hash.
+	Name          string   `json:"name,omitempty"`
+	ClientName    string   `json:"clientName,omitempty"`
+	ClientVersion string   `json:"clientVersion,omitempty"`
+	Fields        []string `json:"fields"` // The leaf paths of the operation joined by '.', see Query.LeafPaths.
+	Count         int      `json:"count"`  // The number of executions since the previous report.
+}
+
+// UsageReport is the JSON body a UsageReporter posts to its endpoint.
+type UsageReport struct {
+	Operations []OperationUsage `json:"operations"`
+}
+
+// UsageReporter counts the executions of operations and reports them to a schema registry, e.g. Apollo GraphOS or Hive
+// through a relay translating the UsageReport, so that platform teams know which fields the queries built by graphb use.
+// It is safe for concurrent use. Reporting is up to the caller, e.g. calling Flush on a time.Ticker and on shutdown.
+type UsageReporter struct {
+	Endpoint      string
+	Client        *http.Client      // http.DefaultClient if nil.
+	Headers       map[string]string // Sent with each report, e.g. the API key of the registry.
+	ClientName    string            // The name of the client reported, e.g. the name of the service.
+	ClientVersion string
+
+	mu      sync.Mutex
+	pending map[string]*OperationUsage // By hash and client.
+}
+
+// Record counts an execution of q.
+func (r *UsageReporter) Record(q *Query) error {
+	hash, err := q.Hash()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	key := hash + "\n" + r.ClientName + "\n" + r.ClientVersion
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if usage, ok := r.pending[key]; ok {
+		usage.Count++
+		return nil
+	}
+	paths := q.LeafPaths()
+	fields := make([]string, len(paths))
+	for i, path := range paths {
+		fields[i] = strings.Join(path, ".")
+	}
+	if r.pending == nil {
+		r.pending = make(map[string]*OperationUsage)
+	}
+	r.pending[key] = &OperationUsage{
+		Hash:          hash,
+		Name:          q.Name,
+		ClientName:    r.ClientName,
+		ClientVersion: r.ClientVersion,
+		Fields:        fields,
+		Count:         1,
+	}
+	return nil
+}
+
+// Flush posts the usage recorded since the previous report as a UsageReport, sorted by hash, and resets it.
+// Nothing is posted if nothing was recorded. The usage of a failed report is counted again in the next one.
+func (r *UsageReporter) Flush(ctx context.Context) error {
+	r.mu.Lock()
+	pending := r.pending
+	r.pending = nil
+	r.mu.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	report := UsageReport{Operations: make([]OperationUsage, 0, len(pending))}
+	for _, usage := range pending {
+		report.Operations = append(report.Operations, *usage)
+	}
+	sort.Slice(report.Operations, func(i, j int) bool {
+		a, b := report.Operations[i], report.Operations[j]
+		if a.Hash != b.Hash {
+			return a.Hash < b.Hash
+		}
+		if a.ClientName != b.ClientName {
+			return a.ClientName < b.ClientName
+		}
+		return a.ClientVersion < b.ClientVersion
+	})
+	if err := r.post(ctx, report); err != nil {
+		r.restore(pending)
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+func (r *UsageReporter) post(ctx context.Context, report UsageReport) error {
+	b, err := json.Marshal(report)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.Endpoint, bytes.NewReader(b))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	req.Header.Set("Content-Type", ContentTypeJSON)
+	for k, v := range r.Headers {
+		req.Header.Set(k, v)
+	}
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return errors.WithStack(UnexpectedStatusErr{resp.StatusCode})
+	}
+	return nil
+}
+
+// restore adds the usage of a failed report to the usage recorded since.
+func (r *UsageReporter) restore(pending map[string]*OperationUsage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pending == nil {
+		r.pending = pending
+		return
+	}
+	for key, usage := range pending {
+		if recorded, ok := r.pending[key]; ok {
+			recorded.Count += usage.Count
+		} else {
+			r.pending[key] = usage
+		}
+	}
+}
+
+// WithUsageReporting returns an ExecuteFunc which records every query executed by execute into reporter,
+// whether it succeeds or not. A query which can not be hashed is not recorded.
+func WithUsageReporting(execute ExecuteFunc, reporter *UsageReporter) ExecuteFunc {
+	return func(ctx context.Context, q *Query) (json.RawMessage, error) {
+		// a query failing to hash fails to execute as well
+		reporter.Record(q)
+		return execute(ctx, q)
+	}
+}
@@ -0,0 +1,96 @@
+package graphb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsageReporter(t *testing.T) {
+	var reports []UsageReport
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "key", r.Header.Get("X-Api-Key"))
+		assert.Equal(t, ContentTypeJSON, r.Header.Get("Content-Type"))
+		var report UsageReport
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&report))
+		reports = append(reports, report)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	reporter := &UsageReporter{Endpoint: server.URL, Headers: map[string]string{"X-Api-Key": "key"}, ClientName: "web", ClientVersion: "1.2"}
+	execute := WithUsageReporting(func(ctx context.Context, q *Query) (json.RawMessage, error) {
+		return nil, errors.New("down")
+	}, reporter)
+
+	// nothing recorded, nothing posted
+	assert.Nil(t, reporter.Flush(context.Background()))
+	assert.Empty(t, reports)
+
+	other := MakeQuery(TypeQuery).SetFields(MakeField("a").SetFields(MakeField("b"), MakeField("__typename")))
+	for _, q := range []*Query{meQuery(), meQuery(), other} {
+		_, err := execute(context.Background(), q)
+		assert.EqualError(t, err, "down")
+	}
+	// not hashed, not recorded
+	assert.IsType(t, InvalidNameErr{}, errors.Cause(reporter.Record(MakeQuery(TypeQuery).SetFields(MakeField("bad name")))))
+
+	meHash, err := meQuery().Hash()
+	assert.Nil(t, err)
+	otherHash, err := other.Hash()
+	assert.Nil(t, err)
+	expected := []OperationUsage{
+		{Hash: meHash, Name: "Me", ClientName: "web", ClientVersion: "1.2", Fields: []string{"me.name"}, Count: 2},
+		{Hash: otherHash, ClientName: "web", ClientVersion: "1.2", Fields: []string{"a.b"}, Count: 1},
+	}
+	if otherHash < meHash {
+		expected[0], expected[1] = expected[1], expected[0]
+	}
+
+	// a failed report is counted again
+	status = http.StatusServiceUnavailable
+	assert.Equal(t, UnexpectedStatusErr{http.StatusServiceUnavailable}, errors.Cause(reporter.Flush(context.Background())))
+	assert.Nil(t, reporter.Record(meQuery()))
+	status = http.StatusNoContent
+	assert.Nil(t, reporter.Flush(context.Background()))
+	assert.Len(t, reports, 2)
+	for i := range expected {
+		if expected[i].Hash == meHash {
+			expected[i].Count++
+		}
+	}
+	assert.Equal(t, UsageReport{expected}, reports[1])
+
+	// reset after a report
+	assert.Nil(t, reporter.Flush(context.Background()))
+	assert.Len(t, reports, 2)
+}
+
+func TestUsageReporter_Concurrent(t *testing.T) {
+	reporter := &UsageReporter{Endpoint: "http://127.0.0.1:0"}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				assert.Nil(t, reporter.Record(meQuery()))
+			}
+			// unreachable, counted again
+			assert.NotNil(t, reporter.Flush(context.Background()))
+		}()
+	}
+	wg.Wait()
+	count := 0
+	for _, usage := range reporter.pending {
+		count += usage.Count
+	}
+	assert.Equal(t, 400, count)
+}
@@ -0,0 +1,105 @@
+package graphb
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Severity is how serious a Problem found by Validate is.
+type Severity int
+
+const (
+	SeverityError   Severity = iota // The query is not serialized.
+	SeverityWarning                 // The query is serialized, but a check turned off by its Config rejects it.
+)
+
+func (s Severity) String() string {
+	if s == SeverityWarning {
+		return "warning"
+	}
+	return "error"
+}
+
+// Problem is an issue found by Validate.
+type Problem struct {
+	Severity Severity
+	Err      error
+}
+
+// reporter receives the problems found by validation.
+// Serialization stops at the first error and ignores warnings, Validate goes on to report them all.
+type reporter struct {
+	report   func(p Problem) bool // Returns whether validation goes on.
+	warnings bool                 // Whether warnings are reported, which costs running the checks turned off.
+}
+
+// check reports err as an error unless it is nil, and returns whether validation goes on.
+func (r reporter) check(err error) bool {
+	return err == nil || r.report(Problem{SeverityError, errors.WithStack(err)})
+}
+
+// warn reports err as a warning unless it is nil, and returns whether validation goes on.
+func (r reporter) warn(err error) bool {
+	return err == nil || r.report(Problem{SeverityWarning, errors.WithStack(err)})
+}
+
+// firstProblem returns a reporter which keeps the first error in err and stops validation.
+func firstProblem(err *error) reporter {
+	return reporter{report: func(p Problem) bool {
+		*err = p.Err
+		return false
+	}}
+}
+
+// allProblems returns a reporter which appends every problem, warnings included, to problems.
+func allProblems(problems *[]Problem) reporter {
+	return reporter{report: func(p Problem) bool {
+		*problems = append(*problems, p)
+		return true
+	}, warnings: true}
+}
+
+// strict returns a copy of c, which may be nil, with every check turned on,
+// to find what the checks c turns off would reject.
+func (c *Config) strict() *Config {
+	var s Config
+	if c != nil {
+		s = *c
+	}
+	s.AllowReservedNames = false
+	s.CheckIntRange = true
+	s.CheckSelections = true
+	s.CheckSharedFields = true
+	return &s
+}
+
+// Validate runs the checks of serialization on this Query, following its Config, and returns a ValidationErr
+// listing every problem found instead of the first one only, or nil if there is none.
+// What the checks turned off by the Config reject is listed with SeverityWarning.
+func (q *Query) Validate() error {
+	var problems []Problem
+	q.validate(allProblems(&problems))
+	return validationErr(problems)
+}
+
+// Validate runs the checks of serialization on this Field and its sub fields, and returns a ValidationErr
+// listing every problem found, or nil if there is none. See Query.Validate.
+func (f *Field) Validate() error {
+	var problems []Problem
+	f.validate(nil, allProblems(&problems))
+	return validationErr(problems)
+}
+
+// Validate runs the checks of serialization on the operations and the fragments of this document, and returns
+// a ValidationErr listing every problem found, or nil if there is none. See Query.Validate.
+func (d *Document) Validate() error {
+	var problems []Problem
+	d.validate(allProblems(&problems))
+	return validationErr(problems)
+}
+
+func validationErr(problems []Problem) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	return errors.WithStack(ValidationErr{problems})
+}
@@ -0,0 +1,97 @@
+package graphb
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// causes returns the causes of the problems of the given severity of a ValidationErr.
+func causes(err error, severity Severity) []error {
+	v, ok := errors.Cause(err).(ValidationErr)
+	if !ok {
+		return nil
+	}
+	var causes []error
+	for _, e := range v.Errs(severity) {
+		causes = append(causes, errors.Cause(e))
+	}
+	return causes
+}
+
+func TestQuery_Validate(t *testing.T) {
+	q := MakeQuery(TypeQuery, NewConfig(WithIntRangeCheck())).
+		SetName("bad name").
+		AddVariables(Variable{Name: "unused", Type: "Int"}).
+		SetFields(
+			MakeField("user").SetAlias("1st").SetArguments(ArgumentVariable("id", "id"), ArgumentInt("id", 1<<40)).SetFields(
+				MakeField("bad-name"),
+				MakeField("posts").AddDirectives(MakeDirective("include")),
+			),
+		)
+	err := q.Validate()
+	assert.Equal(t, []error{
+		InvalidNameErr{operationName, "bad name"},
+		InvalidNameErr{aliasName, "1st"},
+		DuplicateArgumentErr{"id"},
+		IntOutOfRangeErr{"id", 1 << 40},
+		InvalidNameErr{fieldName, "bad-name"},
+		UndefinedVariableErr{"id"},
+		UnusedVariableErr{"unused"},
+	}, causes(err, SeverityError))
+	assert.Nil(t, causes(err, SeverityWarning))
+	assert.Contains(t, err.Error(), "7 validation problem(s): error: ")
+
+	// serialization stops at the first problem
+	_, err = q.StringChan()
+	assert.Equal(t, InvalidNameErr{operationName, "bad name"}, errors.Cause(err))
+
+	q = MakeQuery(TypeQuery).SetFields(MakeField("user").SetFields(MakeField("id")))
+	assert.Nil(t, q.Validate())
+}
+
+func TestField_Validate(t *testing.T) {
+	f := MakeField("user").SetFields(MakeField("bad name"), MakeField("posts").SetArguments(ArgumentInt("1st", 1)))
+	assert.Equal(t, []error{
+		InvalidNameErr{fieldName, "bad name"},
+		InvalidNameErr{argumentName, "1st"},
+	}, causes(f.Validate(), SeverityError))
+
+	cyclic := MakeField("user")
+	cyclic.SetFields(MakeField("friends").SetFields(cyclic))
+	assert.Equal(t, []error{CyclicFieldErr{*cyclic}}, causes(cyclic.Validate(), SeverityError))
+	err := MakeQuery(TypeQuery).SetFields(cyclic).Validate()
+	assert.Equal(t, []error{CyclicFieldErr{*cyclic}}, causes(err, SeverityError))
+	assert.Nil(t, MakeField("user").Validate())
+}
+
+func TestQuery_Validate_warnings(t *testing.T) {
+	build := func(c *Config) *Query {
+		return MakeQuery(TypeQuery, c).SetFields(
+			MakeField("__user").SetArguments(ArgumentInt("id", 1<<40)).SetFields(MakeField("... on User")),
+		)
+	}
+	err := build(NewConfig(WithReservedNames())).Validate()
+	assert.Nil(t, causes(err, SeverityError))
+	assert.Equal(t, []error{
+		ReservedNameErr{fieldName, "__user"},
+		IntOutOfRangeErr{"id", 1 << 40},
+		EmptySelectionErr{"... on User"},
+	}, causes(err, SeverityWarning))
+	assert.Contains(t, err.Error(), "3 validation problem(s): warning: ")
+	// warnings do not prevent serialization
+	_, err = build(NewConfig(WithReservedNames())).StringChan()
+	assert.Nil(t, err)
+
+	err = build(NewConfig(WithIntRangeCheck(), WithSelectionCheck())).Validate()
+	assert.Equal(t, []error{
+		ReservedNameErr{fieldName, "__user"},
+		IntOutOfRangeErr{"id", 1 << 40},
+		EmptySelectionErr{"... on User"},
+	}, causes(err, SeverityError))
+	assert.Nil(t, causes(err, SeverityWarning))
+
+	err = MakeQuery(TypeQuery).Validate()
+	assert.Equal(t, []error{EmptySelectionErr{}}, causes(err, SeverityWarning))
+}
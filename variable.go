@@ -0,0 +1,252 @@
+package graphb
+
+import (
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+// checks the validity of a type reference according to the spec: http://facebook.github.io/graphql/October2016/#sec-Type-References
+var validTypeReference = regexp.MustCompile(`^(\[)*[_A-Za-z][_0-9A-Za-z]*!?(\]!?)*$`)
+
+// Variable represents a variable definition of an operation, e.g. `$id:ID!`.
+// Name is the variable name without the leading '$'. Type is a GraphQL type reference such as "ID!" or "[String!]".
+// Value, when not nil, is sent in the "variables" object of Query.JSON() and has to be encodable by encoding/json.
+type Variable struct {
+	Name  string
+	Type  string
+	Value interface{}
+
+	defaultValue argumentValue // The default value of a variable parsed from a document, e.g. `$first:Int=10`.
+}
+
+func (v *Variable) tokenChan() <-chan Token {
+	tokenChan := make(chan Token)
+	go func() {
+		tokenChan <- Token{TokenVariable, variableTokens.intern(v.Name)}
+		tokenChan <- Token{TokenPunctuator, tokenColumn}
+		tokenChan <- Token{TokenType, v.Type}
+		if v.defaultValue != nil {
+			tokenChan <- Token{TokenPunctuator, tokenEquals}
+			for tok := range v.defaultValue.tokenChan() {
+				tokenChan <- tok
+			}
+		}
+		close(tokenChan)
+	}()
+	return tokenChan
+}
+
+// WithDefault returns a copy of this Variable declaring the value of arg as its default value, e.g. `$first:Int=10`.
+// The name of arg is ignored.
+func (v Variable) WithDefault(arg Argument) Variable {
+	v.defaultValue = arg.Value
+	return v
+}
+
+func (v *Variable) check() error {
+	if !validName.MatchString(v.Name) {
+		return errors.WithStack(InvalidNameErr{variableName, v.Name})
+	}
+	if !isValidTypeReference(v.Type) {
+		return errors.WithStack(InvalidTypeReferenceErr{v.Name, v.Type})
+	}
+	return nil
+}
+
+// variableValues returns the values of the variables which have one, keyed by variable names.
+func (q *Query) variableValues() map[string]interface{} {
+	values := make(map[string]interface{})
+	for _, v := range q.Variables {
+		if v.Value != nil {
+			values[v.Name] = v.Value
+		}
+	}
+	return values
+}
+
+// ArgumentVariable returns an argument whose value is a reference to the variable of the given name, e.g. `id:$id`.
+// The variable has to be defined on the operation, see Query.AddVariables and OfVariable.
+func ArgumentVariable(name string, variable string) Argument {
+	return Argument{name, argVariable(variable)}
+}
+
+// argVariable represents a reference to a variable.
+type argVariable string
+
+func (v argVariable) tokenChan() <-chan Token {
+	tokenChan := make(chan Token)
+	go func() {
+		tokenChan <- Token{TokenVariable, variableTokens.intern(string(v))}
+		close(tokenChan)
+	}()
+	return tokenChan
+}
+
+// variables returns the variable definitions of this Query,
+// followed by the Boolean! ones implied by IncludeIfVar and SkipIfVar which it does not define. Its fields must not be cyclic.
+func (q *Query) variables() []Variable {
+	defined := make(map[string]bool, len(q.Variables))
+	for _, v := range q.Variables {
+		defined[v.Name] = true
+	}
+	var implied []Variable
+	var walk func(fs []*Field)
+	walk = func(fs []*Field) {
+		for _, f := range fs {
+			if f == nil {
+				continue
+			}
+			for _, d := range f.Directives {
+				if !d.impliesVariables {
+					continue
+				}
+				for _, arg := range d.Arguments {
+					for _, name := range variablesOf(arg.Value) {
+						if !defined[name] {
+							defined[name] = true
+							implied = append(implied, Variable{Name: name, Type: "Boolean!"})
+						}
+					}
+				}
+			}
+			walk(f.Fields)
+		}
+	}
+	walk(q.Fields)
+	if len(implied) == 0 {
+		return q.Variables
+	}
+	return append(append([]Variable(nil), q.Variables...), implied...)
+}
+
+// validateVariables implements the All Variables Defined and All Variables Used rules of the spec:
+// http://facebook.github.io/graphql/October2016/#sec-All-Variables-Used
+func (q *Query) validateVariables(report reporter) bool {
+	variables := q.variables()
+	defined := make(map[string]bool)
+	for i := range variables {
+		v := &variables[i]
+		if !report.check(v.check()) {
+			return false
+		}
+		if defined[v.Name] && !report.check(DuplicateVariableErr{v.Name}) {
+			return false
+		}
+		defined[v.Name] = true
+	}
+
+	used := make(map[string]bool)
+	for _, d := range q.Directives {
+		for _, arg := range d.Arguments {
+			for _, name := range variablesOf(arg.Value) {
+				if !defined[name] && !used[name] && !report.check(UndefinedVariableErr{name}) {
+					return false
+				}
+				used[name] = true
+			}
+		}
+	}
+	for _, f := range q.Fields {
+		for _, name := range f.variableReferences() {
+			if !defined[name] && !used[name] && !report.check(UndefinedVariableErr{name}) {
+				return false
+			}
+			used[name] = true
+		}
+	}
+	for _, v := range variables {
+		if !used[v.Name] {
+			if !report.check(UnusedVariableErr{v.Name}) {
+				return false
+			}
+			used[v.Name] = true // reported once
+		}
+	}
+	return true
+}
+
+// referencedVariables returns the names of the variables referenced by the directives of this Query and by its fields.
+func (q *Query) referencedVariables() map[string]bool {
+	used := make(map[string]bool)
+	for _, d := range q.Directives {
+		for _, arg := range d.Arguments {
+			for _, name := range variablesOf(arg.Value) {
+				used[name] = true
+			}
+		}
+	}
+	for _, f := range q.Fields {
+		for _, name := range f.variableReferences() {
+			used[name] = true
+		}
+	}
+	return used
+}
+
+// variableReferences returns the names of all variables referenced by the arguments of this Field and its sub fields.
+func (f *Field) variableReferences() []string {
+	if f == nil {
+		return nil
+	}
+	var names []string
+	for _, arg := range f.Arguments {
+		names = append(names, variablesOf(arg.Value)...)
+	}
+	for _, d := range f.Directives {
+		for _, arg := range d.Arguments {
+			names = append(names, variablesOf(arg.Value)...)
+		}
+	}
+	for _, subF := range f.Fields {
+		names = append(names, subF.variableReferences()...)
+	}
+	return names
+}
+
+// variablesOf returns the names of the variables referenced by an argument value, looking into input objects and lists.
+func variablesOf(value argumentValue) []string {
+	switch v := value.(type) {
+	case argVariable:
+		return []string{string(v)}
+	case argDefaulted:
+		return variablesOf(v.value)
+	case argumentCustom:
+		var names []string
+		for _, arg := range v {
+			names = append(names, variablesOf(arg.Value)...)
+		}
+		return names
+	case argArgSlice:
+		var names []string
+		for _, args := range v {
+			names = append(names, variablesOf(argumentCustom(args))...)
+		}
+		return names
+	case argList:
+		var names []string
+		for _, value := range v {
+			names = append(names, variablesOf(value)...)
+		}
+		return names
+	default:
+		return nil
+	}
+}
+
+// isValidTypeReference checks the grammar of a type reference, including that list brackets are balanced.
+func isValidTypeReference(t string) bool {
+	if !validTypeReference.MatchString(t) {
+		return false
+	}
+	depth := 0
+	for _, r := range t {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		}
+	}
+	return depth == 0
+}
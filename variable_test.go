@@ -0,0 +1,93 @@
+package graphb
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArgumentVariable(t *testing.T) {
+	a := ArgumentVariable("id", "userID")
+	assert.Equal(t, Argument{"id", argVariable("userID")}, a)
+	assert.Equal(t, "id:$userID", StringFromChan(literals(a.tokenChan())))
+}
+
+func TestQuery_Variables(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		q := NewQuery(
+			TypeQuery,
+			OfName("getUser"),
+			OfVariable("id", "ID!"),
+			OfVariable("tags", "[String!]"),
+			OfField(
+				"user",
+				OfArguments(ArgumentVariable("id", "id")),
+				OfField("posts", OfArguments(ArgumentCustomType("filter", ArgumentVariable("tags", "tags")))),
+			),
+		)
+		assert.Nil(t, q.E)
+		s, err := q.JSON()
+		assert.Nil(t, err)
+		assert.Equal(t, `{"query":"query getUser($id:ID!,$tags:[String!]){user(id:$id){posts(filter:{tags:$tags})}}"}`, s)
+	})
+
+	t.Run("undefined variable", func(t *testing.T) {
+		q := MakeQuery(TypeQuery).SetFields(
+			MakeField("user").SetFields(
+				MakeField("friends").SetArguments(ArgumentSlice("where", []Argument{ArgumentVariable("first", "first")})),
+			),
+		)
+		_, err := q.JSON()
+		assert.Equal(t, UndefinedVariableErr{"first"}, errors.Cause(err))
+	})
+
+	t.Run("unused variable", func(t *testing.T) {
+		q := MakeQuery(TypeQuery).
+			AddVariables(Variable{Name: "id", Type: "ID"}).
+			SetFields(MakeField("user"))
+		_, err := q.JSON()
+		assert.Equal(t, UnusedVariableErr{"id"}, errors.Cause(err))
+	})
+
+	t.Run("duplicate variable", func(t *testing.T) {
+		q := MakeQuery(TypeQuery).
+			AddVariables(Variable{Name: "id", Type: "ID"}, Variable{Name: "id", Type: "Int"}).
+			SetFields(MakeField("user").SetArguments(ArgumentVariable("id", "id")))
+		_, err := q.JSON()
+		assert.Equal(t, DuplicateVariableErr{"id"}, errors.Cause(err))
+	})
+
+	t.Run("invalid definitions", func(t *testing.T) {
+		q := NewQuery(TypeQuery, OfVariable("1d", "ID"))
+		assert.IsType(t, InvalidNameErr{}, errors.Cause(q.E))
+
+		q = NewQuery(TypeQuery, OfVariable("id", "[ID"))
+		assert.IsType(t, InvalidTypeReferenceErr{}, errors.Cause(q.E))
+	})
+}
+
+func Test_isValidTypeReference(t *testing.T) {
+	for _, valid := range []string{"ID", "ID!", "[ID]", "[ID!]!", "[[Int]!]"} {
+		assert.True(t, isValidTypeReference(valid), valid)
+	}
+	for _, invalid := range []string{"", "!", "ID!!", "[ID", "ID]", "[[ID]", "[]", "I D"} {
+		assert.False(t, isValidTypeReference(invalid), invalid)
+	}
+}
+
+func TestQuery_JSON_variables(t *testing.T) {
+	q := MakeQuery(TypeQuery).
+		AddVariables(
+			Variable{Name: "id", Type: "ID!", Value: 1},
+			Variable{Name: "tag", Type: "String"},
+		).
+		SetFields(MakeField("user").SetArguments(ArgumentVariable("id", "id"), ArgumentVariable("tag", "tag")))
+	s, err := q.JSON()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"query":"query($id:ID!,$tag:String){user(id:$id,tag:$tag)}","variables":{"id":1}}`, s)
+
+	q.Variables[1].Value = make(chan int)
+	_, err = q.JSON()
+	assert.Error(t, err)
+}
@@ -0,0 +1,81 @@
+package graphb
+
+import (
+	"time"
+)
+
+// ZeroBehavior tells how an argument holding the zero value of its type is emitted, see ArgumentSet and ArgumentsOf.
+type ZeroBehavior int
+
+const (
+	ZeroEmit ZeroBehavior = iota // The zero value is emitted like any other value, when 0 is meaningful.
+	ZeroOmit                     // The argument is omitted, when 0 means unset.
+	ZeroNull                     // The argument is emitted as null, when unset has to be explicit.
+)
+
+// ArgumentSet builds arguments like the Argument functions, following its ZeroBehavior for zero values, e.g.
+//
+//	unset := graphb.ArgumentSet{Zero: graphb.ZeroOmit}
+//	f.SetArguments(unset.Int("first", first), unset.String("after", cursor))
+type ArgumentSet struct {
+	Zero ZeroBehavior
+}
+
+func (s ArgumentSet) Bool(name string, value bool) Argument {
+	return s.apply(ArgumentBool(name, value), !value)
+}
+
+func (s ArgumentSet) Int(name string, value int) Argument {
+	return s.apply(ArgumentInt(name, value), value == 0)
+}
+
+func (s ArgumentSet) Long(name string, value int64) Argument {
+	return s.apply(ArgumentLong(name, value), value == 0)
+}
+
+func (s ArgumentSet) String(name string, value string) Argument {
+	return s.apply(ArgumentString(name, value), value == "")
+}
+
+func (s ArgumentSet) Enum(name string, value string) Argument {
+	return s.apply(ArgumentEnum(name, value), value == "")
+}
+
+func (s ArgumentSet) Time(name string, value time.Time) Argument {
+	return s.apply(ArgumentTime(name, value), value.IsZero())
+}
+
+func (s ArgumentSet) BoolSlice(name string, values ...bool) Argument {
+	return s.apply(ArgumentBoolSlice(name, values...), len(values) == 0)
+}
+
+func (s ArgumentSet) IntSlice(name string, values ...int) Argument {
+	return s.apply(ArgumentIntSlice(name, values...), len(values) == 0)
+}
+
+func (s ArgumentSet) StringSlice(name string, values ...string) Argument {
+	return s.apply(ArgumentStringSlice(name, values...), len(values) == 0)
+}
+
+func (s ArgumentSet) EnumSlice(name string, values ...string) Argument {
+	return s.apply(ArgumentEnumSlice(name, values...), len(values) == 0)
+}
+
+func (s ArgumentSet) apply(arg Argument, zero bool) Argument {
+	return withZeroBehavior(arg, zero, s.Zero)
+}
+
+// withZeroBehavior returns arg as emitted by behavior if it holds a zero value.
+func withZeroBehavior(arg Argument, zero bool, behavior ZeroBehavior) Argument {
+	if !zero {
+		return arg
+	}
+	switch behavior {
+	case ZeroOmit:
+		// a value equal to its default is never emitted, see emittedArguments
+		return Argument{arg.Name, argDefaulted{arg.Value, arg.Value}}
+	case ZeroNull:
+		return Argument{arg.Name, argTokens{{TokenNull, "null"}}}
+	}
+	return arg
+}
@@ -0,0 +1,29 @@
+package graphb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArgumentSet(t *testing.T) {
+	build := func(s ArgumentSet) string {
+		f := MakeField("posts").SetArguments(
+			s.Int("first", 0),
+			s.Int("skip", 5),
+			s.String("after", ""),
+			s.Bool("archived", false),
+			s.Time("since", time.Time{}),
+			s.EnumSlice("status"),
+			s.StringSlice("tags", "a"),
+		)
+		return StringFromChan(f.stringChan())
+	}
+	assert.Equal(t, `posts(first:0,skip:5,after:"",archived:false,since:"0001-01-01T00:00:00Z",status:[],tags:["a"])`, build(ArgumentSet{}))
+	assert.Equal(t, `posts(skip:5,tags:["a"])`, build(ArgumentSet{Zero: ZeroOmit}))
+	assert.Equal(t, `posts(first:null,skip:5,after:null,archived:null,since:null,status:null,tags:["a"])`, build(ArgumentSet{Zero: ZeroNull}))
+
+	f := MakeField("posts").SetArguments(ArgumentSet{Zero: ZeroOmit}.Int("first", 0))
+	assert.Equal(t, "posts", StringFromChan(f.stringChan()))
+}